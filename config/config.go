@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
@@ -36,6 +37,22 @@ type AppConfig struct {
 		From     string `mapstructure:"FROM"`
 		TO       string `mapstructure:"TO"`
 	}
+
+	CHAT struct {
+		DeleteForEveryoneWindow time.Duration `mapstructure:"DELETE_FOR_EVERYONE_WINDOW"`
+		MaxPinsPerRoom          int           `mapstructure:"MAX_PINS_PER_ROOM"`
+		MaxScheduleAhead        time.Duration `mapstructure:"MAX_SCHEDULE_AHEAD"`
+		MaxScheduledPerUser     int           `mapstructure:"MAX_SCHEDULED_PER_USER"`
+		EditWindow              time.Duration `mapstructure:"EDIT_WINDOW"`       // rooms without their own window
+		MaxMessageEdits         int           `mapstructure:"MAX_MESSAGE_EDITS"` // per message
+	}
+
+	STORAGE struct {
+		LocalDir      string        `mapstructure:"LOCAL_DIR"`
+		MaxUploadSize int64         `mapstructure:"MAX_UPLOAD_SIZE"` // bytes
+		SignedURLTTL  time.Duration `mapstructure:"SIGNED_URL_TTL"`
+		SigningKey    string        `mapstructure:"SIGNING_KEY"`
+	}
 }
 
 var Conf *AppConfig
@@ -49,6 +66,19 @@ func LoadConfig() error {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	// chat defaults, durations accept values like "1h" or "15m"
+	viper.SetDefault("CHAT.DELETE_FOR_EVERYONE_WINDOW", "1h")
+	viper.SetDefault("CHAT.MAX_PINS_PER_ROOM", 50)
+	viper.SetDefault("CHAT.MAX_SCHEDULE_AHEAD", "720h")
+	viper.SetDefault("CHAT.MAX_SCHEDULED_PER_USER", 100)
+	viper.SetDefault("CHAT.EDIT_WINDOW", "15m")
+	viper.SetDefault("CHAT.MAX_MESSAGE_EDITS", 20)
+
+	// uploaded files, the signing key should be set so signed URLs survive restarts
+	viper.SetDefault("STORAGE.LOCAL_DIR", "./uploads")
+	viper.SetDefault("STORAGE.MAX_UPLOAD_SIZE", 10<<20)
+	viper.SetDefault("STORAGE.SIGNED_URL_TTL", "15m")
+
 	if err := viper.ReadInConfig(); err != nil {
 		return fmt.Errorf("error reading config file: %w", err)
 	}
@@ -1,12 +1,17 @@
 package chat_dto
 
 import (
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SendPrivateMessageRequest struct {
-	Content string `json:"content" validate:"required,min=1"`
+	Content       string   `json:"content" validate:"required_without=AttachmentIDs"`
+	Format        string   `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"`
+	AttachmentIDs []string `json:"attachment_ids,omitempty" validate:"omitempty,max=10,unique,dive,uuid"` // ids returned by POST /api/v1/uploads
+	ClientMsgID   string   `json:"client_msg_id,omitempty" validate:"omitempty,max=64,printascii"`        // or the Idempotency-Key header, a retry with the same id returns the first response
 }
 
 type GetPrivateMessagesRequest struct {
@@ -15,13 +20,117 @@ type GetPrivateMessagesRequest struct {
 }
 
 type ReplyPrivateMessageRequest struct {
-	Content    string `json:"content" validate:"required,min=1"`
-	ReplyTo    string `json:"reply_to" validate:"required,objectID"` // message ID being replied to
-	ReceiverID string `json:"receiver_id" validate:"required,uuid"`
+	Content       string   `json:"content" validate:"required_without=AttachmentIDs"`
+	Format        string   `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"`
+	AttachmentIDs []string `json:"attachment_ids,omitempty" validate:"omitempty,max=10,unique,dive,uuid"`
+	ReplyTo       string   `json:"reply_to" validate:"required,objectID"` // message ID being replied to
+	ReceiverID    string   `json:"receiver_id" validate:"required,uuid"`
+	ClientMsgID   string   `json:"client_msg_id,omitempty" validate:"omitempty,max=64,printascii"`
 }
 
 type UpdatePrivateMessageRequest struct {
 	Content string `json:"content" validate:"min=1"`
+	Format  string `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"` // keeps the current format when empty
+}
+
+type SendRoomMessageRequest struct {
+	Content       string   `json:"content" validate:"required_without=AttachmentIDs"`
+	Format        string   `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"`
+	AttachmentIDs []string `json:"attachment_ids,omitempty" validate:"omitempty,max=10,unique,dive,uuid"`
+	ClientMsgID   string   `json:"client_msg_id,omitempty" validate:"omitempty,max=64,printascii"`
+}
+
+type ReplyRoomMessageRequest struct {
+	Content       string   `json:"content" validate:"required_without=AttachmentIDs"`
+	Format        string   `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"`
+	AttachmentIDs []string `json:"attachment_ids,omitempty" validate:"omitempty,max=10,unique,dive,uuid"`
+	ClientMsgID   string   `json:"client_msg_id,omitempty" validate:"omitempty,max=64,printascii"`
+}
+
+type UpdateRoomMessageRequest struct {
+	Content string `json:"content" validate:"required,min=1"`
+	Format  string `json:"format,omitempty" validate:"omitempty,oneof=plain markdown"` // keeps the current format when empty
+}
+
+type CreateGroupRequest struct {
+	Name      string   `json:"name" validate:"required,min=1,max=100"`
+	MemberIDs []string `json:"member_ids" validate:"required,min=1,max=255,dive,uuid"`
+}
+
+type UpdateGroupRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type ChangeMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin moderator member"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+type InviteMembersRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+type CreateInviteLinkRequest struct {
+	ExpiresIn int `json:"expires_in" validate:"required,min=60,max=2592000"` // seconds, up to 30 days
+	MaxUses   int `json:"max_uses" validate:"required,min=1,max=1000"`
+}
+
+type ForwardMessagesRequest struct {
+	MessageIDs    []string `json:"message_ids" validate:"required,min=1,max=20,dive,objectID"`
+	TargetRoomIDs []string `json:"target_room_ids" validate:"required,min=1,max=10,dive,uuid"`
+	ClientMsgID   string   `json:"client_msg_id,omitempty" validate:"omitempty,max=60,printascii"` // every copy gets the id with its position appended
+}
+
+type ScheduleMessageRequest struct {
+	Content string    `json:"content" validate:"required,min=1"`
+	SendAt  time.Time `json:"send_at" validate:"required"` // RFC3339, must be in the future
+}
+
+type UpdateScheduledMessageRequest struct {
+	Content *string    `json:"content,omitempty" validate:"omitempty,min=1"`
+	SendAt  *time.Time `json:"send_at,omitempty"`
+}
+
+type DisappearingTimerRequest struct {
+	Timer string `json:"timer" validate:"required,oneof=off 1h 24h 7d"`
+}
+
+type EditWindowRequest struct {
+	Window string `json:"window" validate:"required,max=16"` // off, default or a duration like 30m, up to 168h
+}
+
+type InboxSettingsRequest struct {
+	Muted    *bool `json:"muted,omitempty" validate:"required_without=Archived"`
+	Archived *bool `json:"archived,omitempty" validate:"required_without=Muted"`
+}
+
+type SearchMessagesRequest struct {
+	Query    string     `json:"q" validate:"required,min=2,max=100"`
+	RoomID   *string    `json:"room_id,omitempty" validate:"omitempty,uuid"`
+	SenderID *string    `json:"sender_id,omitempty" validate:"omitempty,uuid"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Limit    int        `json:"limit" validate:"omitempty,min=1,max=100"`
+	BeforeID *string    `json:"before_id,omitempty"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=32,excludesall= "`
+}
+
+type CreatePollRequest struct {
+	Question       string     `json:"question" validate:"required,max=300"`
+	Options        []string   `json:"options" validate:"required,min=2,max=10,dive,required,max=100"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	Anonymous      bool       `json:"anonymous"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty"` // RFC3339, the poll stays open until closed by hand when empty
+}
+
+type VotePollRequest struct {
+	OptionIDs []string `json:"option_ids" validate:"required,min=1,max=10,unique,dive,required"`
 }
 
 func ObjectIDValidator(fl validator.FieldLevel) bool {
@@ -3,13 +3,22 @@ package chat_dto
 import "time"
 
 type SendPrivateMessageResponse struct {
-	MessageID  string    `json:"message_id"`
-	RoomID     string    `json:"room_id"`
-	SenderID   string    `json:"sender_id"`
-	ReceiverID string    `json:"receiver_id"`
-	Content    string    `json:"content"`
-	IsRead     bool      `json:"is_read"`
-	CreatedAt  time.Time `json:"created_at"`
+	MessageID   string           `json:"message_id"`
+	ClientMsgID string           `json:"client_msg_id,omitempty"`
+	RoomID      string           `json:"room_id"`
+	SenderID    string           `json:"sender_id"`
+	ReceiverID  string           `json:"receiver_id"`
+	Content     string           `json:"content"`
+	Format      string           `json:"format"`
+	PlainText   string           `json:"plain_text"`
+	Attachments []*Attachment    `json:"attachments,omitempty"`
+	IsRead      bool             `json:"is_read"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	Mentions    []string         `json:"mentions,omitempty"`
+	Mentioned   []string         `json:"-"` // members to send a mention event to
+	Unread      map[string]int64 `json:"-"` // unread counters of the other members after this message
+	Replayed    bool             `json:"-"` // a retry of an earlier send, nothing new to broadcast
 }
 
 type UpdatePrivateMessageResponse struct {
@@ -18,11 +27,16 @@ type UpdatePrivateMessageResponse struct {
 	SenderID           string              `json:"sender_id"`
 	ReceiverID         string              `json:"receiver_id"`
 	Content            string              `json:"content"`
+	Format             string              `json:"format"`
+	PlainText          string              `json:"plain_text"`
 	MessageEditHistory []*MessageEditEntry `json:"message_edit_history"`
 	ReplyTo            *ReplyMessage       `json:"reply_to"`
 	IsRead             bool                `json:"is_read"`
 	IsEdited           bool                `json:"is_edited"`
+	EditedBy           string              `json:"edited_by"` // the author or a moderator editing for them
 	UpdatedAt          time.Time           `json:"updated_at"`
+	Mentions           []string            `json:"mentions,omitempty"`
+	Mentioned          []string            `json:"-"` // members to send a mention event to
 }
 
 type MessageEditEntry struct {
@@ -34,14 +48,73 @@ type MessageEditEntry struct {
 }
 
 type ReplyPrivateMessageResponse struct {
-	MessageID  string        `json:"message_id"`
-	RoomID     string        `json:"room_id"`
-	SenderID   string        `json:"sender_id"`
-	ReceiverID string        `json:"receiver_id"`
-	Content    string        `json:"content"`
-	ReplyTo    *ReplyMessage `json:"reply_to"`
-	IsRead     bool          `json:"is_read"`
-	CreatedAt  time.Time     `json:"created_at"`
+	MessageID   string           `json:"message_id"`
+	ClientMsgID string           `json:"client_msg_id,omitempty"`
+	RoomID      string           `json:"room_id"`
+	SenderID    string           `json:"sender_id"`
+	ReceiverID  string           `json:"receiver_id"`
+	Content     string           `json:"content"`
+	Format      string           `json:"format"`
+	PlainText   string           `json:"plain_text"`
+	Attachments []*Attachment    `json:"attachments,omitempty"`
+	ReplyTo     *ReplyMessage    `json:"reply_to"`
+	Thread      *ThreadSummary   `json:"thread,omitempty"`
+	IsRead      bool             `json:"is_read"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	Mentions    []string         `json:"mentions,omitempty"`
+	Mentioned   []string         `json:"-"` // members to send a mention event to
+	Unread      map[string]int64 `json:"-"` // unread counters of the other members after this message
+	Replayed    bool             `json:"-"` // a retry of an earlier send, nothing new to broadcast
+}
+
+// ThreadSummary is the state of a thread root right after a reply was added
+type ThreadSummary struct {
+	RootMessageID string    `json:"root_message_id"`
+	ReplyCount    int       `json:"reply_count"`
+	LastReplyAt   time.Time `json:"last_reply_at"`
+	Participants  []string  `json:"-"` // active members that took part in the thread
+}
+
+type ThreadResponse struct {
+	Root       PrivateMessages   `json:"root"`
+	Replies    []PrivateMessages `json:"replies"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+type ForwardedFrom struct {
+	MessageID string `json:"message_id"`
+	RoomID    string `json:"room_id"`
+	SenderID  string `json:"sender_id"`
+}
+
+// Attachment is an attached file, URL is a signed download link that expires
+type Attachment struct {
+	Type         string `json:"type"`
+	URL          string `json:"url"`
+	UploadID     string `json:"upload_id,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// LinkPreview is the title, description and image of a link in the message content
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+type ForwardMessagesResponse struct {
+	ClientMsgID string                 `json:"client_msg_id,omitempty"`
+	Messages    []*RoomMessageResponse `json:"messages"`
+	Replayed    bool                   `json:"-"` // a retry of an earlier forward, nothing new to broadcast
 }
 
 type ReplyMessage struct {
@@ -57,12 +130,388 @@ type GetPrivateMessagesResponse struct {
 }
 
 type PrivateMessages struct {
-	MessageID  string        `json:"message_id"`
-	RoomID     string        `json:"room_id"`
-	SenderID   string        `json:"sender_id"`
-	ReceiverID string        `json:"receiver_id"`
-	Content    string        `json:"content"`
-	ReplyTo    *ReplyMessage `json:"reply_to,omitempty"`
-	IsRead     bool          `json:"is_read"`
-	CreatedAt  time.Time     `json:"created_at"`
+	MessageID     string            `json:"message_id"`
+	RoomID        string            `json:"room_id"`
+	SenderID      string            `json:"sender_id"`
+	ReceiverID    string            `json:"receiver_id"`
+	MessageType   string            `json:"message_type"` // text, poll or system
+	Content       string            `json:"content"`
+	Format        string            `json:"format"`
+	PlainText     string            `json:"plain_text"`
+	Poll          *Poll             `json:"poll,omitempty"`
+	SystemData    map[string]string `json:"system_data,omitempty"`
+	ReplyTo       *ReplyMessage     `json:"reply_to,omitempty"`
+	IsRead        bool              `json:"is_read"`
+	IsEdited      bool              `json:"is_edited"` // revisions are listed by the history endpoint
+	IsDeleted     bool              `json:"is_deleted"`
+	Reactions     []ReactionSummary `json:"reactions,omitempty"`
+	ForwardedFrom *ForwardedFrom    `json:"forwarded_from,omitempty"`
+	Attachments   []*Attachment     `json:"attachments,omitempty"`
+	LinkPreviews  []*LinkPreview    `json:"link_previews,omitempty"`
+	ThreadRootID  string            `json:"thread_root_id,omitempty"`
+	ReplyCount    int               `json:"reply_count,omitempty"`
+	LastReplyAt   *time.Time        `json:"last_reply_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ExpiresAt     *time.Time        `json:"expires_at,omitempty"`
+}
+
+// ReactionSummary aggregates one emoji on a message, Reacted is relative to the caller
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+type MessageReactionResponse struct {
+	MessageID  string            `json:"message_id"`
+	RoomID     string            `json:"room_id"`
+	UserID     string            `json:"user_id"`
+	Emoji      string            `json:"emoji"`
+	Action     string            `json:"action"`
+	Reactions  []ReactionSummary `json:"reactions"`
+	Changed    bool              `json:"-"`
+	Recipients []string          `json:"-"`
+}
+
+// Poll is the tally of a poll, MyVote is relative to the caller and voters are only listed when the poll is not anonymous
+type Poll struct {
+	Question       string             `json:"question"`
+	Options        []PollOptionResult `json:"options"`
+	MultipleChoice bool               `json:"multiple_choice"`
+	Anonymous      bool               `json:"anonymous"`
+	ClosesAt       *time.Time         `json:"closes_at,omitempty"`
+	ClosedAt       *time.Time         `json:"closed_at,omitempty"`
+	IsClosed       bool               `json:"is_closed"`
+	TotalVoters    int                `json:"total_voters"`
+	MyVote         []string           `json:"my_vote,omitempty"`
+}
+
+type PollOptionResult struct {
+	ID     string   `json:"id"`
+	Text   string   `json:"text"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters,omitempty"`
+}
+
+type PollUpdatedResponse struct {
+	MessageID  string   `json:"message_id"`
+	RoomID     string   `json:"room_id"`
+	Poll       *Poll    `json:"poll"`
+	Recipients []string `json:"-"`
+}
+
+type RoomMessageResponse struct {
+	MessageID          string              `json:"message_id"`
+	ClientMsgID        string              `json:"client_msg_id,omitempty"`
+	RoomID             string              `json:"room_id"`
+	SenderID           string              `json:"sender_id"`
+	ReceiverID         string              `json:"receiver_id,omitempty"`
+	MessageType        string              `json:"message_type"`
+	Content            string              `json:"content"`
+	Format             string              `json:"format"`
+	PlainText          string              `json:"plain_text"`
+	Poll               *Poll               `json:"poll,omitempty"`
+	ReplyTo            *ReplyMessage       `json:"reply_to,omitempty"`
+	ForwardedFrom      *ForwardedFrom      `json:"forwarded_from,omitempty"`
+	Attachments        []*Attachment       `json:"attachments,omitempty"`
+	LinkPreviews       []*LinkPreview      `json:"link_previews,omitempty"`
+	Thread             *ThreadSummary      `json:"thread,omitempty"`
+	IsEdited           bool                `json:"is_edited"`
+	MessageEditHistory []*MessageEditEntry `json:"message_edit_history,omitempty"`
+	EditedBy           string              `json:"edited_by,omitempty"` // set on edits, the author or a moderator editing for them
+	CreatedAt          time.Time           `json:"created_at"`
+	ExpiresAt          *time.Time          `json:"expires_at,omitempty"`
+	UpdatedAt          *time.Time          `json:"updated_at,omitempty"`
+	Recipients         []string            `json:"-"` // active members the message is fanned out to
+	Mentions           []string            `json:"mentions,omitempty"`
+	Mentioned          []string            `json:"-"` // members to send a mention event to
+	Unread             map[string]int64    `json:"-"` // unread counters of the other members, set on the last message a send added to the room
+	Replayed           bool                `json:"-"` // a retry of an earlier send, nothing new to broadcast
+}
+
+type ReadCursorResponse struct {
+	RoomID        string    `json:"room_id"`
+	UserID        string    `json:"user_id"`
+	LastReadMsgID string    `json:"last_read_msg_id"`
+	ReadAt        time.Time `json:"read_at"`
+	Advanced      bool      `json:"advanced"`               // false when the cursor was already at or past the message
+	UnreadCount   *int64    `json:"unread_count,omitempty"` // messages left after the cursor, only counted when it advanced
+	Recipients    []string  `json:"-"`
+}
+
+type MessageReceiptsResponse struct {
+	MessageID string           `json:"message_id"`
+	RoomID    string           `json:"room_id"`
+	ReadBy    []MessageReceipt `json:"read_by"`
+	Unread    []string         `json:"unread"`
+}
+
+type MessageReceipt struct {
+	UserID string     `json:"user_id"`
+	ReadAt *time.Time `json:"read_at"`
+}
+
+type MessageDeletedResponse struct {
+	MessageID  string    `json:"message_id"`
+	RoomID     string    `json:"room_id"`
+	Scope      string    `json:"scope"`
+	DeletedBy  string    `json:"deleted_by"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	Recipients []string  `json:"-"`
+}
+
+type GroupResponse struct {
+	RoomID    string                `json:"room_id"`
+	Name      string                `json:"name"`
+	CreatedBy string                `json:"created_by"`
+	Members   []GroupMemberResponse `json:"members"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+type GroupMemberResponse struct {
+	UserID   string    `json:"user_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type GroupMembersResponse struct {
+	RoomID  string                `json:"room_id"`
+	Members []GroupMemberResponse `json:"members"`
+}
+
+type MemberRoleChangedResponse struct {
+	RoomID       string         `json:"room_id"`
+	UserID       string         `json:"user_id"`
+	PreviousRole string         `json:"previous_role"`
+	Role         string         `json:"role"`
+	Change       string         `json:"change"` // promoted or demoted
+	ChangedBy    string         `json:"changed_by"`
+	Recipients   []string       `json:"-"`
+	Announcement *SystemMessage `json:"-"`
+}
+
+type OwnershipTransferredResponse struct {
+	RoomID          string         `json:"room_id"`
+	PreviousOwnerID string         `json:"previous_owner_id"`
+	NewOwnerID      string         `json:"new_owner_id"`
+	Recipients      []string       `json:"-"`
+	Announcement    *SystemMessage `json:"-"`
+}
+
+// SystemMessage is a room event stored in the history, Data carries the event details and the message ID
+type SystemMessage struct {
+	MessageID string
+	Content   string
+	Data      map[string]string
+}
+
+type RoomLeftResponse struct {
+	RoomID     string   `json:"room_id"`
+	UserID     string   `json:"user_id"`
+	Reason     string   `json:"reason"` // left or removed
+	ActorID    string   `json:"actor_id"`
+	Recipients []string `json:"-"`
+}
+
+type MembershipEventResponse struct {
+	UserID    string    `json:"user_id"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MembershipEventsResponse struct {
+	RoomID string                    `json:"room_id"`
+	Events []MembershipEventResponse `json:"events"`
+}
+
+type InvitationResponse struct {
+	InvitationID string    `json:"invitation_id"`
+	RoomID       string    `json:"room_id"`
+	InviterID    string    `json:"inviter_id"`
+	InviteeID    string    `json:"invitee_id"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InviteLinkResponse struct {
+	Code      string    `json:"code"`
+	RoomID    string    `json:"room_id"`
+	MaxUses   int       `json:"max_uses"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type RoomJoinedResponse struct {
+	RoomID       string   `json:"room_id"`
+	UserID       string   `json:"user_id"`
+	Participants []string `json:"participants"`
+}
+
+type MentionResponse struct {
+	MessageID string    `json:"message_id"`
+	RoomID    string    `json:"room_id"`
+	SenderID  string    `json:"sender_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MentionsResponse struct {
+	Mentions   []MentionResponse `json:"mentions"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+type MessagePinnedResponse struct {
+	MessageID  string    `json:"message_id"`
+	RoomID     string    `json:"room_id"`
+	Pinned     bool      `json:"pinned"`
+	PinnedBy   string    `json:"pinned_by"` // the member that pinned or unpinned
+	PinnedAt   time.Time `json:"pinned_at"`
+	Recipients []string  `json:"-"`
+}
+
+type PinnedMessageResponse struct {
+	Message  PrivateMessages `json:"message"`
+	PinnedBy string          `json:"pinned_by"`
+	PinnedAt time.Time       `json:"pinned_at"`
+}
+
+type RoomPinsResponse struct {
+	Pins    []PinnedMessageResponse `json:"pins"`
+	MaxPins int                     `json:"max_pins"`
+}
+
+type BookmarkResponse struct {
+	BookmarkID   int64           `json:"bookmark_id"`
+	BookmarkedAt time.Time       `json:"bookmarked_at"`
+	Message      PrivateMessages `json:"message"`
+}
+
+type BookmarksResponse struct {
+	Bookmarks  []BookmarkResponse `json:"bookmarks"`
+	NextCursor *string            `json:"next_cursor,omitempty"`
+	HasMore    bool               `json:"has_more"`
+}
+
+type ScheduledMessageResponse struct {
+	ScheduledID string     `json:"scheduled_id"`
+	RoomID      string     `json:"room_id"`
+	ReceiverID  string     `json:"receiver_id"`
+	Content     string     `json:"content"`
+	SendAt      time.Time  `json:"send_at"`
+	Status      string     `json:"status"`
+	Version     int        `json:"-"` // the version the delivery job has to carry
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type ScheduledMessagesResponse struct {
+	ScheduledMessages []ScheduledMessageResponse `json:"scheduled_messages"`
+}
+
+type DisappearingTimerResponse struct {
+	RoomID     string   `json:"room_id"`
+	Timer      string   `json:"timer"`
+	TTLSeconds int      `json:"ttl_seconds"`
+	UpdatedBy  string   `json:"updated_by"`
+	Recipients []string `json:"-"`
+}
+
+type EditWindowResponse struct {
+	RoomID        string   `json:"room_id"`
+	Window        string   `json:"window"`
+	WindowSeconds int      `json:"window_seconds"` // effective window, 0 means no limit
+	UpdatedBy     string   `json:"updated_by"`
+	Recipients    []string `json:"-"`
+}
+
+// MessageHistoryResponse lists every revision of a message, the original content first
+type MessageHistoryResponse struct {
+	MessageID string            `json:"message_id"`
+	RoomID    string            `json:"room_id"`
+	EditCount int               `json:"edit_count"`
+	Revisions []MessageRevision `json:"revisions"`
+}
+
+// MessageRevision is the content of a message from WrittenAt until the next revision
+type MessageRevision struct {
+	Revision  int       `json:"revision"` // 0 is the content the message was sent with
+	Content   string    `json:"content"`
+	WrittenBy string    `json:"written_by"`
+	WrittenAt time.Time `json:"written_at"`
+}
+
+type SearchMessagesResponse struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
+
+type SearchResult struct {
+	MessageID  string            `json:"message_id"`
+	RoomID     string            `json:"room_id"`
+	SenderID   string            `json:"sender_id"`
+	Content    string            `json:"content"`
+	Format     string            `json:"format"`
+	Snippet    string            `json:"snippet"`
+	Highlights []SearchHighlight `json:"highlights"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// SearchHighlight is a matched range of the snippet, in characters (runes), End is exclusive
+type SearchHighlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// UploadResponse describes a stored upload, UploadID goes into attachment_ids of a message
+type UploadResponse struct {
+	UploadID  string    `json:"upload_id"`
+	Type      string    `json:"type"`
+	Filename  string    `json:"filename"`
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InboxResponse struct {
+	Rooms      []InboxRoom `json:"rooms"`
+	NextCursor *string     `json:"next_cursor,omitempty"`
+	HasMore    bool        `json:"has_more"`
+}
+
+// InboxRoom is a room in the inbox of the caller, unread count and flags are the caller's own
+type InboxRoom struct {
+	RoomID         string              `json:"room_id"`
+	RoomType       string              `json:"room_type"`
+	Name           string              `json:"name,omitempty"`        // only set for groups
+	Participant    *UserProfile        `json:"participant,omitempty"` // the other member of a private room
+	LastMessage    *LastMessagePreview `json:"last_message,omitempty"`
+	UnreadCount    int64               `json:"unread_count"`
+	IsMuted        bool                `json:"is_muted"`
+	IsArchived     bool                `json:"is_archived"`
+	LastActivityAt time.Time           `json:"last_activity_at"`
+}
+
+type UserProfile struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+type LastMessagePreview struct {
+	MessageID       string    `json:"message_id"`
+	SenderID        string    `json:"sender_id"`
+	MessageType     string    `json:"message_type"`
+	Preview         string    `json:"preview"` // plain text, shortened
+	AttachmentCount int       `json:"attachment_count,omitempty"`
+	IsDeleted       bool      `json:"is_deleted"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type InboxSettingsResponse struct {
+	RoomID     string `json:"room_id"`
+	IsMuted    bool   `json:"is_muted"`
+	IsArchived bool   `json:"is_archived"`
 }
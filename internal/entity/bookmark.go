@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// MessageBookmark is a message saved by one user, only visible to that user
+type MessageBookmark struct {
+	ID        int64     `gorm:"primaryKey"`
+	UserID    string    `gorm:"not null"`
+	RoomID    string    `gorm:"not null"`
+	MessageID string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
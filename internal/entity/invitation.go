@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+type RoomInvitation struct {
+	ID          string    `gorm:"primaryKey;default:uuid_generate_v4()"`
+	RoomID      string    `gorm:"not null"`
+	InviterID   string    `gorm:"not null"`
+	InviteeID   string    `gorm:"not null"`
+	Status      string    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	RespondedAt *time.Time
+}
+
+// InviteLink is stored as a redis hash under invite_link:{code}, the key TTL is the link expiry
+type InviteLink struct {
+	Code      string `redis:"code"`
+	RoomID    string `redis:"room_id"`
+	CreatedBy string `redis:"created_by"`
+	MaxUses   int    `redis:"max_uses"`
+	Uses      int    `redis:"uses"`
+	ExpiresAt int64  `redis:"expires_at"`
+}
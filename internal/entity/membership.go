@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const (
+	MembershipEventJoined   = "joined"
+	MembershipEventRejoined = "rejoined"
+	MembershipEventLeft     = "left"
+	MembershipEventRemoved  = "removed"
+)
+
+// RoomMembershipEvent is one entry of the room membership audit trail, rows are never updated
+type RoomMembershipEvent struct {
+	ID        int64     `gorm:"primaryKey"`
+	RoomID    string    `gorm:"not null"`
+	UserID    string    `gorm:"not null"`
+	ActorID   string    `gorm:"default:null"`
+	Event     string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
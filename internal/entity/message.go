@@ -10,17 +10,64 @@ type Message struct {
 	ID                 primitive.ObjectID  `bson:"_id,omitempty"`
 	RoomID             string              `bson:"room_id"`
 	SenderID           string              `bson:"sender_id"`
-	ReceiverID         string              `bson:"receiver_id"`
-	Content            string              `bson:"content"`
-	IsRead             bool                `bson:"is_read"`
+	ClientMsgID        string              `bson:"client_msg_id,omitempty"` // generated by the sender, unique per sender
+	ReceiverID         string              `bson:"receiver_id,omitempty"`   // empty for group rooms
+	Type               string              `bson:"type,omitempty"`          // empty for text messages
+	Content            string              `bson:"content"`                 // the question of a poll
+	Format             string              `bson:"format,omitempty"`        // plain or markdown, empty is plain
+	PlainText          string              `bson:"plain_text,omitempty"`    // content without markup, used by search and notifications
 	IsEdited           bool                `bson:"is_edited"`
 	MessageEditHistory []*MessageEditEntry `bson:"message_edit_history"`
 	Attachments        []*Attachment       `bson:"attachments"`
+	LinkPreviews       []*LinkPreview      `bson:"link_previews,omitempty"` // filled in by the unfurl_links job
 	ReplyTo            *ReplyTo            `bson:"reply_to"`
+	ForwardedFrom      *ForwardedFrom      `bson:"forwarded_from,omitempty"`
+	Reactions          []*Reaction         `bson:"reactions,omitempty"`
+	Poll               *Poll               `bson:"poll,omitempty"`                // only set on poll messages
+	SystemData         map[string]string   `bson:"system_data,omitempty"`         // only set on system messages
+	Mentions           []string            `bson:"mentions,omitempty"`            // IDs of the room members mentioned with @username
+	ThreadRootID       *primitive.ObjectID `bson:"thread_root_id,omitempty"`      // set on every reply, points at the first message of the thread
+	ReplyCount         int                 `bson:"reply_count,omitempty"`         // only kept on thread roots
+	LastReplyAt        *time.Time          `bson:"last_reply_at,omitempty"`       // only kept on thread roots
+	ThreadParticipants []string            `bson:"thread_participants,omitempty"` // root sender plus every replier
+	DeletedFor         []string            `bson:"deleted_for,omitempty"`         // users that deleted the message for themselves
+	IsDeleted          bool                `bson:"is_deleted,omitempty"`          // deleted for everyone, content is a tombstone
+	DeletedBy          string              `bson:"deleted_by,omitempty"`
+	DeletedAt          *time.Time          `bson:"deleted_at,omitempty"`
+	ExpiresAt          *time.Time          `bson:"expires_at,omitempty"` // stamped from the room disappearing timer
 	CreatedAt          time.Time           `bson:"created_at"`
 	UpdatedAt          *time.Time          `bson:"updated_at"`
 }
 
+const (
+	MessageTombstone = "This message was deleted"
+
+	MessageFormatPlain    = "plain"
+	MessageFormatMarkdown = "markdown"
+
+	MessageTypeText   = "text" // stored without a type
+	MessageTypePoll   = "poll"
+	MessageTypeSystem = "system" // room events like role changes, stored so they show up in the history
+
+	DeleteScopeMe       = "me"
+	DeleteScopeEveryone = "everyone"
+	DeleteScopeExpired  = "expired" // removed by the disappearing messages sweeper
+)
+
+// MessageDeletion is the audit record kept in message_deletions when a message is deleted for everyone
+type MessageDeletion struct {
+	ID               primitive.ObjectID  `bson:"_id,omitempty"`
+	MessageID        primitive.ObjectID  `bson:"message_id"`
+	RoomID           string              `bson:"room_id"`
+	SenderID         string              `bson:"sender_id"`
+	DeletedBy        string              `bson:"deleted_by"`
+	OriginalContent  string              `bson:"original_content"`
+	Attachments      []*Attachment       `bson:"attachments,omitempty"`
+	EditHistory      []*MessageEditEntry `bson:"message_edit_history,omitempty"`
+	MessageCreatedAt time.Time           `bson:"message_created_at"`
+	DeletedAt        time.Time           `bson:"deleted_at"`
+}
+
 type MessageEditEntry struct {
 	MessageID       primitive.ObjectID `bson:"message_id"`
 	OriginalContent string             `bson:"original_content"`
@@ -35,7 +82,73 @@ type ReplyTo struct {
 	SenderID  string             `bson:"sender_id"`
 }
 
+// Reaction is one emoji from one user, a user can react with several different emojis
+type Reaction struct {
+	UserID    string    `bson:"user_id"`
+	Emoji     string    `bson:"emoji"`
+	ReactedAt time.Time `bson:"reacted_at"`
+}
+
+// Poll is the question, options and votes of a poll message, a poll is closed once ClosedAt is set or
+// ClosesAt has passed, whichever comes first
+type Poll struct {
+	Question       string        `bson:"question"`
+	Options        []*PollOption `bson:"options"`
+	MultipleChoice bool          `bson:"multiple_choice"`
+	Anonymous      bool          `bson:"anonymous"`
+	ClosesAt       *time.Time    `bson:"closes_at,omitempty"`
+	ClosedAt       *time.Time    `bson:"closed_at,omitempty"` // stamped by the close_poll job or the creator
+	Votes          []*PollVote   `bson:"votes"`
+}
+
+type PollOption struct {
+	ID   string `bson:"id"`
+	Text string `bson:"text"`
+}
+
+// PollVote is the current choice of one user, voting again replaces it
+type PollVote struct {
+	UserID    string    `bson:"user_id"`
+	OptionIDs []string  `bson:"option_ids"`
+	VotedAt   time.Time `bson:"voted_at"`
+}
+
+// ForwardedFrom points at the original message of a forwarded copy, forwarding a copy keeps the original reference
+type ForwardedFrom struct {
+	MessageID primitive.ObjectID `bson:"message_id"`
+	RoomID    string             `bson:"room_id"`
+	SenderID  string             `bson:"sender_id"`
+}
+
+// Attachment references an uploaded file, URL is the unsigned download path and is signed when sent to clients
 type Attachment struct {
-	Type string `bson:"type"`
-	URL  string `bson:"url"`
+	Type     string `bson:"type"`
+	URL      string `bson:"url"`
+	UploadID string `bson:"upload_id,omitempty"`
+	Filename string `bson:"filename,omitempty"`
+	Size     int64  `bson:"size,omitempty"`
+	MimeType string `bson:"mime_type,omitempty"`
+	// set by the process_attachments job for images
+	Width        int    `bson:"width,omitempty"`
+	Height       int    `bson:"height,omitempty"`
+	ThumbnailURL string `bson:"thumbnail_url,omitempty"`
+}
+
+// LinkPreview is the Open Graph or html metadata of a link in the message content
+type LinkPreview struct {
+	URL         string `bson:"url"`
+	Title       string `bson:"title,omitempty"`
+	Description string `bson:"description,omitempty"`
+	ImageURL    string `bson:"image_url,omitempty"`
+	SiteName    string `bson:"site_name,omitempty"`
+}
+
+// MessageSearchFilter narrows a full-text search, RoomIDs must already be limited to the rooms the viewer can read
+type MessageSearchFilter struct {
+	Query    string
+	ViewerID string
+	RoomIDs  []string
+	SenderID *string
+	From     *time.Time
+	To       *time.Time
 }
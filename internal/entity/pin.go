@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// RoomPin marks a message as pinned in its room, MessageID is the hex ObjectID of the message
+type RoomPin struct {
+	ID        int64     `gorm:"primaryKey"`
+	RoomID    string    `gorm:"not null"`
+	MessageID string    `gorm:"not null"`
+	PinnedBy  string    `gorm:"default:null"`
+	PinnedAt  time.Time `gorm:"autoCreateTime"`
+}
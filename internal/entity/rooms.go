@@ -6,6 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoomTypePrivate = "private"
+	RoomTypeGroup   = "group"
+
+	RoomRoleOwner     = "owner"
+	RoomRoleAdmin     = "admin"
+	RoomRoleModerator = "moderator"
+	RoomRoleMember    = "member"
+
+	DisappearingOff = "off"
+
+	EditWindowOff     = "off"     // messages can be edited at any time
+	EditWindowDefault = "default" // back to CHAT.EDIT_WINDOW
+)
+
+// DisappearingTimers are the timers a room can pick for its messages
+var DisappearingTimers = map[string]time.Duration{
+	DisappearingOff: 0,
+	"1h":            time.Hour,
+	"24h":           24 * time.Hour,
+	"7d":            7 * 24 * time.Hour,
+}
+
 type Room struct {
 	ID        uuid.UUID `gorm:"primaryKey"`
 	RT        string    `gorm:"not null"`
@@ -14,6 +37,10 @@ type Room struct {
 	CreatedAt time.Time `gorm:"not null"`
 	UpdatedAt *time.Time
 	DeletedAt *time.Time
+	// disappearing messages timer, 0 means off
+	MessageTTLSeconds int `gorm:"column:message_ttl_seconds;not null;default:0"`
+	// how long after sending a message can be edited, nil uses CHAT.EDIT_WINDOW and 0 means no limit
+	EditWindowSeconds *int `gorm:"column:edit_window_seconds"`
 }
 
 type RoomMember struct {
@@ -23,7 +50,24 @@ type RoomMember struct {
 	Role          string    `gorm:"not null"`
 	JoinedAt      time.Time `gorm:"autoCreateTime"`
 	LeftAt        *time.Time
-	LastReadMsgID string
-	LastMessageAt time.Time
+	LastReadMsgID string // read up to cursor, hex ObjectID of the last read message
+	LastReadAt    *time.Time
+	LastMessageAt *time.Time // NULL until the first message, the inbox falls back to JoinedAt
 	UnreadCount   int64
+	IsMuted       bool `gorm:"not null;default:false"`
+	IsArchived    bool `gorm:"not null;default:false"`
+}
+
+// InboxEntry is a room in the inbox of a member, LastActivityAt falls back to the join time until the first message
+type InboxEntry struct {
+	RoomMember
+	RoomType       string
+	RoomName       string
+	LastActivityAt time.Time
+}
+
+// InboxCursor points at the last entry of an inbox page, the next page starts right after it
+type InboxCursor struct {
+	LastActivityAt time.Time
+	RoomID         string
 }
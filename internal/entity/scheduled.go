@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduledMessage is a private message waiting in scheduled_messages until SendAt
+type ScheduledMessage struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty"`
+	RoomID     string              `bson:"room_id"`
+	SenderID   string              `bson:"sender_id"`
+	ReceiverID string              `bson:"receiver_id"`
+	Content    string              `bson:"content"`
+	SendAt     time.Time           `bson:"send_at"`
+	Status     string              `bson:"status"`
+	Version    int                 `bson:"version"`              // bumped on every edit, a queued job only delivers the version it was created for
+	MessageID  *primitive.ObjectID `bson:"message_id,omitempty"` // set once the message is sent
+	Failure    string              `bson:"failure,omitempty"`
+	ClaimedAt  *time.Time          `bson:"claimed_at,omitempty"` // when the worker started sending, a stale claim is handed back
+	CreatedAt  time.Time           `bson:"created_at"`
+	UpdatedAt  *time.Time          `bson:"updated_at,omitempty"`
+	SentAt     *time.Time          `bson:"sent_at,omitempty"`
+}
+
+const (
+	ScheduledStatusPending   = "scheduled"
+	ScheduledStatusSending   = "sending"
+	ScheduledStatusSent      = "sent"
+	ScheduledStatusCancelled = "cancelled"
+	ScheduledStatusFailed    = "failed"
+)
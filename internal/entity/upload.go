@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+const (
+	AttachmentTypeImage = "image"
+	AttachmentTypeVideo = "video"
+	AttachmentTypeAudio = "audio"
+	AttachmentTypeFile  = "file"
+)
+
+// FileUpload is an uploaded file, RoomID and MessageID are set once it is attached to a message
+// and an upload can only be attached once. The image fields are filled in by the process_attachments job.
+type FileUpload struct {
+	ID                string     `gorm:"primaryKey;default:uuid_generate_v4()"`
+	UploaderID        string     `gorm:"not null"`
+	Filename          string     `gorm:"not null"`
+	MimeType          string     `gorm:"not null"`
+	Size              int64      `gorm:"not null"`
+	StorageKey        string     `gorm:"not null"`
+	RoomID            *string    `gorm:"default:null"`
+	MessageID         *string    `gorm:"default:null"`
+	Width             int        `gorm:"default:null"`
+	Height            int        `gorm:"default:null"`
+	ThumbnailKey      string     `gorm:"default:null"`
+	ThumbnailMimeType string     `gorm:"default:null"`
+	ProcessedAt       *time.Time `gorm:"default:null"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,103 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) AddBookmark(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.AddBookmark(r.Context(), userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message bookmarked", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := h.Service.RemoveBookmark(r.Context(), userID, messageID); err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("bookmark removed", "OK", reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.GetPrivateMessagesRequest
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	// pagination from query params, before_id is the next_cursor of the previous page
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.GetBookmarks(r.Context(), req, userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("bookmarks fetch successfully", *resp, reqID))
+
+	return nil
+}
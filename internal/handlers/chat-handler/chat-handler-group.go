@@ -0,0 +1,129 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) CreateGroup(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.CreateGroupRequest
+	defer r.Body.Close()
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.CreateGroup(r.Context(), req, userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("group created successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) RenameGroup(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.UpdateGroupRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.RenameGroup(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("group renamed successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetGroupMembers(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetGroupMembers(r.Context(), userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("group members fetch successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := h.Service.DeleteGroup(r.Context(), userID, roomID); err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("group deleted successfully", "OK", reqID))
+
+	return nil
+}
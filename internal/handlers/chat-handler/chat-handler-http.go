@@ -1,9 +1,11 @@
 package chat_handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -13,6 +15,7 @@ import (
 	"github.com/xenn00/chat-system/internal/handlers"
 	"github.com/xenn00/chat-system/internal/middleware"
 	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/unfurl"
 	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
 	"github.com/xenn00/chat-system/state"
 )
@@ -35,6 +38,15 @@ func NewChatHandler(state *state.AppState) *ChatHandler {
 	}
 }
 
+// clientMsgID is the client message id of a send, the Idempotency-Key header is used when the body has none
+func clientMsgID(r *http.Request, fromBody string) string {
+	if fromBody != "" {
+		return fromBody
+	}
+
+	return r.Header.Get("Idempotency-Key")
+}
+
 func (h *ChatHandler) SendPrivateMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
 	var req chat_dto.SendPrivateMessageRequest
 	defer r.Body.Close()
@@ -44,6 +56,7 @@ func (h *ChatHandler) SendPrivateMessage(w http.ResponseWriter, r *http.Request)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
 	}
+	req.ClientMsgID = clientMsgID(r, req.ClientMsgID)
 
 	if err := h.Validate.Struct(req); err != nil {
 		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
@@ -67,32 +80,61 @@ func (h *ChatHandler) SendPrivateMessage(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(handlers.CreateResponse("message sent successfully", *resp, reqID))
 
+	// a retried send was already broadcast by the first one
+	if resp.Replayed {
+		return nil
+	}
+
 	// notif / ws broadcast
 	go func() {
 		if err := h.broadcastPrivateMessage(resp); err != nil {
 			log.Error().Err(err).Msg("failed to broadcast message")
 		}
 	}()
+	if len(resp.Attachments) > 0 {
+		go h.processAttachments(resp.MessageID)
+	}
+	if unfurl.HasLinks(resp.Content) {
+		go h.unfurlLinks(resp.MessageID)
+	}
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.SenderID, resp.PlainText, resp.Mentioned)
+	}
+	if len(resp.Unread) > 0 {
+		go h.broadcastUnreadChanged(resp.RoomID, resp.Unread)
+	}
 
 	return nil
 }
 
 func (h *ChatHandler) GetPrivateMessages(w http.ResponseWriter, r *http.Request) *app_error.AppError {
 	var req chat_dto.GetPrivateMessagesRequest
-	defer r.Body.Close()
 
-	// get room_id from query param
+	// get room_id from uri param, pagination from query params (limit, before_id)
 	roomID := chi.URLParam(r, "roomId")
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), "body")
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
 	}
 
 	if err := h.Validate.Struct(req); err != nil {
 		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
 	}
 
-	resp, err := h.Service.GetPrivateMessage(r.Context(), req, roomID)
+	resp, err := h.Service.GetPrivateMessage(r.Context(), req, userID, roomID)
 	if err != nil {
 		return err
 	}
@@ -125,6 +167,7 @@ func (h *ChatHandler) ReplyPrivateMessage(w http.ResponseWriter, r *http.Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
 	}
+	req.ClientMsgID = clientMsgID(r, req.ClientMsgID)
 
 	if err := h.Validate.Struct(req); err != nil {
 		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
@@ -143,12 +186,116 @@ func (h *ChatHandler) ReplyPrivateMessage(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(handlers.CreateResponse("message replied successfully", *resp, reqID))
 
+	// a retried send was already broadcast by the first one
+	if resp.Replayed {
+		return nil
+	}
+
 	// notif / ws broadcast
 	go func() {
 		if err := h.broadcastPrivateMessageReply(resp); err != nil {
 			log.Error().Err(err).Msg("failed to broadcast message reply")
 		}
 	}()
+	if len(resp.Attachments) > 0 {
+		go h.processAttachments(resp.MessageID)
+	}
+	if unfurl.HasLinks(resp.Content) {
+		go h.unfurlLinks(resp.MessageID)
+	}
+	if resp.Thread != nil {
+		go h.broadcastThreadUpdated(resp.RoomID, resp.MessageID, resp.SenderID, resp.Thread)
+	}
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.SenderID, resp.PlainText, resp.Mentioned)
+	}
+	if len(resp.Unread) > 0 {
+		go h.broadcastUnreadChanged(resp.RoomID, resp.Unread)
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) GetMentions(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.GetPrivateMessagesRequest
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.GetMentions(r.Context(), req, userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("mentions fetch successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.GetPrivateMessagesRequest
+
+	// pagination from query params (limit, before_id), same as the room history
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.GetThread(r.Context(), req, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("thread fetch successfully", *resp, reqID))
 
 	return nil
 }
@@ -168,7 +315,8 @@ func (h *ChatHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Request)
 		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
 	}
 
-	if err := h.Service.MarkPrivateMessageAsRead(r.Context(), userID, roomID, messageID); err != nil {
+	resp, err := h.Service.MarkMessageAsRead(r.Context(), userID, roomID, messageID)
+	if err != nil {
 		return err
 	}
 
@@ -178,7 +326,148 @@ func (h *ChatHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(handlers.CreateResponse("message marked as read successfully", "OK", reqID))
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message marked as read successfully", *resp, reqID))
+
+	// notif / ws broadcast, only when the cursor moved
+	if resp.Advanced {
+		go h.broadcastMessageRead(resp)
+	}
+	if resp.UnreadCount != nil {
+		go h.broadcastUnreadChanged(resp.RoomID, map[string]int64{resp.UserID: *resp.UnreadCount})
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) GetMessageReceipts(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetMessageReceipts(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message receipts fetch successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetMessageHistory(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetMessageHistory(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message history fetch successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	// scope is me (default) or everyone, everyone replaces the message with a tombstone for all members
+	scope := r.URL.Query().Get("scope")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.DeleteMessage(r.Context(), userID, roomID, messageID, scope)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message deleted", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastMessageDeleted(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) AddReaction(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	return h.handleReaction(w, r, h.Service.AddReaction, "reaction added")
+}
+
+func (h *ChatHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	return h.handleReaction(w, r, h.Service.RemoveReaction, "reaction removed")
+}
+
+type reactionFunc func(ctx context.Context, req chat_dto.ReactionRequest, userID, roomID, messageID string) (*chat_dto.MessageReactionResponse, *app_error.AppError)
+
+// handleReaction serves both reaction endpoints, they only differ in the service call
+func (h *ChatHandler) handleReaction(w http.ResponseWriter, r *http.Request, react reactionFunc, msg string) *app_error.AppError {
+	var req chat_dto.ReactionRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := react(r.Context(), req, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse(msg, *resp, reqID))
+
+	// notif / ws broadcast, repeated toggles do not change anything
+	if resp.Changed {
+		go h.broadcastMessageReaction(resp)
+	}
 
 	return nil
 }
@@ -224,6 +513,12 @@ func (h *ChatHandler) UpdatePrivateMessage(w http.ResponseWriter, r *http.Reques
 
 	// notif / ws broadcast
 	go h.broadcastPrivateMessageUpdated(resp)
+	// previews of links that were edited away are removed too
+	go h.unfurlLinks(resp.MessageID)
+	// a moderator edit mentions in the name of the moderator, not the author
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.EditedBy, resp.PlainText, resp.Mentioned)
+	}
 
 	return nil
 }
@@ -0,0 +1,90 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) GetInbox(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.GetPrivateMessagesRequest
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	// pagination from query params, before_id is the next_cursor of the previous page
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.GetInbox(r.Context(), req, userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("rooms fetch successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) SetInboxSettings(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.InboxSettingsRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.SetInboxSettings(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("inbox settings updated", *resp, reqID))
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) InviteToGroup(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.InviteMembersRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.InviteToGroup(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("invitations sent successfully", resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetPendingInvitations(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetPendingInvitations(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("invitations fetch successfully", resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	invitationID := chi.URLParam(r, "inviteId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.AcceptInvitation(r.Context(), userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("invitation accepted", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastRoomJoined(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	invitationID := chi.URLParam(r, "inviteId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := h.Service.DeclineInvitation(r.Context(), userID, invitationID); err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("invitation declined", "OK", reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) CreateInviteLink(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.CreateInviteLinkRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.CreateInviteLink(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("invite link created successfully", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) JoinByInviteLink(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	code := chi.URLParam(r, "code")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.JoinByInviteLink(r.Context(), userID, code)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("joined group successfully", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastRoomJoined(resp)
+
+	return nil
+}
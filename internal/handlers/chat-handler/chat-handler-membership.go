@@ -0,0 +1,100 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.LeaveRoom(r.Context(), userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("left group successfully", *resp, reqID))
+
+	// notif / ws broadcast, also drops the live connections of the user from the room
+	go h.broadcastRoomLeft(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) RemoveMember(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	targetID := chi.URLParam(r, "userId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.RemoveMember(r.Context(), userID, roomID, targetID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("member removed successfully", *resp, reqID))
+
+	// notif / ws broadcast, also drops the live connections of the user from the room
+	go h.broadcastRoomLeft(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) GetMembershipEvents(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		limit = parsed
+	}
+
+	resp, err := h.Service.GetMembershipEvents(r.Context(), userID, roomID, limit)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("membership events fetch successfully", *resp, reqID))
+
+	return nil
+}
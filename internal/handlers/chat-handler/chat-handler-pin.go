@@ -0,0 +1,92 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) PinMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.PinMessage(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message pinned", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastMessagePinned(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.UnpinMessage(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message unpinned", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastMessagePinned(resp)
+
+	return nil
+}
+
+func (h *ChatHandler) GetRoomPins(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetRoomPins(r.Context(), userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("pins fetch successfully", *resp, reqID))
+
+	return nil
+}
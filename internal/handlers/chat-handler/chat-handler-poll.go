@@ -0,0 +1,138 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+func (h *ChatHandler) CreatePoll(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.CreatePollRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.CreatePoll(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("poll created", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastGroupMessage(websocket.MessageTypeChatMessage, resp)
+	if len(resp.Unread) > 0 {
+		go h.broadcastUnreadChanged(resp.RoomID, resp.Unread)
+	}
+	if resp.Poll.ClosesAt != nil {
+		go h.scheduleClosePoll(resp.MessageID, *resp.Poll.ClosesAt)
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) VotePoll(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.VotePollRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.VotePoll(r.Context(), req, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	h.writePollUpdated(w, r, "vote recorded", resp)
+	return nil
+}
+
+func (h *ChatHandler) RetractPollVote(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.RetractPollVote(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	h.writePollUpdated(w, r, "vote retracted", resp)
+	return nil
+}
+
+func (h *ChatHandler) ClosePoll(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.ClosePoll(r.Context(), userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	h.writePollUpdated(w, r, "poll closed", resp)
+	return nil
+}
+
+// writePollUpdated answers with the tally as the caller sees it and sends it to the room as a poll_updated event
+func (h *ChatHandler) writePollUpdated(w http.ResponseWriter, r *http.Request, msg string, resp *chat_dto.PollUpdatedResponse) {
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse(msg, *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastPollUpdated(resp)
+}
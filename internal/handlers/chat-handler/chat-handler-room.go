@@ -0,0 +1,312 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+	"github.com/xenn00/chat-system/internal/unfurl"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+func (h *ChatHandler) SendRoomMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.SendRoomMessageRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+	req.ClientMsgID = clientMsgID(r, req.ClientMsgID)
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.SendRoomMessage(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message sent successfully", *resp, reqID))
+
+	// a retried send was already broadcast by the first one
+	if resp.Replayed {
+		return nil
+	}
+
+	// notif / ws broadcast
+	go h.broadcastGroupMessage(websocket.MessageTypeChatMessage, resp)
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.SenderID, resp.PlainText, resp.Mentioned)
+	}
+	if len(resp.Unread) > 0 {
+		go h.broadcastUnreadChanged(resp.RoomID, resp.Unread)
+	}
+	if len(resp.Attachments) > 0 {
+		go h.processAttachments(resp.MessageID)
+	}
+	if unfurl.HasLinks(resp.Content) {
+		go h.unfurlLinks(resp.MessageID)
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) ReplyRoomMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.ReplyRoomMessageRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+	req.ClientMsgID = clientMsgID(r, req.ClientMsgID)
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.ReplyRoomMessage(r.Context(), req, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message replied successfully", *resp, reqID))
+
+	// a retried send was already broadcast by the first one
+	if resp.Replayed {
+		return nil
+	}
+
+	// notif / ws broadcast
+	go h.broadcastGroupMessage(websocket.MessageTypeChatMessage, resp)
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.SenderID, resp.PlainText, resp.Mentioned)
+	}
+	if len(resp.Unread) > 0 {
+		go h.broadcastUnreadChanged(resp.RoomID, resp.Unread)
+	}
+	if len(resp.Attachments) > 0 {
+		go h.processAttachments(resp.MessageID)
+	}
+	if unfurl.HasLinks(resp.Content) {
+		go h.unfurlLinks(resp.MessageID)
+	}
+	if resp.Thread != nil {
+		go h.broadcastThreadUpdated(resp.RoomID, resp.MessageID, resp.SenderID, resp.Thread)
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) UpdateRoomMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.UpdateRoomMessageRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+	messageID := chi.URLParam(r, "messageId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.UpdateRoomMessage(r.Context(), req, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message edited", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastGroupMessage(websocket.MessageTypeMessageUpdated, resp)
+	// previews of links that were edited away are removed too
+	go h.unfurlLinks(resp.MessageID)
+	// a moderator edit mentions in the name of the moderator, not the author
+	if len(resp.Mentioned) > 0 {
+		go h.broadcastMention(resp.RoomID, resp.MessageID, resp.EditedBy, resp.PlainText, resp.Mentioned)
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) ForwardMessages(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.ForwardMessagesRequest
+	defer r.Body.Close()
+
+	// roomId is the source room of the forwarded messages
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+	req.ClientMsgID = clientMsgID(r, req.ClientMsgID)
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.ForwardMessages(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("messages forwarded successfully", *resp, reqID))
+
+	// a retried send was already broadcast by the first one
+	if resp.Replayed {
+		return nil
+	}
+
+	// notif / ws broadcast, one regular chat_message per copy
+	for _, msg := range resp.Messages {
+		go h.broadcastGroupMessage(websocket.MessageTypeChatMessage, msg)
+		if len(msg.Unread) > 0 {
+			go h.broadcastUnreadChanged(msg.RoomID, msg.Unread)
+		}
+	}
+
+	return nil
+}
+
+func (h *ChatHandler) SetDisappearingTimer(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.DisappearingTimerRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.SetDisappearingTimer(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("disappearing timer updated", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastSystemMessage(resp.RoomID, fmt.Sprintf("%s set disappearing messages to %s", resp.UpdatedBy, resp.Timer), map[string]string{
+		"event":       "disappearing_timer_changed",
+		"timer":       resp.Timer,
+		"ttl_seconds": fmt.Sprint(resp.TTLSeconds),
+		"updated_by":  resp.UpdatedBy,
+	}, resp.Recipients)
+
+	return nil
+}
+
+func (h *ChatHandler) SetEditWindow(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.EditWindowRequest
+	defer r.Body.Close()
+
+	roomID := chi.URLParam(r, "roomId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.SetEditWindow(r.Context(), req, userID, roomID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("edit window updated", *resp, reqID))
+
+	// notif / ws broadcast
+	go h.broadcastSystemMessage(resp.RoomID, fmt.Sprintf("%s set the edit window to %s", resp.UpdatedBy, resp.Window), map[string]string{
+		"event":          "edit_window_changed",
+		"window":         resp.Window,
+		"window_seconds": fmt.Sprint(resp.WindowSeconds),
+		"updated_by":     resp.UpdatedBy,
+	}, resp.Recipients)
+
+	return nil
+}
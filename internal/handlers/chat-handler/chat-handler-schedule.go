@@ -0,0 +1,145 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) ScheduleMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.ScheduleMessageRequest
+	defer r.Body.Close()
+
+	receiverID := chi.URLParam(r, "receiverId")
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.ScheduleMessage(r.Context(), req, userID, receiverID)
+	if err != nil {
+		return err
+	}
+
+	// without a job the message would never fire, so don't leave it pending
+	if err := h.scheduleDelivery(resp); err != nil {
+		if _, cancelErr := h.Service.CancelScheduledMessage(r.Context(), userID, resp.ScheduledID); cancelErr != nil {
+			log.Error().Str("scheduled_id", resp.ScheduledID).Str("error", cancelErr.Message).Msg("failed to cancel unqueued scheduled message")
+		}
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to schedule message", "queue")
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(handlers.CreateResponse("message scheduled", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) GetScheduledMessages(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.GetScheduledMessages(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("scheduled messages fetched", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) UpdateScheduledMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	var req chat_dto.UpdateScheduledMessageRequest
+	defer r.Body.Close()
+
+	scheduledID := chi.URLParam(r, "scheduledId")
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "Invalid JSON", "body")
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.UpdateScheduledMessage(r.Context(), req, userID, scheduledID)
+	if err != nil {
+		return err
+	}
+
+	// the job queued for the previous version drops itself, this one carries the new version
+	if err := h.scheduleDelivery(resp); err != nil {
+		if _, cancelErr := h.Service.CancelScheduledMessage(r.Context(), userID, resp.ScheduledID); cancelErr != nil {
+			log.Error().Str("scheduled_id", resp.ScheduledID).Str("error", cancelErr.Message).Msg("failed to cancel unqueued scheduled message")
+		}
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to reschedule message, it has been cancelled", "queue")
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("scheduled message updated", *resp, reqID))
+
+	return nil
+}
+
+func (h *ChatHandler) CancelScheduledMessage(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	scheduledID := chi.URLParam(r, "scheduledId")
+
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	resp, err := h.Service.CancelScheduledMessage(r.Context(), userID, scheduledID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("scheduled message cancelled", *resp, reqID))
+
+	return nil
+}
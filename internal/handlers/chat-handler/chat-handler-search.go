@@ -0,0 +1,99 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+)
+
+func (h *ChatHandler) SearchMessages(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	return h.handleSearch(w, r, nil)
+}
+
+func (h *ChatHandler) SearchRoomMessages(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	roomID := chi.URLParam(r, "roomId")
+	return h.handleSearch(w, r, &roomID)
+}
+
+// handleSearch serves both search endpoints, the room endpoint pins room_id to its uri param
+func (h *ChatHandler) handleSearch(w http.ResponseWriter, r *http.Request, roomID *string) *app_error.AppError {
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	req, appErr := parseSearchRequest(r)
+	if appErr != nil {
+		return appErr
+	}
+	if roomID != nil {
+		req.RoomID = roomID
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid fields: %v", err), "validation")
+	}
+
+	resp, err := h.Service.SearchMessages(r.Context(), req, userID)
+	if err != nil {
+		return err
+	}
+
+	reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+	if !ok {
+		reqID = "unknown"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(handlers.CreateResponse("messages searched successfully", *resp, reqID))
+
+	return nil
+}
+
+// parseSearchRequest reads q, room_id, sender_id, from, to (RFC3339), limit and before_id from the query string
+func parseSearchRequest(r *http.Request) (chat_dto.SearchMessagesRequest, *app_error.AppError) {
+	var req chat_dto.SearchMessagesRequest
+
+	query := r.URL.Query()
+	req.Query = query.Get("q")
+	if roomID := query.Get("room_id"); roomID != "" {
+		req.RoomID = &roomID
+	}
+	if senderID := query.Get("sender_id"); senderID != "" {
+		req.SenderID = &senderID
+	}
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return req, app_error.NewAppError(http.StatusBadRequest, "from must be an RFC3339 timestamp", "from")
+		}
+		req.From = &from
+	}
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return req, app_error.NewAppError(http.StatusBadRequest, "to must be an RFC3339 timestamp", "to")
+		}
+		req.To = &to
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return req, app_error.NewAppError(http.StatusBadRequest, "limit must be a number", "limit")
+		}
+		req.Limit = limit
+	}
+	if beforeID := query.Get("before_id"); beforeID != "" {
+		req.BeforeID = &beforeID
+	}
+
+	return req, nil
+}
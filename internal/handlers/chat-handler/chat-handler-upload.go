@@ -0,0 +1,127 @@
+package chat_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/handlers"
+	"github.com/xenn00/chat-system/internal/middleware"
+	"github.com/xenn00/chat-system/internal/storage"
+	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
+)
+
+// multipartOverhead leaves room for the boundaries and part headers around the file
+const multipartOverhead = 64 << 10
+
+// UploadAttachment streams the multipart "file" part to the blob store without buffering it in memory
+func (h *ChatHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	userID, ok := r.Context().Value(middleware.UserClaimsKey).(string)
+	if !ok || userID == "" {
+		return app_error.NewAppError(http.StatusUnauthorized, "user id is not found in context", "context")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, chat_service.MaxUploadSize()+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "request must be multipart/form-data", "content-type")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return app_error.NewAppError(http.StatusBadRequest, "file is required", "file")
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return app_error.NewAppError(http.StatusRequestEntityTooLarge, "request body is too large", "file")
+			}
+			return app_error.NewAppError(http.StatusBadRequest, "invalid multipart body", "file")
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		resp, appErr := h.Service.UploadAttachment(r.Context(), userID, part.FileName(), part)
+		part.Close()
+		if appErr != nil {
+			return appErr
+		}
+
+		reqID, ok := r.Context().Value(middleware.RequestIdKey).(string)
+		if !ok {
+			reqID = "unknown"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(handlers.CreateResponse("file uploaded", *resp, reqID))
+
+		return nil
+	}
+}
+
+// DownloadFile serves a file through a signed URL, the signature replaces the access token
+// so the link works in plain <img> and <a> tags
+func (h *ChatHandler) DownloadFile(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	uploadID := chi.URLParam(r, "uploadId")
+
+	query := r.URL.Query()
+	if !storage.VerifySignedURL(r.URL.Path, query.Get("expires"), query.Get("sig"), time.Now()) {
+		return app_error.NewAppError(http.StatusForbidden, "link is invalid or expired", "sig")
+	}
+
+	file, content, err := h.Service.OpenUpload(r.Context(), uploadID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	serveFile(w, file, content, "attachment")
+	return nil
+}
+
+// DownloadThumbnail serves the preview of an image upload through its own signed URL
+func (h *ChatHandler) DownloadThumbnail(w http.ResponseWriter, r *http.Request) *app_error.AppError {
+	uploadID := chi.URLParam(r, "uploadId")
+
+	query := r.URL.Query()
+	if !storage.VerifySignedURL(r.URL.Path, query.Get("expires"), query.Get("sig"), time.Now()) {
+		return app_error.NewAppError(http.StatusForbidden, "link is invalid or expired", "sig")
+	}
+
+	file, content, err := h.Service.OpenThumbnail(r.Context(), uploadID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	serveFile(w, file, content, "inline")
+	return nil
+}
+
+func serveFile(w http.ResponseWriter, file *chat_dto.UploadResponse, content io.Reader, disposition string) {
+	w.Header().Set("Content-Type", file.MimeType)
+	if file.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": file.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+
+	if _, err := io.Copy(w, content); err != nil {
+		log.Warn().Err(err).Str("upload_id", file.UploadID).Msg("failed to stream file")
+	}
+}
@@ -12,13 +12,17 @@ import (
 
 func (h *ChatHandler) broadcastPrivateMessage(resp *chat_dto.SendPrivateMessageResponse) error {
 	jobPayload := &types.BroadcastMessagePayload{
-		MessageID:  resp.MessageID,
-		RoomID:     resp.RoomID,
-		SenderID:   resp.SenderID,
-		ReceiverID: resp.ReceiverID,
-		Content:    resp.Content,
+		MessageID:   resp.MessageID,
+		ClientMsgID: resp.ClientMsgID,
+		RoomID:      resp.RoomID,
+		SenderID:    resp.SenderID,
+		ReceiverID:  resp.ReceiverID,
+		Content:     resp.Content,
+		Format:      resp.Format,
+		Attachments: toAttachmentPayloads(resp.Attachments),
 
 		CreatedAt: resp.CreatedAt,
+		ExpiresAt: resp.ExpiresAt,
 	}
 
 	job := queue.Job{
@@ -43,18 +47,22 @@ func (h *ChatHandler) broadcastPrivateMessage(resp *chat_dto.SendPrivateMessageR
 
 func (h *ChatHandler) broadcastPrivateMessageReply(resp *chat_dto.ReplyPrivateMessageResponse) error {
 	jobPayload := &types.BroadcastMessagePayload{
-		MessageID:  resp.MessageID,
-		RoomID:     resp.RoomID,
-		SenderID:   resp.SenderID,
-		ReceiverID: resp.ReceiverID,
-		Content:    resp.Content,
-		IsRead:     &resp.IsRead,
+		MessageID:   resp.MessageID,
+		ClientMsgID: resp.ClientMsgID,
+		RoomID:      resp.RoomID,
+		SenderID:    resp.SenderID,
+		ReceiverID:  resp.ReceiverID,
+		Content:     resp.Content,
+		Format:      resp.Format,
+		Attachments: toAttachmentPayloads(resp.Attachments),
+		IsRead:      &resp.IsRead,
 		ReplyTo: &types.ReplyTo{
 			MessageID: resp.ReplyTo.RepliedMessageID,
 			Content:   resp.ReplyTo.Content,
 			SenderID:  resp.ReplyTo.SenderID,
 		},
 		CreatedAt: resp.CreatedAt,
+		ExpiresAt: resp.ExpiresAt,
 	}
 
 	job := queue.Job{
@@ -85,11 +93,22 @@ func (h *ChatHandler) broadcastPrivateMessageUpdated(resp *chat_dto.UpdatePrivat
 		SenderID:   resp.SenderID,
 		ReceiverID: resp.ReceiverID,
 		Content:    resp.Content,
+		Format:     resp.Format,
 		IsRead:     &resp.IsRead,
 		IsEdited:   &resp.IsEdited,
 		UpdatedAt:  &resp.UpdatedAt,
 	}
 
+	for _, entry := range resp.MessageEditHistory {
+		jobPayload.MessageEditHistory = append(jobPayload.MessageEditHistory, &types.MessageEditEntry{
+			MessageID:       entry.MessageID,
+			OriginalContent: entry.OriginalContent,
+			NewContent:      entry.NewContent,
+			EditedBy:        entry.EditedBy,
+			EditedAt:        entry.EditedAt,
+		})
+	}
+
 	job := queue.Job{
 		ID:        uuid.New().String(),
 		Type:      "broadcast_private_message_updated",
@@ -105,3 +124,504 @@ func (h *ChatHandler) broadcastPrivateMessageUpdated(resp *chat_dto.UpdatePrivat
 		log.Error().Err(err).Msg("Failed to enqueue job")
 	}
 }
+
+func (h *ChatHandler) broadcastRoomJoined(resp *chat_dto.RoomJoinedResponse) {
+	jobPayload := &types.RoomJoinedPayload{
+		RoomID:       resp.RoomID,
+		UserID:       resp.UserID,
+		Participants: resp.Participants,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_room_joined",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastGroupMessage(event string, resp *chat_dto.RoomMessageResponse) {
+	message := types.BroadcastMessagePayload{
+		MessageID:   resp.MessageID,
+		ClientMsgID: resp.ClientMsgID,
+		RoomID:      resp.RoomID,
+		SenderID:    resp.SenderID,
+		ReceiverID:  resp.ReceiverID,
+		Content:     resp.Content,
+		Format:      resp.Format,
+		IsEdited:    &resp.IsEdited,
+		CreatedAt:   resp.CreatedAt,
+		UpdatedAt:   resp.UpdatedAt,
+		ExpiresAt:   resp.ExpiresAt,
+	}
+
+	if resp.ReplyTo != nil {
+		message.ReplyTo = &types.ReplyTo{
+			MessageID: resp.ReplyTo.RepliedMessageID,
+			Content:   resp.ReplyTo.Content,
+			SenderID:  resp.ReplyTo.SenderID,
+		}
+	}
+
+	if resp.ForwardedFrom != nil {
+		message.ForwardedFrom = &types.ForwardedFrom{
+			MessageID: resp.ForwardedFrom.MessageID,
+			RoomID:    resp.ForwardedFrom.RoomID,
+			SenderID:  resp.ForwardedFrom.SenderID,
+		}
+	}
+
+	message.Attachments = toAttachmentPayloads(resp.Attachments)
+
+	if resp.Poll != nil {
+		poll := toPollPayload(resp.Poll)
+		message.MessageType = resp.MessageType
+		message.Poll = &poll
+	}
+
+	for _, entry := range resp.MessageEditHistory {
+		message.MessageEditHistory = append(message.MessageEditHistory, &types.MessageEditEntry{
+			MessageID:       entry.MessageID,
+			OriginalContent: entry.OriginalContent,
+			NewContent:      entry.NewContent,
+			EditedBy:        entry.EditedBy,
+			EditedAt:        entry.EditedAt,
+		})
+	}
+
+	jobPayload := &types.BroadcastGroupMessagePayload{
+		Event:      event,
+		Recipients: resp.Recipients,
+		Message:    message,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_group_message",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+		return
+	}
+
+	log.Info().Str("job_id", job.ID).Str("message_id", resp.MessageID).Int("recipients", len(resp.Recipients)).Msg("Broadcast job enqueued successfully")
+}
+
+func (h *ChatHandler) broadcastSystemMessage(roomID, content string, data map[string]string, recipients []string) {
+	jobPayload := &types.SystemMessagePayload{
+		RoomID:     roomID,
+		Content:    content,
+		Data:       data,
+		Recipients: recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_system_message",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastRoomLeft(resp *chat_dto.RoomLeftResponse) {
+	jobPayload := &types.RoomLeftPayload{
+		RoomID:     resp.RoomID,
+		UserID:     resp.UserID,
+		Reason:     resp.Reason,
+		ActorID:    resp.ActorID,
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_room_left",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  1,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastMessageRead(resp *chat_dto.ReadCursorResponse) {
+	jobPayload := &types.MessageReadPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.LastReadMsgID,
+		ReadBy:     resp.UserID,
+		ReadAt:     resp.ReadAt,
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_message_read",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastMessageDeleted(resp *chat_dto.MessageDeletedResponse) {
+	jobPayload := &types.MessageDeletedPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.MessageID,
+		Scope:      resp.Scope,
+		DeletedBy:  resp.DeletedBy,
+		DeletedAt:  resp.DeletedAt,
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_message_deleted",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastMessageReaction(resp *chat_dto.MessageReactionResponse) {
+	reactions := make([]types.ReactionCountPayload, 0, len(resp.Reactions))
+	for _, reaction := range resp.Reactions {
+		reactions = append(reactions, types.ReactionCountPayload{
+			Emoji: reaction.Emoji,
+			Count: reaction.Count,
+		})
+	}
+
+	jobPayload := &types.MessageReactionPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.MessageID,
+		UserID:     resp.UserID,
+		Emoji:      resp.Emoji,
+		Action:     resp.Action,
+		Reactions:  reactions,
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_message_reaction",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastThreadUpdated(roomID, replyID, replierID string, thread *chat_dto.ThreadSummary) {
+	jobPayload := &types.ThreadUpdatedPayload{
+		RoomID:        roomID,
+		RootMessageID: thread.RootMessageID,
+		ReplyID:       replyID,
+		ReplierID:     replierID,
+		ReplyCount:    thread.ReplyCount,
+		LastReplyAt:   thread.LastReplyAt,
+		Recipients:    thread.Participants,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_thread_updated",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastMention(roomID, messageID, senderID, content string, mentioned []string) {
+	jobPayload := &types.MentionPayload{
+		RoomID:     roomID,
+		MessageID:  messageID,
+		SenderID:   senderID,
+		Content:    content,
+		Recipients: mentioned,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_mention",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+// broadcastUnreadChanged sends every user in counts their new unread count of the room
+func (h *ChatHandler) broadcastUnreadChanged(roomID string, counts map[string]int64) {
+	jobPayload := &types.UnreadChangedPayload{
+		RoomID: roomID,
+		Counts: counts,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_unread_changed",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastMessagePinned(resp *chat_dto.MessagePinnedResponse) {
+	jobPayload := &types.MessagePinnedPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.MessageID,
+		Pinned:     resp.Pinned,
+		PinnedBy:   resp.PinnedBy,
+		PinnedAt:   resp.PinnedAt,
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_message_pinned",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+// scheduledJobGrace is how long a due send job may wait for a busy worker before it counts as expired,
+// messages whose job expired anyway are picked up by the scheduled message recovery
+const scheduledJobGrace = time.Hour
+
+// scheduleDelivery parks the send job until the message is due, the job only sends the version it carries
+func (h *ChatHandler) scheduleDelivery(resp *chat_dto.ScheduledMessageResponse) error {
+	jobPayload := &types.ScheduledMessagePayload{
+		ScheduledID: resp.ScheduledID,
+		Version:     resp.Version,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "send_scheduled_message",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  resp.SendAt.Add(scheduledJobGrace).Unix(),
+	}
+
+	if err := h.Producer.Schedule(h.State.Ctx, job, resp.SendAt); err != nil {
+		log.Error().Err(err).Str("scheduled_id", resp.ScheduledID).Msg("Failed to schedule job")
+		return err
+	}
+
+	log.Info().Str("job_id", job.ID).Str("scheduled_id", resp.ScheduledID).Time("send_at", resp.SendAt).Msg("Scheduled message job queued successfully")
+	return nil
+}
+
+// processAttachments fills in the metadata and thumbnails of the uploads of a new message in the background
+func (h *ChatHandler) processAttachments(messageID string) {
+	jobPayload := &types.ProcessAttachmentsPayload{
+		MessageID: messageID,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "process_attachments",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to enqueue job")
+	}
+}
+
+// unfurlLinks fetches the previews of the links in a new or edited message in the background, on edits it
+// also removes previews of links that are gone
+func (h *ChatHandler) unfurlLinks(messageID string) {
+	jobPayload := &types.UnfurlLinksPayload{
+		MessageID: messageID,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "unfurl_links",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to enqueue job")
+	}
+}
+
+func (h *ChatHandler) broadcastPollUpdated(resp *chat_dto.PollUpdatedResponse) {
+	jobPayload := &types.PollUpdatedPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.MessageID,
+		Poll:       toPollPayload(resp.Poll),
+		Recipients: resp.Recipients,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "broadcast_poll_updated",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Enqueue(h.State.Ctx, job); err != nil {
+		log.Error().Err(err).Msg("Failed to enqueue job")
+	}
+}
+
+// scheduleClosePoll queues the close_poll job for the closing time of a poll
+func (h *ChatHandler) scheduleClosePoll(messageID string, closesAt time.Time) {
+	jobPayload := &types.ClosePollPayload{
+		MessageID: messageID,
+	}
+
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "close_poll",
+		Payload:   queue.MustMarshal(jobPayload),
+		Priority:  2,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  closesAt.Add(1 * time.Minute).Unix(),
+	}
+
+	if err := h.Producer.Schedule(h.State.Ctx, job, closesAt); err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("Failed to schedule job")
+		return
+	}
+
+	log.Info().Str("job_id", job.ID).Str("message_id", messageID).Time("closes_at", closesAt).Msg("Close poll job queued successfully")
+}
+
+func toAttachmentPayloads(attachments []*chat_dto.Attachment) []*types.Attachment {
+	payloads := make([]*types.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		payloads = append(payloads, &types.Attachment{
+			Type:         attachment.Type,
+			URL:          attachment.URL,
+			UploadID:     attachment.UploadID,
+			Filename:     attachment.Filename,
+			Size:         attachment.Size,
+			MimeType:     attachment.MimeType,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+			ThumbnailURL: attachment.ThumbnailURL,
+		})
+	}
+
+	return payloads
+}
+
+// toPollPayload drops the vote of the caller, the payload goes to every member
+func toPollPayload(poll *chat_dto.Poll) types.PollPayload {
+	options := make([]types.PollOptionPayload, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		options = append(options, types.PollOptionPayload{
+			ID:     option.ID,
+			Text:   option.Text,
+			Votes:  option.Votes,
+			Voters: option.Voters,
+		})
+	}
+
+	return types.PollPayload{
+		Question:       poll.Question,
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		IsClosed:       poll.IsClosed,
+		TotalVoters:    poll.TotalVoters,
+	}
+}
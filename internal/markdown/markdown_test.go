@@ -0,0 +1,80 @@
+package markdown
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain markdown is kept", "**bold** _it_ ~~gone~~ [site](https://example.com \"title\")", "**bold** _it_ ~~gone~~ [site](https://example.com \"title\")"},
+		{"raw html is removed", "hi <script>alert(1)</script><b onclick=\"x()\">there</b>", "hi alert(1)there"},
+		{"nested tags", "<scr<b>ipt>alert(1)</script>", "alert(1)"},
+		{"deeply nested tags", "hi <img src=x onerror=alert(1)<a<a<a<a<a<a<a>>>>>>>", "hi &lt;img src=x onerror=alert(1)"},
+		{"unclosed tag", "x <img src=x onerror=alert(1)", "x &lt;img src=x onerror=alert(1)"},
+		{"comments", "a<!-- hidden -->b<!-- open", "ab"},
+		{"javascript link keeps the label", "[click](javascript:alert(1))", "click"},
+		{"obfuscated scheme", "[a](jav&#x09;ascript:alert(1)) [b](JAVASCRIPT:x) [c](java\\script:x)", "a b c"},
+		{"relative and data links", "[a](/etc/passwd) [b](data:text/html,x)", "a b"},
+		{"images become links", "![cat](https://example.com/cat.png) ![x](javascript:x)", "[cat](https://example.com/cat.png) x"},
+		{"autolinks", "<https://example.com> <javascript:alert(1)> <mailto:a@example.com>", "<https://example.com>  <mailto:a@example.com>"},
+		{"reference definitions", "[a][1]\n[1]: javascript:alert(1)\n[2]: https://example.com\n", "[a][1]\n[2]: https://example.com\n"},
+		{"code is literal", "`<b>` and\n```html\n<script>x</script>\n```\n<i>x</i>", "`<b>` and\n```html\n<script>x</script>\n```\nx"},
+		{"comparisons survive", "a < b and c > d", "a < b and c > d"},
+		{"link nested in a label", "[[x](javascript:alert(1))](http://ok)", "\\[\\[x](javascript:alert(1))](http://ok)"},
+		{"reference definition in a quote", "[click][r]\n\n> [r]: javascript:alert(1)", "\\[click]\\[r]\n\n> [r]: javascript:alert(1)"},
+		{"reference definition in a list", "[click][r]\n\n- [r]: javascript:alert(1)", "\\[click]\\[r]\n\n- [r]: javascript:alert(1)"},
+		{"reference destination on the next line", "[x]:\njavascript:alert(1)\n\n[y][x]", "[x]:\njavascript:alert(1)\n\n\\[y]\\[x]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.src); got != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSanitizeLeavesNoUnsafeNode parses the output like a client does, goldmark's own renderer would hide
+// javascript links so the nodes are checked instead
+func TestSanitizeLeavesNoUnsafeNode(t *testing.T) {
+	inputs := []string{
+		"[[x](javascript:alert(1))](http://ok)",
+		"[click][r]\n\n> [r]: javascript:alert(1)",
+		"[click][r]\n\n- [r]: javascript:alert(1)",
+		"[x]:\njavascript:alert(1)\n\n[y][x]",
+		"[![a](https://example.com/a.png)](javascript:x)",
+		"> <img src=x onerror=alert(1)>\n\n- <script>x</script>",
+	}
+
+	for _, src := range inputs {
+		if _, found := findUnsafeNode(Sanitize(src)); found {
+			t.Errorf("Sanitize(%q) = %q still holds an unsafe node", src, Sanitize(src))
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"emphasis", "**bold**, __strong__, *it*, _it_ and ~~old~~", "bold, strong, it, it and old"},
+		{"snake case is not emphasis", "call my_func_name now", "call my_func_name now"},
+		{"links keep the label", "see [the docs](https://example.com) or <https://example.org>", "see the docs or https://example.org"},
+		{"blocks", "# Title #\n> quoted\n- one\n2. two\n\n---\n\n\n\ntext", "Title\nquoted\none\ntwo\n\ntext"},
+		{"code keeps its content", "run `go *test*`\n```\nx := *p\n```", "run go *test*\nx := *p"},
+		{"escapes and entities", "\\*not italic\\* &amp; 1 \\_ 2", "*not italic* & 1 _ 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.src); got != tt.want {
+				t.Errorf("PlainText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
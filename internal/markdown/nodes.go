@@ -0,0 +1,116 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// maxNodePasses bounds how often the text is parsed again, every pass escapes one node
+const maxNodePasses = 200
+
+var commonMark = goldmark.New()
+
+// escapeUnsafeNodes parses the text as CommonMark, the way a client renders it, and escapes the opener of
+// every link with an unsafe target, every image and any html left. This catches what the regexes can't see,
+// like links nested in a label or reference definitions inside quotes and lists or split across lines.
+// Escaping a node can turn what follows into a new one, like \[x][r] where [r] is a link, so the text is
+// parsed again until nothing is left. Returns false when an unsafe node could not be located.
+func escapeUnsafeNodes(src string) (string, bool) {
+	for range maxNodePasses {
+		pos, found := findUnsafeNode(src)
+		if !found {
+			return src, true
+		}
+		if pos < 0 {
+			return src, false
+		}
+		src = src[:pos] + `\` + src[pos:]
+	}
+
+	return src, false
+}
+
+// findUnsafeNode returns where the first unsafe node opens, -1 when it can't tell
+func findUnsafeNode(src string) (int, bool) {
+	source := []byte(src)
+	doc := commonMark.Parser().Parse(text.NewReader(source))
+
+	pos, found := -1, false
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Link:
+			if IsSafeURL(string(node.Destination)) {
+				return ast.WalkContinue, nil
+			}
+			pos = labelOpener(src, node)
+		case *ast.Image:
+			// images become plain links, the link is checked in the next pass
+			if at := labelOpener(src, node); at > 0 && src[at-1] == '!' {
+				pos = at - 1
+			}
+		case *ast.AutoLink:
+			if IsSafeURL(string(node.URL(source))) {
+				return ast.WalkContinue, nil
+			}
+		case *ast.RawHTML:
+			if node.Segments.Len() > 0 {
+				pos = node.Segments.At(0).Start
+			}
+		case *ast.HTMLBlock:
+			if node.Lines().Len() > 0 {
+				line := node.Lines().At(0)
+				if at := strings.IndexByte(src[line.Start:line.Stop], '<'); at >= 0 {
+					pos = line.Start + at
+				}
+			}
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		found = true
+		return ast.WalkStop, nil
+	})
+
+	return pos, found
+}
+
+// labelOpener finds the [ that opens the label of a link or image, the closest unescaped one before its
+// first text. Labels without text can't be located.
+func labelOpener(src string, n ast.Node) int {
+	var first *ast.Text
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := child.(*ast.Text); ok && entering {
+			first = t
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if first == nil {
+		return -1
+	}
+
+	for at := first.Segment.Start - 1; at >= 0; at-- {
+		if src[at] == '[' && !isEscaped(src, at) {
+			return at
+		}
+	}
+
+	return -1
+}
+
+// isEscaped reports whether the byte at i follows an odd number of backslashes
+func isEscaped(src string, i int) bool {
+	slashes := 0
+	for at := i - 1; at >= 0 && src[at] == '\\'; at-- {
+		slashes++
+	}
+
+	return slashes%2 == 1
+}
@@ -0,0 +1,93 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	heading       = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+|$)`)
+	closingHashes = regexp.MustCompile(`[ \t]+#+[ \t]*$`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,}|=+[ \t]*)$`)
+	blockquote    = regexp.MustCompile(`^(?: {0,3}>[ \t]?)+`)
+	listMarker    = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+`)
+	escaped       = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+	refLink       = regexp.MustCompile(`\[([^\[\]\n]+)\]\[[^\[\]\n]*\]`)
+	strong        = regexp.MustCompile(`\*\*(\S(?:[^*]*?\S)?)\*\*`)
+	strongUnder   = regexp.MustCompile(`__(\S(?:[^_]*?\S)?)__`)
+	emphasis      = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	emphasisUnder = regexp.MustCompile(`(^|[^\pL\pN_])_(\S(?:[^_]*?\S)?)_([^\pL\pN_]|$)`)
+	strikethrough = regexp.MustCompile(`~~(\S(?:[^~]*?\S)?)~~`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// escapeSentinel stands in for an escaped character while the inline markup is removed
+const escapeSentinel = "\uE000"
+
+// PlainText renders sanitized markdown as the text a reader sees, without markup. It is what search indexes
+// and what notifications show.
+func PlainText(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	var out strings.Builder
+	for _, seg := range split(src) {
+		switch seg.Kind {
+		case segmentText:
+			out.WriteString(plainText(seg.Raw))
+		default:
+			out.WriteString(seg.Code)
+		}
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func plainText(text string) string {
+	text = linkRefDef.ReplaceAllString(text, "")
+
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if thematicBreak.MatchString(line) {
+			continue
+		}
+
+		line = blockquote.ReplaceAllString(line, "")
+		if heading.MatchString(line) {
+			line = closingHashes.ReplaceAllString(heading.ReplaceAllString(line, ""), "")
+		}
+		line = listMarker.ReplaceAllString(line, "")
+		line = strings.TrimSuffix(line, "\\") // hard line break
+
+		kept = append(kept, line)
+	}
+	text = strings.Join(kept, "\n")
+
+	// escaped characters are literal, hide them from the emphasis patterns
+	var literals []string
+	text = escaped.ReplaceAllStringFunc(text, func(match string) string {
+		literals = append(literals, match[1:])
+		return escapeSentinel
+	})
+
+	text = inlineLink.ReplaceAllString(text, "$2")
+	text = refLink.ReplaceAllString(text, "$1")
+	text = autolink.ReplaceAllString(text, "$1")
+	text = strong.ReplaceAllString(text, "$1")
+	text = strongUnder.ReplaceAllString(text, "$1")
+	text = emphasis.ReplaceAllString(text, "$1")
+	text = emphasisUnder.ReplaceAllString(text, "$1$2$3")
+	text = strikethrough.ReplaceAllString(text, "$1")
+
+	for _, literal := range literals {
+		text = strings.Replace(text, escapeSentinel, literal, 1)
+	}
+
+	return html.UnescapeString(text)
+}
@@ -0,0 +1,140 @@
+// Package markdown keeps message content to a safe markdown subset: emphasis, strikethrough, code, links,
+// quotes, lists and headings. Raw html is removed and links may only point at http, https and mailto URLs.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?(-->|$)`)
+	htmlTag     = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>|<[!?][^<>]*>`)
+	autolink    = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9+.\-]{1,31}:[^\s<>]*)>`)
+	inlineLink  = regexp.MustCompile(`(!?)\[((?:[^\[\]\n]|\[[^\[\]\n]*\])*)\]\(\s*(<[^<>\n]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)(?:\s+(?:"[^"\n]*"|'[^'\n]*'|\([^()\n]*\)))?\s*\)`)
+	imageRef    = regexp.MustCompile(`!(\[[^\[\]\n]*\]\[[^\[\]\n]*\])`)
+	linkRefDef  = regexp.MustCompile(`(?m)^ {0,3}\[[^\[\]\n]+\]:[ \t]*(<[^<>\n]*>|\S+).*(\n|$)`)
+	urlScheme   = regexp.MustCompile(`^([a-z][a-z0-9+.\-]*):`)
+	tagOpener   = regexp.MustCompile(`<([A-Za-z/!?])`)
+)
+
+var linkOpeners = strings.NewReplacer("[", `\[`, "<", `\<`)
+
+// allowedSchemes are the only link targets kept, relative links have no meaning in a chat
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Sanitize removes raw html and unsafe links from markdown, code is kept as written since it renders
+// literally. Images become plain links so a message cannot load remote content on its own. What the line
+// based rewrites leave is checked against a CommonMark parse and escaped.
+func Sanitize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	segments := split(src)
+	for i, seg := range segments {
+		if seg.Kind == segmentText {
+			segments[i].Raw = sanitizeText(seg.Raw)
+		}
+	}
+
+	// the regexes work line by line, a real parse finds what they miss
+	if safe, ok := escapeUnsafeNodes(join(segments)); ok {
+		return safe
+	}
+
+	// an unsafe node the parse could not place, keep no link or html at all then
+	for i, seg := range segments {
+		if seg.Kind == segmentText {
+			segments[i].Raw = linkOpeners.Replace(seg.Raw)
+		}
+	}
+	return join(segments)
+}
+
+func join(segments []segment) string {
+	var out strings.Builder
+	for _, seg := range segments {
+		out.WriteString(seg.Raw)
+	}
+
+	return out.String()
+}
+
+func sanitizeText(text string) string {
+	text = htmlComment.ReplaceAllString(text, "")
+
+	text = autolink.ReplaceAllStringFunc(text, func(match string) string {
+		if !IsSafeURL(autolink.FindStringSubmatch(match)[1]) {
+			return ""
+		}
+		return match
+	})
+
+	// removing a tag can join the halves of another one, like <scr<b>ipt>, so strip until nothing changes.
+	// Every pass makes the text shorter, so this ends.
+	for {
+		stripped := htmlTag.ReplaceAllString(text, "")
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+
+	text = escapeTagOpeners(text)
+
+	text = inlineLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := inlineLink.FindStringSubmatch(match)
+		image, label, dest := parts[1] == "!", parts[2], strings.Trim(parts[3], "<>")
+
+		if !IsSafeURL(dest) {
+			return label
+		}
+		if image {
+			return match[1:]
+		}
+		return match
+	})
+
+	text = imageRef.ReplaceAllString(text, "$1")
+
+	return linkRefDef.ReplaceAllStringFunc(text, func(match string) string {
+		if !IsSafeURL(strings.Trim(linkRefDef.FindStringSubmatch(match)[1], "<>")) {
+			return ""
+		}
+		return match
+	})
+}
+
+// escapeTagOpeners escapes what is left of a tag, like an unclosed <img, so it can never open one.
+// Autolinks are left alone, the unsafe ones are already gone.
+func escapeTagOpeners(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range autolink.FindAllStringIndex(text, -1) {
+		out.WriteString(tagOpener.ReplaceAllString(text[last:loc[0]], "&lt;$1"))
+		out.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(tagOpener.ReplaceAllString(text[last:], "&lt;$1"))
+
+	return out.String()
+}
+
+// IsSafeURL reports whether a link target is an absolute http, https or mailto URL. Entities, escapes and
+// whitespace are removed first, browsers ignore them when reading the scheme.
+func IsSafeURL(dest string) bool {
+	decoded := html.UnescapeString(dest)
+	decoded = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '\\' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, decoded)
+
+	scheme := urlScheme.FindStringSubmatch(strings.ToLower(decoded))
+	return scheme != nil && allowedSchemes[scheme[1]]
+}
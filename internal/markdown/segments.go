@@ -0,0 +1,139 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentText segmentKind = iota
+	segmentFence
+	segmentCodeSpan
+)
+
+// segment is a run of markdown text or code, Raw is the source and Code the literal content of code
+type segment struct {
+	Kind segmentKind
+	Raw  string
+	Code string
+}
+
+var fenceOpen = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// split cuts the source into text, fenced code blocks and inline code spans, code is never rewritten
+func split(src string) []segment {
+	var segments []segment
+	var text strings.Builder
+
+	flushText := func() {
+		if text.Len() > 0 {
+			segments = append(segments, splitCodeSpans(text.String())...)
+			text.Reset()
+		}
+	}
+
+	lines := strings.SplitAfter(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		open := fenceOpen.FindStringSubmatch(lines[i])
+		// a backtick fence cannot have backticks in its info string
+		if open == nil || (open[1][0] == '`' && strings.Contains(lines[i][len(open[0]):], "`")) {
+			text.WriteString(lines[i])
+			continue
+		}
+
+		flushText()
+
+		var raw, code strings.Builder
+		raw.WriteString(lines[i])
+		for i++; i < len(lines); i++ {
+			raw.WriteString(lines[i])
+			if isFenceClose(lines[i], open[1]) {
+				break
+			}
+			code.WriteString(lines[i])
+		}
+
+		segments = append(segments, segment{Kind: segmentFence, Raw: raw.String(), Code: code.String()})
+	}
+	flushText()
+
+	return segments
+}
+
+func isFenceClose(line, open string) bool {
+	trimmed := strings.TrimRight(line, " \t\n")
+	indent := len(trimmed) - len(strings.TrimLeft(trimmed, " "))
+	if indent > 3 {
+		return false
+	}
+
+	fence := trimmed[indent:]
+	return len(fence) >= len(open) && strings.Trim(fence, open[:1]) == ""
+}
+
+// splitCodeSpans finds inline code, a run of backticks is closed by the next run of the same length
+func splitCodeSpans(text string) []segment {
+	var segments []segment
+
+	start := 0
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+
+		n := backtickRun(text, i)
+		end := findBacktickRun(text, i+n, n)
+		if end < 0 {
+			i += n
+			continue
+		}
+
+		if i > start {
+			segments = append(segments, segment{Kind: segmentText, Raw: text[start:i]})
+		}
+
+		code := text[i+n : end]
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		segments = append(segments, segment{Kind: segmentCodeSpan, Raw: text[i : end+n], Code: code})
+
+		i = end + n
+		start = i
+	}
+
+	if start < len(text) {
+		segments = append(segments, segment{Kind: segmentText, Raw: text[start:]})
+	}
+
+	return segments
+}
+
+func backtickRun(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == '`' {
+		n++
+	}
+
+	return n
+}
+
+func findBacktickRun(text string, from, n int) int {
+	for i := from; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+
+		run := backtickRun(text, i)
+		if run == n {
+			return i
+		}
+		i += run
+	}
+
+	return -1
+}
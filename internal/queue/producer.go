@@ -3,14 +3,20 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Producer interface {
 	Enqueue(ctx context.Context, job Job) error
+	Schedule(ctx context.Context, job Job, runAt time.Time) error
 }
 
+// DelayedQueueKey holds jobs that must not run before their score (unix seconds),
+// the worker pool moves them to priority_queue once they are due
+const DelayedQueueKey = "priority_queue_delayed"
+
 type RedisProducer struct {
 	Redis *redis.Client
 }
@@ -32,3 +38,16 @@ func (p *RedisProducer) Enqueue(ctx context.Context, job Job) error {
 		Member: jobBytes,
 	}).Err()
 }
+
+// Schedule parks the job until runAt, its ExpireAt should be counted from runAt
+func (p *RedisProducer) Schedule(ctx context.Context, job Job, runAt time.Time) error {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return p.Redis.ZAdd(ctx, DelayedQueueKey, redis.Z{
+		Score:  float64(runAt.Unix()),
+		Member: jobBytes,
+	}).Err()
+}
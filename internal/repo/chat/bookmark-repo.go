@@ -0,0 +1,46 @@
+package chat_repo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"gorm.io/gorm/clause"
+)
+
+// CreateBookmark saves a bookmark, bookmarking the same message twice keeps the first one.
+// Reports whether a new bookmark was created.
+func (r *ChatRepo) CreateBookmark(ctx context.Context, bookmark *entity.MessageBookmark) (bool, *app_error.AppError) {
+	result := r.AppState.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark)
+	if result.Error != nil {
+		return false, app_error.NewAppError(http.StatusInternalServerError, "failed to save bookmark", "db-error")
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteBookmark removes a bookmark, reports whether it existed
+func (r *ChatRepo) DeleteBookmark(ctx context.Context, userID, messageID string) (bool, *app_error.AppError) {
+	result := r.AppState.DB.WithContext(ctx).Where("user_id = ? AND message_id = ?", userID, messageID).Delete(&entity.MessageBookmark{})
+	if result.Error != nil {
+		return false, app_error.NewAppError(http.StatusInternalServerError, "failed to delete bookmark", "db-error")
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// FindBookmarks pages through the bookmarks of a user newest first, beforeID is the id of the last bookmark seen
+func (r *ChatRepo) FindBookmarks(ctx context.Context, userID string, limit int, beforeID int64) ([]*entity.MessageBookmark, *app_error.AppError) {
+	query := r.AppState.DB.WithContext(ctx).Where("user_id = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var bookmarks []*entity.MessageBookmark
+	if err := query.Order("id DESC").Limit(limit).Find(&bookmarks).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch bookmarks", "db-error")
+	}
+
+	return bookmarks, nil
+}
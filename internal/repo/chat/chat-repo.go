@@ -140,26 +140,43 @@ func (r *ChatRepo) FindRoomByID(ctx context.Context, roomID string) (*entity.Roo
 	return &room, nil
 }
 
+// UpdateRoomMetadata moves the read cursor of the sender to their own message and the room up in the inbox of every
+// member. Unread counters live in Redis, see IncrementUnread.
 func (r *ChatRepo) UpdateRoomMetadata(ctx context.Context, roomID, senderID string, msgId primitive.ObjectID) error {
+	now := time.Now()
 	tx := r.AppState.DB.WithContext(ctx).Begin()
 
 	if err := tx.Model(&entity.RoomMember{}).Where("room_id = ? AND user_id = ?", roomID, senderID).Updates(map[string]any{
 		"last_read_msg_id": msgId.Hex(),
-		"last_message_at":  time.Now(),
-		"unread_count":     gorm.Expr("unread_count + ?", 1),
+		"last_read_at":     now,
 	}).Error; err != nil {
 		tx.Rollback()
 		return app_error.NewAppError(http.StatusInternalServerError, "failed to update last message metadata", "db-error")
 	}
 
-	return tx.Commit().Error
+	// the room moves up in the inbox of every member, not only the sender's
+	if err := tx.Model(&entity.RoomMember{}).Where("room_id = ? AND left_at IS NULL", roomID).Update("last_message_at", now).Error; err != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update last message metadata", "db-error")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	r.invalidateInboxes(ctx, roomID)
+
+	return nil
 }
 
 func (r *ChatRepo) GetPrivateMessages(ctx context.Context, roomID string, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError) {
-	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
-
 	// base filter: all messages in the room
-	filter := bson.M{"room_id": roomID}
+	return r.findMessagesPage(ctx, bson.M{"room_id": roomID}, limit, beforeID)
+}
+
+// findMessagesPage returns up to limit messages matching filter older than beforeID, oldest first
+func (r *ChatRepo) findMessagesPage(ctx context.Context, filter bson.M, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
 
 	// if beforeID is provided -> filter messages with ID < beforeID
 	if beforeID != nil {
@@ -169,6 +186,7 @@ func (r *ChatRepo) GetPrivateMessages(ctx context.Context, roomID string, limit
 		}
 		filter["_id"] = bson.M{"$lt": objID}
 	}
+	filter["expires_at"] = notExpired(time.Now())
 
 	cur, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit))) // sort by _id desc to get latest messages
 
@@ -192,6 +210,12 @@ func (r *ChatRepo) GetPrivateMessages(ctx context.Context, roomID string, limit
 	return messages, nil
 }
 
+// notExpired matches messages without a disappearing timer and the ones whose timer is still running,
+// expired messages stay hidden until the sweeper removes them
+func notExpired(now time.Time) bson.M {
+	return bson.M{"$not": bson.M{"$lte": now}}
+}
+
 func (r *ChatRepo) FindMessageByID(ctx context.Context, messageID string) (*entity.Message, *app_error.AppError) {
 	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
 	objID, err := primitive.ObjectIDFromHex(messageID)
@@ -199,7 +223,7 @@ func (r *ChatRepo) FindMessageByID(ctx context.Context, messageID string) (*enti
 		return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("invalid message ID: %v", err), "invalid-id")
 	}
 	var message entity.Message
-	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode((&message)); err != nil {
+	if err := collection.FindOne(ctx, bson.M{"_id": objID, "expires_at": notExpired(time.Now())}).Decode((&message)); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, app_error.NewAppError(http.StatusNotFound, "message not found or has been deleted", "not-found")
 		}
@@ -224,6 +248,9 @@ func (r *ChatRepo) FindRoomMembers(ctx context.Context, roomID string) ([]*entit
 func (r *ChatRepo) CreateMessage(ctx context.Context, msg *entity.Message) (primitive.ObjectID, *app_error.AppError) {
 	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
 	_, err := collection.InsertOne(ctx, msg)
+	if mongo.IsDuplicateKeyError(err) {
+		return primitive.NilObjectID, app_error.NewAppError(http.StatusConflict, "a message with this client_msg_id was already sent", "client_msg_id")
+	}
 	if err != nil {
 		return primitive.NilObjectID, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to create message: %v", err), "mongo")
 	}
@@ -236,13 +263,6 @@ func (r *ChatRepo) ReplyMessage(ctx context.Context, msg *entity.Message) (primi
 		return primitive.NilObjectID, err
 	}
 
-	// update is_read status of the replied message to true
-	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
-	_, updateErr := collection.UpdateOne(ctx, bson.M{"_id": msg.ReplyTo.MessageID}, bson.M{"$set": bson.M{"is_read": true}})
-	if updateErr != nil {
-		return primitive.NilObjectID, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update replied message is_read status: %v", updateErr), "mongo")
-	}
-
 	// update metadata for the room members
 	if err := r.UpdateRoomMetadata(ctx, msg.RoomID, msg.SenderID, msg.ID); err != nil {
 		return primitive.NilObjectID, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update room metadata after reply message: %v", err), "db-error")
@@ -251,49 +271,209 @@ func (r *ChatRepo) ReplyMessage(ctx context.Context, msg *entity.Message) (primi
 	return msg.ID, nil
 }
 
-func (r *ChatRepo) MarkMessageAsRead(ctx context.Context, messageID string) *app_error.AppError {
+// UpdateMessage writes an edit and appends its entry to the edit history, the history is only extended by the
+// update itself so concurrent edits cannot drop entries. Editing is refused when the message already holds
+// maxEdits entries (no cap when maxEdits <= 0) or was changed since originalTimestamp. Returns the updated message.
+func (r *ChatRepo) UpdateMessage(ctx context.Context, msg *entity.Message, messageEditEntry *entity.MessageEditEntry, originalTimestamp *time.Time, maxEdits int) (*entity.Message, *app_error.AppError) {
 	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
-	objID, err := primitive.ObjectIDFromHex(messageID)
-	if err != nil {
-		return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("invalid message ID: %v", err), "invalid-id")
+
+	filter := bson.M{
+		"_id":        msg.ID,
+		"is_deleted": bson.M{"$ne": true},
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"is_read": true}})
+	// optimistic looking - ensure message wasn't updated by someone else
+	if originalTimestamp != nil {
+		filter["updated_at"] = bson.M{"$lte": *originalTimestamp}
+	} else {
+		filter["updated_at"] = nil
+	}
+
+	// the entry at index maxEdits-1 only exists once the cap is reached
+	if maxEdits > 0 {
+		filter[fmt.Sprintf("message_edit_history.%d", maxEdits-1)] = bson.M{"$exists": false}
+	}
+
+	// pipeline update so a null history of an unedited message can be extended, values go in as literals
+	update := bson.A{bson.M{"$set": bson.M{
+		"content":    bson.M{"$literal": msg.Content},
+		"format":     bson.M{"$literal": msg.Format},
+		"plain_text": bson.M{"$literal": msg.PlainText},
+		"is_edited":  true,
+		"updated_at": bson.M{"$literal": msg.UpdatedAt},
+		"mentions":   bson.M{"$literal": msg.Mentions},
+		"message_edit_history": bson.M{"$concatArrays": bson.A{
+			bson.M{"$ifNull": bson.A{"$message_edit_history", bson.A{}}},
+			bson.A{bson.M{"$literal": messageEditEntry}},
+		}},
+	}}}
+
+	var updated entity.Message
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == nil {
+		return &updated, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to update message", "database")
+	}
+
+	// the filter did not match, tell the edit cap apart from a concurrent change
+	var current entity.Message
+	err = collection.FindOne(ctx, bson.M{"_id": msg.ID}, options.FindOne().SetProjection(bson.M{"message_edit_history": 1, "is_deleted": 1})).Decode(&current)
 	if err != nil {
-		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update replied message is_read status: %v", err), "mongo")
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "message not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to update message", "database")
+	}
+
+	if current.IsDeleted {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "Deleted message cannot be edited", "deleted")
+	}
+
+	if maxEdits > 0 && len(current.MessageEditHistory) >= maxEdits {
+		return nil, app_error.NewAppError(http.StatusForbidden, fmt.Sprintf("Message can be edited at most %d times", maxEdits), "edit_limit")
+	}
+
+	return nil, app_error.NewAppError(http.StatusConflict, "Message was modified by another operation", "concurrent_update")
+}
+
+func (r *ChatRepo) CreateGroupRoom(ctx context.Context, creatorID, name string, memberIDs []string) (*entity.Room, []*entity.RoomMember, *app_error.AppError) {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	newRoom := &entity.Room{
+		ID:        uuid.New(),
+		RT:        entity.RoomTypeGroup,
+		Name:      name,
+		CreatedBy: creatorID,
+	}
+
+	if err := tx.Create(newRoom).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, app_error.NewAppError(http.StatusInternalServerError, "failed to create group room", "db-error")
+	}
+
+	// creator always joins as owner, the rest as plain members
+	members := make([]*entity.RoomMember, 0, len(memberIDs)+1)
+	members = append(members, &entity.RoomMember{
+		RoomID: newRoom.ID.String(),
+		UserID: creatorID,
+		Role:   entity.RoomRoleOwner,
+	})
+	for _, memberID := range memberIDs {
+		members = append(members, &entity.RoomMember{
+			RoomID: newRoom.ID.String(),
+			UserID: memberID,
+			Role:   entity.RoomRoleMember,
+		})
+	}
+
+	if err := tx.Create(&members).Error; err != nil {
+		tx.Rollback()
+		if strings.Contains(err.Error(), "foreign key") {
+			return nil, nil, app_error.NewAppError(http.StatusBadRequest, "one or more members do not exist", "member_ids")
+		}
+		return nil, nil, app_error.NewAppError(http.StatusInternalServerError, "failed to add members to group room", "db-error")
+	}
+
+	for _, member := range members {
+		if err := r.recordMembershipEvent(tx, member.RoomID, member.UserID, creatorID, entity.MembershipEventJoined); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, app_error.NewAppError(http.StatusInternalServerError, "failed to commit group creation", "db-error")
+	}
+
+	return newRoom, members, nil
+}
+
+func (r *ChatRepo) UpdateRoomName(ctx context.Context, roomID, name string) *app_error.AppError {
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.Room{}).Where("id = ? AND deleted_at IS NULL", roomID).Updates(map[string]any{
+		"name":       name,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to rename room", "db-error")
+	}
+
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
 	}
 
 	return nil
 }
 
-func (r *ChatRepo) UpdateMessage(ctx context.Context, msg *entity.Message, messageEditEntry *entity.MessageEditEntry, originalTimestamp *time.Time) *app_error.AppError {
-	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+func (r *ChatRepo) SoftDeleteRoom(ctx context.Context, roomID string) *app_error.AppError {
+	now := time.Now()
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.Room{}).Where("id = ? AND deleted_at IS NULL", roomID).Updates(map[string]any{
+		"deleted_at": now,
+		"updated_at": now,
+	})
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to delete room", "db-error")
+	}
 
-	filter := bson.M{
-		"_id": msg.ID,
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
 	}
 
-	// optimistic looking - ensure message wasn't updated by someone else
-	if originalTimestamp != nil {
-		filter["updated_at"] = bson.M{"$lte": *originalTimestamp}
+	return nil
+}
+
+func (r *ChatRepo) UpdateMemberRole(ctx context.Context, roomID, userID, role string) *app_error.AppError {
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).Where("room_id = ? AND user_id = ? AND left_at IS NULL", roomID, userID).Update("role", role)
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update member role", "db-error")
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"content":              msg.Content,
-			"is_edited":            true,
-			"updated_at":           msg.UpdatedAt,
-			"message_edit_history": append(msg.MessageEditHistory, messageEditEntry),
-		},
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
 	}
 
-	result, err := collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return app_error.NewAppError(http.StatusInternalServerError, "failed to update message", "database")
+	return nil
+}
+
+func (r *ChatRepo) TransferOwnership(ctx context.Context, roomID, fromUserID, toUserID string) *app_error.AppError {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// previous owner stays in the room as admin
+	result := tx.Model(&entity.RoomMember{}).Where("room_id = ? AND user_id = ? AND role = ? AND left_at IS NULL", roomID, fromUserID, entity.RoomRoleOwner).Update("role", entity.RoomRoleAdmin)
+	if result.Error != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update previous owner", "db-error")
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusConflict, "ownership was changed by another operation", "owner")
 	}
 
-	if result.MatchedCount == 0 {
-		return app_error.NewAppError(http.StatusConflict, "Message was modified by another operation", "concurrent_update")
+	result = tx.Model(&entity.RoomMember{}).Where("room_id = ? AND user_id = ? AND left_at IS NULL", roomID, toUserID).Update("role", entity.RoomRoleOwner)
+	if result.Error != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update new owner", "db-error")
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to commit ownership transfer", "db-error")
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Lua script for an atomic claim of a client message id, a claimed key holds an empty value until the
+// response of the send is stored
+
+const claimClientMessageScript = `
+if redis.call('SET', KEYS[1], '', 'NX', 'PX', ARGV[1]) then
+	return {1, ''}
+end
+return {0, redis.call('GET', KEYS[1]) or ''}
+`
+
+var claimClientMessage = redis.NewScript(claimClientMessageScript)
+
+func createClientMessageKey(senderID, clientMsgID string) string {
+	return fmt.Sprintf("client_msg:%s:%s", senderID, clientMsgID)
+}
+
+// ClaimClientMessage reserves a client message id of the sender for ttl. When the id is already taken it returns
+// the stored response of the first send, empty while that send is still running.
+func (r *ChatRepo) ClaimClientMessage(ctx context.Context, senderID, clientMsgID string, ttl time.Duration) (string, bool, *app_error.AppError) {
+	res, err := claimClientMessage.Run(ctx, r.AppState.Redis, []string{createClientMessageKey(senderID, clientMsgID)}, ttl.Milliseconds()).Slice()
+	if err != nil || len(res) != 2 {
+		return "", false, app_error.NewAppError(http.StatusInternalServerError, "failed to claim client message id", "redis")
+	}
+
+	claimed, _ := res[0].(int64)
+	response, _ := res[1].(string)
+
+	return response, claimed == 1, nil
+}
+
+func (r *ChatRepo) StoreClientMessage(ctx context.Context, senderID, clientMsgID string, response []byte, ttl time.Duration) *app_error.AppError {
+	if err := r.AppState.Redis.Set(ctx, createClientMessageKey(senderID, clientMsgID), response, ttl).Err(); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to store client message response", "redis")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) ReleaseClientMessage(ctx context.Context, senderID, clientMsgID string) {
+	// the send failed, the client may retry with the same id
+	r.AppState.Redis.Del(ctx, createClientMessageKey(senderID, clientMsgID))
+}
+
+func (r *ChatRepo) FindMessageByClientMsgID(ctx context.Context, senderID, clientMsgID string) (*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	var message entity.Message
+	if err := collection.FindOne(ctx, bson.M{"sender_id": senderID, "client_msg_id": clientMsgID}).Decode(&message); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "message not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch message: %v", err), "mongo")
+	}
+
+	return &message, nil
+}
@@ -0,0 +1,85 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DeleteMessage hides a message for one user (scope me) or replaces it with a tombstone for
+// every member (scope everyone). Deleting for everyone keeps the original in message_deletions.
+func (r *ChatRepo) DeleteMessage(ctx context.Context, messageID primitive.ObjectID, userID, scope string, deletedAt time.Time) *app_error.AppError {
+	db := r.AppState.Mongo.Database("chat_collection")
+	collection := db.Collection("messages")
+
+	if scope == entity.DeleteScopeMe {
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": messageID}, bson.M{"$addToSet": bson.M{"deleted_for": userID}})
+		if err != nil {
+			return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to delete message: %v", err), "mongo")
+		}
+		return nil
+	}
+
+	// only the first delete for everyone wins, the returned document is the original one
+	var original entity.Message
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": messageID, "is_deleted": bson.M{"$ne": true}},
+		bson.M{
+			"$set": bson.M{
+				"content":    entity.MessageTombstone,
+				"plain_text": entity.MessageTombstone,
+				"is_deleted": true,
+				"deleted_by": userID,
+				"deleted_at": deletedAt,
+			},
+			"$unset": bson.M{
+				"attachments":          "",
+				"link_previews":        "",
+				"format":               "",
+				"message_edit_history": "",
+				"reactions":            "",
+				"type":                 "",
+				"poll":                 "",
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&original)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return app_error.NewAppError(http.StatusConflict, "message was already deleted", "deleted")
+		}
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to delete message: %v", err), "mongo")
+	}
+
+	// replies keep a snapshot of the message they quote, blank it as well
+	if _, err := collection.UpdateMany(ctx, bson.M{"reply_to.message_id": messageID}, bson.M{"$set": bson.M{"reply_to.content": entity.MessageTombstone}}); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update replies of deleted message: %v", err), "mongo")
+	}
+
+	deletion := &entity.MessageDeletion{
+		ID:               primitive.NewObjectID(),
+		MessageID:        original.ID,
+		RoomID:           original.RoomID,
+		SenderID:         original.SenderID,
+		DeletedBy:        userID,
+		OriginalContent:  original.Content,
+		Attachments:      original.Attachments,
+		EditHistory:      original.MessageEditHistory,
+		MessageCreatedAt: original.CreatedAt,
+		DeletedAt:        deletedAt,
+	}
+	if _, err := db.Collection("message_deletions").InsertOne(ctx, deletion); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to store deletion audit record: %v", err), "mongo")
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package chat_repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// UpdateRoomMessageTTL sets the disappearing timer of a room, only messages created afterwards pick it up
+func (r *ChatRepo) UpdateRoomMessageTTL(ctx context.Context, roomID string, ttlSeconds int) *app_error.AppError {
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.Room{}).Where("id = ? AND deleted_at IS NULL", roomID).Updates(map[string]any{
+		"message_ttl_seconds": ttlSeconds,
+		"updated_at":          time.Now(),
+	})
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update disappearing timer", "db-error")
+	}
+
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
+	}
+
+	return nil
+}
+
+// FindExpiredMessages returns up to limit messages whose disappearing timer ran out, the oldest first
+func (r *ChatRepo) FindExpiredMessages(ctx context.Context, now time.Time, limit int) ([]*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	cur, err := collection.Find(ctx, bson.M{"expires_at": bson.M{"$lte": now}},
+		options.Find().
+			SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+			SetLimit(int64(limit)).
+			SetProjection(bson.M{"_id": 1, "room_id": 1, "expires_at": 1, "attachments.upload_id": 1}),
+	)
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch expired messages: %v", err), "mongo")
+	}
+	defer cur.Close(ctx)
+
+	var messages []*entity.Message
+	if err := cur.All(ctx, &messages); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to decode expired messages: %v", err), "mongo")
+	}
+
+	return messages, nil
+}
+
+// DeleteExpiredMessages removes the given messages for good, the expiry is checked again so nothing else is lost.
+// Replies quoting them get their snapshot blanked like after a delete for everyone.
+func (r *ChatRepo) DeleteExpiredMessages(ctx context.Context, messageIDs []primitive.ObjectID, now time.Time) *app_error.AppError {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+	filter := bson.M{"_id": bson.M{"$in": messageIDs}, "expires_at": bson.M{"$lte": now}}
+	if _, err := collection.DeleteMany(ctx, filter); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to delete expired messages: %v", err), "mongo")
+	}
+
+	if _, err := collection.UpdateMany(ctx, bson.M{"reply_to.message_id": bson.M{"$in": messageIDs}}, bson.M{"$set": bson.M{"reply_to.content": entity.MessageTombstone}}); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update replies of expired messages: %v", err), "mongo")
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package chat_repo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+// UpdateRoomEditWindow sets how long messages of the room stay editable, nil goes back to the server default
+func (r *ChatRepo) UpdateRoomEditWindow(ctx context.Context, roomID string, windowSeconds *int) *app_error.AppError {
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.Room{}).Where("id = ? AND deleted_at IS NULL", roomID).Updates(map[string]any{
+		"edit_window_seconds": windowSeconds,
+		"updated_at":          time.Now(),
+	})
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update edit window", "db-error")
+	}
+
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
+	}
+
+	return nil
+}
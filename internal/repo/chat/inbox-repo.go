@@ -0,0 +1,129 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// InboxCacheKey holds the cached first inbox page of a user
+func InboxCacheKey(userID string) string {
+	return fmt.Sprintf("inbox:%s", userID)
+}
+
+// FindInbox pages through the rooms the user is still a member of, latest activity first. Soft deleted rooms are
+// left out, before is the last entry of the previous page.
+func (r *ChatRepo) FindInbox(ctx context.Context, userID string, limit int, before *entity.InboxCursor) ([]*entity.InboxEntry, *app_error.AppError) {
+	query := r.AppState.DB.WithContext(ctx).Table("room_members").
+		Select("room_members.*, rooms.rt AS room_type, rooms.name AS room_name, COALESCE(room_members.last_message_at, room_members.joined_at) AS last_activity_at").
+		Joins("JOIN rooms ON rooms.id = room_members.room_id").
+		Where("room_members.user_id = ? AND room_members.left_at IS NULL AND rooms.deleted_at IS NULL", userID)
+
+	if before != nil {
+		query = query.Where("(COALESCE(room_members.last_message_at, room_members.joined_at), room_members.room_id) < (?, ?::uuid)", before.LastActivityAt, before.RoomID)
+	}
+
+	var entries []*entity.InboxEntry
+	if err := query.Order("last_activity_at DESC, room_members.room_id DESC").Limit(limit).Scan(&entries).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch inbox", "db-error")
+	}
+
+	return entries, nil
+}
+
+// FindMembersOfRooms lists the members of several rooms at once, members that left included
+func (r *ChatRepo) FindMembersOfRooms(ctx context.Context, roomIDs []string) ([]*entity.RoomMember, *app_error.AppError) {
+	var members []*entity.RoomMember
+	if len(roomIDs) == 0 {
+		return members, nil
+	}
+
+	if err := r.AppState.DB.WithContext(ctx).Where("room_id IN ?", roomIDs).Find(&members).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch room members", "db-error")
+	}
+
+	return members, nil
+}
+
+// FindLastMessages returns the latest live message of every room the viewer can see, keyed by room ID.
+// Rooms without such a message are missing from the map.
+func (r *ChatRepo) FindLastMessages(ctx context.Context, roomIDs []string, viewerID string) (map[string]*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+	now := time.Now()
+
+	// one lookup per room keeps every query on room_time_idx
+	lastMessages := make(map[string]*entity.Message, len(roomIDs))
+	for _, roomID := range roomIDs {
+		filter := bson.M{
+			"room_id":     roomID,
+			"deleted_for": bson.M{"$ne": viewerID},
+			"expires_at":  notExpired(now),
+		}
+
+		var message entity.Message
+		err := collection.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})).Decode(&message)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch last message: %v", err), "mongo")
+		}
+
+		lastMessages[roomID] = &message
+	}
+
+	return lastMessages, nil
+}
+
+// UpdateInboxFlags changes the mute and archive flags of a member, nil flags are kept
+func (r *ChatRepo) UpdateInboxFlags(ctx context.Context, roomID, userID string, muted, archived *bool) *app_error.AppError {
+	updates := map[string]any{}
+	if muted != nil {
+		updates["is_muted"] = *muted
+	}
+	if archived != nil {
+		updates["is_archived"] = *archived
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).
+		Where("room_id = ? AND user_id = ? AND left_at IS NULL", roomID, userID).
+		Updates(updates)
+	if result.Error != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update inbox settings", "db-error")
+	}
+	if result.RowsAffected == 0 {
+		return app_error.NewAppError(http.StatusNotFound, "you are not a member of this room", "not-found")
+	}
+
+	r.AppState.Redis.Del(ctx, InboxCacheKey(userID))
+
+	return nil
+}
+
+// invalidateInboxes drops the cached inbox of every active member of the room
+func (r *ChatRepo) invalidateInboxes(ctx context.Context, roomID string) {
+	var userIDs []string
+	if err := r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).
+		Where("room_id = ? AND left_at IS NULL", roomID).
+		Pluck("user_id", &userIDs).Error; err != nil || len(userIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, InboxCacheKey(userID))
+	}
+
+	r.AppState.Redis.Del(ctx, keys...)
+}
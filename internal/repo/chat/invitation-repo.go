@@ -0,0 +1,260 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lua script for atomic consume of an invite link use
+
+const consumeInviteLinkScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local maxUses = tonumber(redis.call('HGET', KEYS[1], 'max_uses'))
+local uses = tonumber(redis.call('HGET', KEYS[1], 'uses') or '0')
+if uses >= maxUses then
+	return -2
+end
+return redis.call('HINCRBY', KEYS[1], 'uses', 1)
+`
+
+const releaseInviteLinkScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('HINCRBY', KEYS[1], 'uses', -1)
+end
+return 0
+`
+
+var (
+	consumeInviteLink = redis.NewScript(consumeInviteLinkScript)
+	releaseInviteLink = redis.NewScript(releaseInviteLinkScript)
+)
+
+func createInviteLinkKey(code string) string {
+	return fmt.Sprintf("invite_link:%s", code)
+}
+
+func (r *ChatRepo) CreateInvitations(ctx context.Context, roomID, inviterID string, inviteeIDs []string) ([]*entity.RoomInvitation, *app_error.AppError) {
+	invitations := make([]*entity.RoomInvitation, 0, len(inviteeIDs))
+	for _, inviteeID := range inviteeIDs {
+		invitations = append(invitations, &entity.RoomInvitation{
+			ID:        uuid.New().String(),
+			RoomID:    roomID,
+			InviterID: inviterID,
+			InviteeID: inviteeID,
+			Status:    entity.InvitationStatusPending,
+		})
+	}
+
+	// users that already have a pending invitation are skipped by the partial unique index
+	result := r.AppState.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&invitations)
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "foreign key") {
+			return nil, app_error.NewAppError(http.StatusBadRequest, "one or more invited users do not exist", "user_ids")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to create invitations", "db-error")
+	}
+
+	var created []*entity.RoomInvitation
+	if err := r.AppState.DB.WithContext(ctx).Where("room_id = ? AND invitee_id IN ? AND status = ?", roomID, inviteeIDs, entity.InvitationStatusPending).Find(&created).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch invitations", "db-error")
+	}
+
+	return created, nil
+}
+
+func (r *ChatRepo) FindPendingInvitationsByUser(ctx context.Context, userID string) ([]*entity.RoomInvitation, *app_error.AppError) {
+	var invitations []*entity.RoomInvitation
+	err := r.AppState.DB.WithContext(ctx).
+		Joins("JOIN rooms ON rooms.id = room_invitations.room_id AND rooms.deleted_at IS NULL").
+		Where("room_invitations.invitee_id = ? AND room_invitations.status = ?", userID, entity.InvitationStatusPending).
+		Order("room_invitations.created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch invitations", "db-error")
+	}
+
+	return invitations, nil
+}
+
+func (r *ChatRepo) FindInvitationByID(ctx context.Context, invitationID string) (*entity.RoomInvitation, *app_error.AppError) {
+	var invitation entity.RoomInvitation
+	if err := r.AppState.DB.WithContext(ctx).Where("id = ?", invitationID).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "invitation not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch invitation", "db-error")
+	}
+
+	return &invitation, nil
+}
+
+func (r *ChatRepo) AcceptInvitation(ctx context.Context, invitation *entity.RoomInvitation) *app_error.AppError {
+	return r.respondInvitation(ctx, invitation, entity.InvitationStatusAccepted, func(tx *gorm.DB) *app_error.AppError {
+		return r.addRoomMember(tx, invitation.RoomID, invitation.InviteeID, entity.RoomRoleMember)
+	})
+}
+
+func (r *ChatRepo) DeclineInvitation(ctx context.Context, invitation *entity.RoomInvitation) *app_error.AppError {
+	return r.respondInvitation(ctx, invitation, entity.InvitationStatusDeclined, nil)
+}
+
+func (r *ChatRepo) respondInvitation(ctx context.Context, invitation *entity.RoomInvitation, status string, then func(tx *gorm.DB) *app_error.AppError) *app_error.AppError {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// only a pending invitation can be answered, guards against double accept
+	result := tx.Model(&entity.RoomInvitation{}).Where("id = ? AND status = ?", invitation.ID, entity.InvitationStatusPending).Updates(map[string]any{
+		"status":       status,
+		"responded_at": time.Now(),
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update invitation", "db-error")
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusConflict, "invitation was already answered", "invitation")
+	}
+
+	if then != nil {
+		if err := then(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to commit invitation response", "db-error")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) AddRoomMember(ctx context.Context, roomID, userID, role string) *app_error.AppError {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := r.addRoomMember(tx, roomID, userID, role); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to commit room member", "db-error")
+	}
+
+	return nil
+}
+
+// addRoomMember inserts a new member row, or reactivates the row of a member that left before
+// so the read cursor and the audit trail of the previous membership are kept
+func (r *ChatRepo) addRoomMember(db *gorm.DB, roomID, userID, role string) *app_error.AppError {
+	var existing entity.RoomMember
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("room_id = ? AND user_id = ?", roomID, userID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to fetch room member", "db-error")
+	}
+
+	if err == nil {
+		if existing.LeftAt == nil {
+			return app_error.NewAppError(http.StatusConflict, "user is already a member of this room", "member")
+		}
+
+		if err := db.Model(&entity.RoomMember{}).Where("id = ?", existing.ID).Updates(map[string]any{
+			"role":      role,
+			"left_at":   nil,
+			"joined_at": time.Now(),
+		}).Error; err != nil {
+			return app_error.NewAppError(http.StatusInternalServerError, "failed to reactivate room member", "db-error")
+		}
+
+		return r.recordMembershipEvent(db, roomID, userID, userID, entity.MembershipEventRejoined)
+	}
+
+	member := &entity.RoomMember{
+		RoomID: roomID,
+		UserID: userID,
+		Role:   role,
+	}
+
+	if err := db.Create(member).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
+			return app_error.NewAppError(http.StatusConflict, "user is already a member of this room", "member")
+		}
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to add room member", "db-error")
+	}
+
+	return r.recordMembershipEvent(db, roomID, userID, userID, entity.MembershipEventJoined)
+}
+
+func (r *ChatRepo) CreateInviteLink(ctx context.Context, link *entity.InviteLink, ttl time.Duration) *app_error.AppError {
+	key := createInviteLinkKey(link.Code)
+
+	pipe := r.AppState.Redis.TxPipeline()
+	pipe.HSet(ctx, key, link)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to store invite link", "redis")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) FindInviteLink(ctx context.Context, code string) (*entity.InviteLink, *app_error.AppError) {
+	res := r.AppState.Redis.HGetAll(ctx, createInviteLinkKey(code))
+	if res.Err() != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch invite link", "redis")
+	}
+	if len(res.Val()) == 0 {
+		return nil, app_error.NewAppError(http.StatusNotFound, "invite link not found or expired", "not-found")
+	}
+
+	var link entity.InviteLink
+	if err := res.Scan(&link); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to decode invite link", "redis")
+	}
+
+	return &link, nil
+}
+
+func (r *ChatRepo) ConsumeInviteLink(ctx context.Context, code string) *app_error.AppError {
+	uses, err := consumeInviteLink.Run(ctx, r.AppState.Redis, []string{createInviteLinkKey(code)}).Int()
+	if err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to consume invite link", "redis")
+	}
+
+	switch uses {
+	case -1:
+		return app_error.NewAppError(http.StatusNotFound, "invite link not found or expired", "not-found")
+	case -2:
+		return app_error.NewAppError(http.StatusGone, "invite link has reached its maximum uses", "invite-link")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) ReleaseInviteLink(ctx context.Context, code string) {
+	// give back a use that was consumed but did not end in a join, skipped if the link already expired
+	releaseInviteLink.Run(ctx, r.AppState.Redis, []string{createInviteLinkKey(code)})
+}
@@ -0,0 +1,45 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SetLinkPreviews stores the previews of a message, no previews removes the field. The content the links were
+// read from is part of the filter so an edit made while fetching is not overwritten with stale previews.
+// Returns the updated message, or nil when the message changed, is gone or was deleted for everyone.
+func (r *ChatRepo) SetLinkPreviews(ctx context.Context, messageID primitive.ObjectID, content string, previews []*entity.LinkPreview) (*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	filter := bson.M{
+		"_id":        messageID,
+		"is_deleted": bson.M{"$ne": true},
+		"content":    content,
+	}
+	update := bson.M{"$unset": bson.M{"link_previews": ""}}
+	if len(previews) > 0 {
+		update = bson.M{"$set": bson.M{"link_previews": previews}}
+	}
+
+	var msg entity.Message
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update link previews: %v", err), "mongo")
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,64 @@
+package chat_repo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"gorm.io/gorm"
+)
+
+// DeactivateRoomMember sets left_at on an active member and records why, the row itself is kept
+func (r *ChatRepo) DeactivateRoomMember(ctx context.Context, roomID, userID, actorID, event string) *app_error.AppError {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result := tx.Model(&entity.RoomMember{}).Where("room_id = ? AND user_id = ? AND left_at IS NULL", roomID, userID).Update("left_at", time.Now())
+	if result.Error != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update room member", "db-error")
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
+	}
+
+	if err := r.recordMembershipEvent(tx, roomID, userID, actorID, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to commit membership change", "db-error")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) FindMembershipEvents(ctx context.Context, roomID string, limit int) ([]*entity.RoomMembershipEvent, *app_error.AppError) {
+	var events []*entity.RoomMembershipEvent
+	if err := r.AppState.DB.WithContext(ctx).Where("room_id = ?", roomID).Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch membership events", "db-error")
+	}
+
+	return events, nil
+}
+
+func (r *ChatRepo) recordMembershipEvent(db *gorm.DB, roomID, userID, actorID, event string) *app_error.AppError {
+	if err := db.Create(&entity.RoomMembershipEvent{
+		RoomID:  roomID,
+		UserID:  userID,
+		ActorID: actorID,
+		Event:   event,
+	}).Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to record membership event", "db-error")
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package chat_repo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FindActiveRoomIDsByUser lists the rooms the user is still a member of, soft deleted rooms excluded
+func (r *ChatRepo) FindActiveRoomIDsByUser(ctx context.Context, userID string) ([]string, *app_error.AppError) {
+	var roomIDs []string
+
+	err := r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).
+		Joins("JOIN rooms ON rooms.id = room_members.room_id").
+		Where("room_members.user_id = ? AND room_members.left_at IS NULL AND rooms.deleted_at IS NULL", userID).
+		Pluck("room_members.room_id", &roomIDs).Error
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch user rooms", "db-error")
+	}
+
+	return roomIDs, nil
+}
+
+// FindMentions pages through the live messages of the given rooms that mention the user, newest page first
+func (r *ChatRepo) FindMentions(ctx context.Context, userID string, roomIDs []string, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError) {
+	filter := bson.M{
+		"mentions":    userID,
+		"room_id":     bson.M{"$in": roomIDs},
+		"is_deleted":  bson.M{"$ne": true},
+		"deleted_for": bson.M{"$ne": userID},
+	}
+
+	return r.findMessagesPage(ctx, filter, limit, beforeID)
+}
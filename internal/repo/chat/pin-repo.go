@@ -0,0 +1,103 @@
+package chat_repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gorm.io/gorm/clause"
+)
+
+// PinMessage pins a message while the room stays under maxPins. The room row is locked so
+// concurrent pins cannot both pass the count check.
+func (r *ChatRepo) PinMessage(ctx context.Context, pin *entity.RoomPin, maxPins int) *app_error.AppError {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var room entity.Room
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", pin.RoomID).First(&room).Error; err != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to lock room", "db-error")
+	}
+
+	var existing int64
+	if err := tx.Model(&entity.RoomPin{}).Where("room_id = ? AND message_id = ?", pin.RoomID, pin.MessageID).Count(&existing).Error; err != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to count pins", "db-error")
+	}
+	if existing > 0 {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusConflict, "message is already pinned", "pinned")
+	}
+
+	var count int64
+	if err := tx.Model(&entity.RoomPin{}).Where("room_id = ?", pin.RoomID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to count pins", "db-error")
+	}
+	if count >= int64(maxPins) {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusConflict, fmt.Sprintf("a room can have at most %d pinned messages", maxPins), "pin-limit")
+	}
+
+	if err := tx.Create(pin).Error; err != nil {
+		tx.Rollback()
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to pin message", "db-error")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to commit pin", "db-error")
+	}
+
+	return nil
+}
+
+// UnpinMessage removes a pin, reports whether the message was pinned
+func (r *ChatRepo) UnpinMessage(ctx context.Context, roomID, messageID string) (bool, *app_error.AppError) {
+	result := r.AppState.DB.WithContext(ctx).Where("room_id = ? AND message_id = ?", roomID, messageID).Delete(&entity.RoomPin{})
+	if result.Error != nil {
+		return false, app_error.NewAppError(http.StatusInternalServerError, "failed to unpin message", "db-error")
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *ChatRepo) FindRoomPins(ctx context.Context, roomID string) ([]*entity.RoomPin, *app_error.AppError) {
+	var pins []*entity.RoomPin
+	if err := r.AppState.DB.WithContext(ctx).Where("room_id = ?", roomID).Order("pinned_at DESC, id DESC").Find(&pins).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch pins", "db-error")
+	}
+
+	return pins, nil
+}
+
+// FindMessagesByIDs loads messages in one query, missing IDs are skipped and the order is not kept
+func (r *ChatRepo) FindMessagesByIDs(ctx context.Context, messageIDs []primitive.ObjectID) ([]*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	messages := make([]*entity.Message, 0, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return messages, nil
+	}
+
+	cur, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": messageIDs}, "expires_at": notExpired(time.Now())})
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch messages: %v", err), "mongo")
+	}
+	defer cur.Close(ctx)
+
+	if err := cur.All(ctx, &messages); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to decode messages: %v", err), "mongo")
+	}
+
+	return messages, nil
+}
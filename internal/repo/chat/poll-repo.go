@@ -0,0 +1,73 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SetPollVote replaces the vote of a user on a poll that is still open at now, a vote without options withdraws
+// it. The votes are rewritten in one update so concurrent votes of other users are kept. Returns the updated
+// message, or nil when the poll closed, is gone or was deleted for everyone.
+func (r *ChatRepo) SetPollVote(ctx context.Context, messageID primitive.ObjectID, vote *entity.PollVote, now time.Time) (*entity.Message, *app_error.AppError) {
+	filter := bson.M{
+		"_id":            messageID,
+		"type":           entity.MessageTypePoll,
+		"is_deleted":     bson.M{"$ne": true},
+		"poll.closed_at": nil,
+		"$or": bson.A{
+			bson.M{"poll.closes_at": nil},
+			bson.M{"poll.closes_at": bson.M{"$gt": now}},
+		},
+	}
+
+	votes := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$poll.votes", bson.A{}}},
+		"cond":  bson.M{"$ne": bson.A{"$$this.user_id", vote.UserID}},
+	}}
+	if len(vote.OptionIDs) > 0 {
+		votes = bson.M{"$concatArrays": bson.A{votes, bson.A{bson.M{"$literal": vote}}}}
+	}
+	update := bson.A{bson.M{"$set": bson.M{"poll.votes": votes}}}
+
+	return r.updatePoll(ctx, filter, update)
+}
+
+// ClosePoll stamps the closing time of a poll, returns nil when it was already closed, is gone or was deleted
+func (r *ChatRepo) ClosePoll(ctx context.Context, messageID primitive.ObjectID, closedAt time.Time) (*entity.Message, *app_error.AppError) {
+	filter := bson.M{
+		"_id":            messageID,
+		"type":           entity.MessageTypePoll,
+		"is_deleted":     bson.M{"$ne": true},
+		"poll.closed_at": nil,
+	}
+	update := bson.M{"$set": bson.M{"poll.closed_at": closedAt}}
+
+	return r.updatePoll(ctx, filter, update)
+}
+
+func (r *ChatRepo) updatePoll(ctx context.Context, filter bson.M, update any) (*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	var msg entity.Message
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update poll: %v", err), "mongo")
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,71 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AddReaction stores the emoji of a user on a message, a repeated reaction is a no-op.
+// Returns the reactions after the call and whether anything changed.
+func (r *ChatRepo) AddReaction(ctx context.Context, messageID primitive.ObjectID, reaction *entity.Reaction) ([]*entity.Reaction, bool, *app_error.AppError) {
+	filter := bson.M{
+		"_id":        messageID,
+		"is_deleted": bson.M{"$ne": true},
+		"reactions": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"user_id": reaction.UserID,
+			"emoji":   reaction.Emoji,
+		}}},
+	}
+	update := bson.M{"$push": bson.M{"reactions": reaction}}
+
+	return r.updateReactions(ctx, messageID, filter, update)
+}
+
+// RemoveReaction drops the emoji of a user from a message, removing a missing reaction is a no-op
+func (r *ChatRepo) RemoveReaction(ctx context.Context, messageID primitive.ObjectID, userID, emoji string) ([]*entity.Reaction, bool, *app_error.AppError) {
+	filter := bson.M{
+		"_id":        messageID,
+		"is_deleted": bson.M{"$ne": true},
+		"reactions":  bson.M{"$elemMatch": bson.M{"user_id": userID, "emoji": emoji}},
+	}
+	update := bson.M{"$pull": bson.M{"reactions": bson.M{"user_id": userID, "emoji": emoji}}}
+
+	return r.updateReactions(ctx, messageID, filter, update)
+}
+
+func (r *ChatRepo) updateReactions(ctx context.Context, messageID primitive.ObjectID, filter, update bson.M) ([]*entity.Reaction, bool, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	var msg entity.Message
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"reactions": 1}),
+	).Decode(&msg)
+	if err == nil {
+		return msg.Reactions, true, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update reactions: %v", err), "mongo")
+	}
+
+	// the filter did not match, the reaction was already in the requested state
+	err = collection.FindOne(ctx, bson.M{"_id": messageID}, options.FindOne().SetProjection(bson.M{"reactions": 1})).Decode(&msg)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, false, app_error.NewAppError(http.StatusNotFound, "message not found", "not-found")
+		}
+		return nil, false, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to find message: %v", err), "mongo")
+	}
+
+	return msg.Reactions, false, nil
+}
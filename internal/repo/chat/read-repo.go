@@ -0,0 +1,29 @@
+package chat_repo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdvanceReadCursor moves the read cursor of a member forward to msgID, it never moves it back.
+// ObjectID hex strings have a fixed length so comparing them as text follows their creation order.
+// Reports whether the cursor actually moved.
+func (r *ChatRepo) AdvanceReadCursor(ctx context.Context, roomID, userID string, msgID primitive.ObjectID, readAt time.Time) (bool, *app_error.AppError) {
+	result := r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).
+		Where("room_id = ? AND user_id = ? AND left_at IS NULL", roomID, userID).
+		Where("last_read_msg_id IS NULL OR last_read_msg_id = '' OR last_read_msg_id < ?", msgID.Hex()).
+		Updates(map[string]any{
+			"last_read_msg_id": msgID.Hex(),
+			"last_read_at":     readAt,
+		})
+	if result.Error != nil {
+		return false, app_error.NewAppError(http.StatusInternalServerError, "failed to update read cursor", "db-error")
+	}
+
+	return result.RowsAffected > 0, nil
+}
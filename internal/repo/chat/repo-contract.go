@@ -18,6 +18,76 @@ type ChatRepoContract interface {
 	UpdateRoomMetadata(ctx context.Context, roomID, senderID string, msgId primitive.ObjectID) error
 	GetPrivateMessages(ctx context.Context, roomID string, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError)
 	FindMessageByID(ctx context.Context, messageID string) (*entity.Message, *app_error.AppError)
-	MarkMessageAsRead(ctx context.Context, messageID string) *app_error.AppError
-	UpdateMessage(ctx context.Context, msg *entity.Message, messageEditEntry *entity.MessageEditEntry, originalTimestamp *time.Time) *app_error.AppError
+	AdvanceReadCursor(ctx context.Context, roomID, userID string, msgID primitive.ObjectID, readAt time.Time) (bool, *app_error.AppError)
+	IncrementUnread(ctx context.Context, roomID, senderID string, by int64) (map[string]int64, *app_error.AppError)
+	SetUnread(ctx context.Context, roomID, userID string, count int64) *app_error.AppError
+	FindUnreadCounts(ctx context.Context, userID string, roomIDs []string) (map[string]int64, *app_error.AppError)
+	CountUnreadMessages(ctx context.Context, roomID, userID string, after primitive.ObjectID) (int64, *app_error.AppError)
+	ReconcileUnreadCounts(ctx context.Context, limit int64) (int, *app_error.AppError)
+	UpdateMessage(ctx context.Context, msg *entity.Message, messageEditEntry *entity.MessageEditEntry, originalTimestamp *time.Time, maxEdits int) (*entity.Message, *app_error.AppError)
+	DeleteMessage(ctx context.Context, messageID primitive.ObjectID, userID, scope string, deletedAt time.Time) *app_error.AppError
+	AddReaction(ctx context.Context, messageID primitive.ObjectID, reaction *entity.Reaction) ([]*entity.Reaction, bool, *app_error.AppError)
+	RemoveReaction(ctx context.Context, messageID primitive.ObjectID, userID, emoji string) ([]*entity.Reaction, bool, *app_error.AppError)
+	GetThreadReplies(ctx context.Context, rootID primitive.ObjectID, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError)
+	BumpThread(ctx context.Context, rootID primitive.ObjectID, replierID string, repliedAt time.Time) (*entity.Message, *app_error.AppError)
+	FindActiveRoomIDsByUser(ctx context.Context, userID string) ([]string, *app_error.AppError)
+	FindInbox(ctx context.Context, userID string, limit int, before *entity.InboxCursor) ([]*entity.InboxEntry, *app_error.AppError)
+	FindMembersOfRooms(ctx context.Context, roomIDs []string) ([]*entity.RoomMember, *app_error.AppError)
+	FindLastMessages(ctx context.Context, roomIDs []string, viewerID string) (map[string]*entity.Message, *app_error.AppError)
+	UpdateInboxFlags(ctx context.Context, roomID, userID string, muted, archived *bool) *app_error.AppError
+	FindMentions(ctx context.Context, userID string, roomIDs []string, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError)
+	SearchMessages(ctx context.Context, filter entity.MessageSearchFilter, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError)
+	PinMessage(ctx context.Context, pin *entity.RoomPin, maxPins int) *app_error.AppError
+	UnpinMessage(ctx context.Context, roomID, messageID string) (bool, *app_error.AppError)
+	FindRoomPins(ctx context.Context, roomID string) ([]*entity.RoomPin, *app_error.AppError)
+	FindMessagesByIDs(ctx context.Context, messageIDs []primitive.ObjectID) ([]*entity.Message, *app_error.AppError)
+	CreateBookmark(ctx context.Context, bookmark *entity.MessageBookmark) (bool, *app_error.AppError)
+	DeleteBookmark(ctx context.Context, userID, messageID string) (bool, *app_error.AppError)
+	FindBookmarks(ctx context.Context, userID string, limit int, beforeID int64) ([]*entity.MessageBookmark, *app_error.AppError)
+	CreateScheduledMessage(ctx context.Context, msg *entity.ScheduledMessage) *app_error.AppError
+	FindScheduledMessages(ctx context.Context, senderID string) ([]*entity.ScheduledMessage, *app_error.AppError)
+	CountScheduledMessages(ctx context.Context, senderID string) (int64, *app_error.AppError)
+	FindScheduledMessageByID(ctx context.Context, scheduledID string) (*entity.ScheduledMessage, *app_error.AppError)
+	RescheduleMessage(ctx context.Context, id primitive.ObjectID, version int, content string, sendAt, updatedAt time.Time) (*entity.ScheduledMessage, *app_error.AppError)
+	CancelScheduledMessage(ctx context.Context, id primitive.ObjectID, cancelledAt time.Time) (*entity.ScheduledMessage, *app_error.AppError)
+	ClaimScheduledMessage(ctx context.Context, id primitive.ObjectID, version int) (*entity.ScheduledMessage, *app_error.AppError)
+	FinishScheduledMessage(ctx context.Context, id primitive.ObjectID, status string, messageID *primitive.ObjectID, failure string, finishedAt time.Time) *app_error.AppError
+	FindOverdueScheduledMessages(ctx context.Context, before time.Time, limit int) ([]*entity.ScheduledMessage, *app_error.AppError)
+	ReleaseScheduledMessage(ctx context.Context, id primitive.ObjectID, claimedAt *time.Time, releasedAt time.Time) (*entity.ScheduledMessage, *app_error.AppError)
+	CreateUpload(ctx context.Context, upload *entity.FileUpload) *app_error.AppError
+	FindUploadByID(ctx context.Context, uploadID string) (*entity.FileUpload, *app_error.AppError)
+	AttachUploads(ctx context.Context, uploaderID string, uploadIDs []string, roomID, messageID string) ([]*entity.FileUpload, *app_error.AppError)
+	DetachUploads(ctx context.Context, messageID string) *app_error.AppError
+	UpdateUploadMetadata(ctx context.Context, upload *entity.FileUpload) *app_error.AppError
+	FindReferencedUploadIDs(ctx context.Context, uploadIDs []string) (map[string]bool, *app_error.AppError)
+	DeleteUploads(ctx context.Context, uploadIDs []string) ([]*entity.FileUpload, *app_error.AppError)
+	UpdateAttachmentMetadata(ctx context.Context, messageID primitive.ObjectID, attachment *entity.Attachment) (*entity.Message, *app_error.AppError)
+	SetLinkPreviews(ctx context.Context, messageID primitive.ObjectID, content string, previews []*entity.LinkPreview) (*entity.Message, *app_error.AppError)
+	SetPollVote(ctx context.Context, messageID primitive.ObjectID, vote *entity.PollVote, now time.Time) (*entity.Message, *app_error.AppError)
+	ClosePoll(ctx context.Context, messageID primitive.ObjectID, closedAt time.Time) (*entity.Message, *app_error.AppError)
+	UpdateRoomMessageTTL(ctx context.Context, roomID string, ttlSeconds int) *app_error.AppError
+	UpdateRoomEditWindow(ctx context.Context, roomID string, windowSeconds *int) *app_error.AppError
+	FindExpiredMessages(ctx context.Context, now time.Time, limit int) ([]*entity.Message, *app_error.AppError)
+	DeleteExpiredMessages(ctx context.Context, messageIDs []primitive.ObjectID, now time.Time) *app_error.AppError
+	CreateGroupRoom(ctx context.Context, creatorID, name string, memberIDs []string) (*entity.Room, []*entity.RoomMember, *app_error.AppError)
+	UpdateRoomName(ctx context.Context, roomID, name string) *app_error.AppError
+	SoftDeleteRoom(ctx context.Context, roomID string) *app_error.AppError
+	UpdateMemberRole(ctx context.Context, roomID, userID, role string) *app_error.AppError
+	TransferOwnership(ctx context.Context, roomID, fromUserID, toUserID string) *app_error.AppError
+	CreateInvitations(ctx context.Context, roomID, inviterID string, inviteeIDs []string) ([]*entity.RoomInvitation, *app_error.AppError)
+	FindPendingInvitationsByUser(ctx context.Context, userID string) ([]*entity.RoomInvitation, *app_error.AppError)
+	FindInvitationByID(ctx context.Context, invitationID string) (*entity.RoomInvitation, *app_error.AppError)
+	AcceptInvitation(ctx context.Context, invitation *entity.RoomInvitation) *app_error.AppError
+	DeclineInvitation(ctx context.Context, invitation *entity.RoomInvitation) *app_error.AppError
+	AddRoomMember(ctx context.Context, roomID, userID, role string) *app_error.AppError
+	DeactivateRoomMember(ctx context.Context, roomID, userID, actorID, event string) *app_error.AppError
+	FindMembershipEvents(ctx context.Context, roomID string, limit int) ([]*entity.RoomMembershipEvent, *app_error.AppError)
+	CreateInviteLink(ctx context.Context, link *entity.InviteLink, ttl time.Duration) *app_error.AppError
+	FindInviteLink(ctx context.Context, code string) (*entity.InviteLink, *app_error.AppError)
+	ConsumeInviteLink(ctx context.Context, code string) *app_error.AppError
+	ReleaseInviteLink(ctx context.Context, code string)
+	ClaimClientMessage(ctx context.Context, senderID, clientMsgID string, ttl time.Duration) (string, bool, *app_error.AppError)
+	StoreClientMessage(ctx context.Context, senderID, clientMsgID string, response []byte, ttl time.Duration) *app_error.AppError
+	ReleaseClientMessage(ctx context.Context, senderID, clientMsgID string)
+	FindMessageByClientMsgID(ctx context.Context, senderID, clientMsgID string) (*entity.Message, *app_error.AppError)
 }
@@ -0,0 +1,189 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func (r *ChatRepo) CreateScheduledMessage(ctx context.Context, msg *entity.ScheduledMessage) *app_error.AppError {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+
+	if _, err := collection.InsertOne(ctx, msg); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to insert scheduled message: %v", err), "mongo")
+	}
+
+	return nil
+}
+
+// FindScheduledMessages returns the messages of a sender that are not sent yet, the next to fire first
+func (r *ChatRepo) FindScheduledMessages(ctx context.Context, senderID string) ([]*entity.ScheduledMessage, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+
+	filter := bson.M{
+		"sender_id": senderID,
+		"status":    bson.M{"$in": bson.A{entity.ScheduledStatusPending, entity.ScheduledStatusSending}},
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "send_at", Value: 1}}))
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch scheduled messages: %v", err), "mongo")
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*entity.ScheduledMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to decode scheduled messages: %v", err), "mongo")
+	}
+
+	return messages, nil
+}
+
+func (r *ChatRepo) CountScheduledMessages(ctx context.Context, senderID string) (int64, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+
+	count, err := collection.CountDocuments(ctx, bson.M{"sender_id": senderID, "status": entity.ScheduledStatusPending})
+	if err != nil {
+		return 0, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to count scheduled messages: %v", err), "mongo")
+	}
+
+	return count, nil
+}
+
+func (r *ChatRepo) FindScheduledMessageByID(ctx context.Context, scheduledID string) (*entity.ScheduledMessage, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+	objID, err := primitive.ObjectIDFromHex(scheduledID)
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("invalid scheduled message ID: %v", err), "invalid-id")
+	}
+
+	var msg entity.ScheduledMessage
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&msg); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "scheduled message not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch scheduled message: %v", err), "mongo")
+	}
+
+	return &msg, nil
+}
+
+// RescheduleMessage changes a pending message and bumps its version, so a job queued for the old version is dropped.
+// Returns nil when the message is no longer pending or was changed concurrently.
+func (r *ChatRepo) RescheduleMessage(ctx context.Context, id primitive.ObjectID, version int, content string, sendAt, updatedAt time.Time) (*entity.ScheduledMessage, *app_error.AppError) {
+	filter := bson.M{"_id": id, "status": entity.ScheduledStatusPending, "version": version}
+	update := bson.M{
+		"$set": bson.M{"content": content, "send_at": sendAt, "updated_at": updatedAt},
+		"$inc": bson.M{"version": 1},
+	}
+
+	return r.updateScheduledMessage(ctx, filter, update)
+}
+
+// CancelScheduledMessage only cancels a pending message, one already claimed by the worker can't be stopped.
+// Returns nil when the message is no longer pending.
+func (r *ChatRepo) CancelScheduledMessage(ctx context.Context, id primitive.ObjectID, cancelledAt time.Time) (*entity.ScheduledMessage, *app_error.AppError) {
+	filter := bson.M{"_id": id, "status": entity.ScheduledStatusPending}
+	update := bson.M{"$set": bson.M{"status": entity.ScheduledStatusCancelled, "updated_at": cancelledAt}}
+
+	return r.updateScheduledMessage(ctx, filter, update)
+}
+
+// ClaimScheduledMessage moves a pending message to sending when the version still matches.
+// Cancel and reschedule use the same status filter, so at most one of them wins.
+// Returns nil when the message must not be sent by this job.
+func (r *ChatRepo) ClaimScheduledMessage(ctx context.Context, id primitive.ObjectID, version int) (*entity.ScheduledMessage, *app_error.AppError) {
+	filter := bson.M{"_id": id, "status": entity.ScheduledStatusPending, "version": version}
+	update := bson.M{"$set": bson.M{"status": entity.ScheduledStatusSending, "claimed_at": time.Now()}}
+
+	return r.updateScheduledMessage(ctx, filter, update)
+}
+
+// FinishScheduledMessage records the outcome of a claimed message, ScheduledStatusPending hands it back for a retry
+func (r *ChatRepo) FinishScheduledMessage(ctx context.Context, id primitive.ObjectID, status string, messageID *primitive.ObjectID, failure string, finishedAt time.Time) *app_error.AppError {
+	set := bson.M{"status": status, "updated_at": finishedAt}
+	if messageID != nil {
+		set["message_id"] = *messageID
+		set["sent_at"] = finishedAt
+	}
+	if failure != "" {
+		set["failure"] = failure
+	}
+
+	msg, err := r.updateScheduledMessage(ctx, bson.M{"_id": id, "status": entity.ScheduledStatusSending}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return app_error.NewAppError(http.StatusConflict, "scheduled message is not being sent", "conflict")
+	}
+
+	return nil
+}
+
+// FindOverdueScheduledMessages returns up to limit messages the queue lost track of: pending messages that were
+// due before the cutoff and messages claimed before the cutoff that never finished sending
+func (r *ChatRepo) FindOverdueScheduledMessages(ctx context.Context, before time.Time, limit int) ([]*entity.ScheduledMessage, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": entity.ScheduledStatusPending, "send_at": bson.M{"$lt": before}},
+		bson.M{"status": entity.ScheduledStatusSending, "claimed_at": bson.M{"$lt": before}},
+		// claimed before claimed_at was recorded
+		bson.M{"status": entity.ScheduledStatusSending, "claimed_at": bson.M{"$exists": false}},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "send_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch overdue scheduled messages: %v", err), "mongo")
+	}
+	defer cursor.Close(ctx)
+
+	var msgs []*entity.ScheduledMessage
+	if err := cursor.All(ctx, &msgs); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to decode overdue scheduled messages: %v", err), "mongo")
+	}
+
+	return msgs, nil
+}
+
+// ReleaseScheduledMessage hands a message still claimed by the given claim back to pending, so a new delivery can
+// claim it. Returns nil when the claim finished or changed in the meantime.
+func (r *ChatRepo) ReleaseScheduledMessage(ctx context.Context, id primitive.ObjectID, claimedAt *time.Time, releasedAt time.Time) (*entity.ScheduledMessage, *app_error.AppError) {
+	filter := bson.M{"_id": id, "status": entity.ScheduledStatusSending, "claimed_at": bson.M{"$exists": false}}
+	if claimedAt != nil {
+		filter["claimed_at"] = *claimedAt
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": entity.ScheduledStatusPending, "updated_at": releasedAt},
+		"$unset": bson.M{"claimed_at": ""},
+	}
+
+	return r.updateScheduledMessage(ctx, filter, update)
+}
+
+func (r *ChatRepo) updateScheduledMessage(ctx context.Context, filter, update bson.M) (*entity.ScheduledMessage, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("scheduled_messages")
+
+	var msg entity.ScheduledMessage
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&msg)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update scheduled message: %v", err), "mongo")
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,36 @@
+package chat_repo
+
+import (
+	"context"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SearchMessages runs the text index over the live messages of the filter rooms, paged with the before_id cursor
+func (r *ChatRepo) SearchMessages(ctx context.Context, filter entity.MessageSearchFilter, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError) {
+	query := bson.M{
+		"$text":       bson.M{"$search": filter.Query},
+		"room_id":     bson.M{"$in": filter.RoomIDs},
+		"is_deleted":  bson.M{"$ne": true},
+		"deleted_for": bson.M{"$ne": filter.ViewerID},
+	}
+
+	if filter.SenderID != nil {
+		query["sender_id"] = *filter.SenderID
+	}
+
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lte"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+
+	return r.findMessagesPage(ctx, query, limit, beforeID)
+}
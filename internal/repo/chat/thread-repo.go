@@ -0,0 +1,52 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetThreadReplies pages through the replies of a thread with the same before_id cursor as GetPrivateMessages
+func (r *ChatRepo) GetThreadReplies(ctx context.Context, rootID primitive.ObjectID, limit int, beforeID *string) ([]*entity.Message, *app_error.AppError) {
+	return r.findMessagesPage(ctx, bson.M{"thread_root_id": rootID}, limit, beforeID)
+}
+
+// BumpThread counts a new reply on the thread root and adds the root sender and the replier
+// to the participants, the update runs as a pipeline so concurrent replies never lose a count.
+// Returns the root after the update.
+func (r *ChatRepo) BumpThread(ctx context.Context, rootID primitive.ObjectID, replierID string, repliedAt time.Time) (*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	pipeline := []bson.M{
+		{"$set": bson.M{
+			"reply_count":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$reply_count", 0}}, 1}},
+			"last_reply_at": bson.M{"$max": bson.A{"$last_reply_at", repliedAt}},
+			"thread_participants": bson.M{"$setUnion": bson.A{
+				bson.M{"$ifNull": bson.A{"$thread_participants", bson.A{}}},
+				bson.A{"$sender_id", replierID},
+			}},
+		}},
+	}
+
+	var root entity.Message
+	err := collection.FindOneAndUpdate(ctx, bson.M{"_id": rootID}, pipeline,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&root)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "thread root message not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update thread: %v", err), "mongo")
+	}
+
+	return &root, nil
+}
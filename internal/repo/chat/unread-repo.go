@@ -0,0 +1,165 @@
+package chat_repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// unreadDirtyKey lists the "<room id>:<user id>" counters that changed since they were last written to room_members
+const unreadDirtyKey = "unread:dirty"
+
+// unreadKey holds the unread counters of a user, one hash field per room
+func unreadKey(userID string) string {
+	return fmt.Sprintf("unread:%s", userID)
+}
+
+// IncrementUnread adds by to the unread counter of every active member of the room except the sender, whose
+// counter goes back to 0 since their own message moved their read cursor. A counter missing from Redis starts
+// from the value last written to room_members. The cached inboxes of the members are dropped. Returns the new
+// counters of the other members keyed by user ID.
+func (r *ChatRepo) IncrementUnread(ctx context.Context, roomID, senderID string, by int64) (map[string]int64, *app_error.AppError) {
+	var members []*entity.RoomMember
+	if err := r.AppState.DB.WithContext(ctx).Select("user_id", "unread_count").
+		Where("room_id = ? AND left_at IS NULL", roomID).
+		Find(&members).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch room members", "db-error")
+	}
+
+	pipe := r.AppState.Redis.TxPipeline()
+	counters := make(map[string]*redis.IntCmd, len(members))
+	for _, member := range members {
+		key := unreadKey(member.UserID)
+		if member.UserID == senderID {
+			pipe.HSet(ctx, key, roomID, 0)
+		} else {
+			pipe.HSetNX(ctx, key, roomID, member.UnreadCount)
+			counters[member.UserID] = pipe.HIncrBy(ctx, key, roomID, by)
+		}
+		pipe.SAdd(ctx, unreadDirtyKey, unreadMember(roomID, member.UserID))
+		pipe.Del(ctx, InboxCacheKey(member.UserID))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update unread counters: %v", err), "redis")
+	}
+
+	counts := make(map[string]int64, len(counters))
+	for userID, cmd := range counters {
+		counts[userID] = cmd.Val()
+	}
+
+	return counts, nil
+}
+
+// SetUnread overwrites the unread counter of a member, used when their read cursor moved
+func (r *ChatRepo) SetUnread(ctx context.Context, roomID, userID string, count int64) *app_error.AppError {
+	pipe := r.AppState.Redis.TxPipeline()
+	pipe.HSet(ctx, unreadKey(userID), roomID, count)
+	pipe.SAdd(ctx, unreadDirtyKey, unreadMember(roomID, userID))
+	pipe.Del(ctx, InboxCacheKey(userID))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update unread counter: %v", err), "redis")
+	}
+
+	return nil
+}
+
+// FindUnreadCounts returns the unread counters Redis holds for the user, keyed by room ID. Rooms without a
+// counter are missing from the map, room_members has their count.
+func (r *ChatRepo) FindUnreadCounts(ctx context.Context, userID string, roomIDs []string) (map[string]int64, *app_error.AppError) {
+	counts := make(map[string]int64, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	values, err := r.AppState.Redis.HMGet(ctx, unreadKey(userID), roomIDs...).Result()
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch unread counters: %v", err), "redis")
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if count, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			counts[roomIDs[i]] = count
+		}
+	}
+
+	return counts, nil
+}
+
+// CountUnreadMessages counts the live messages of other members the user can see after the given message,
+// a zero after counts the whole room
+func (r *ChatRepo) CountUnreadMessages(ctx context.Context, roomID, userID string, after primitive.ObjectID) (int64, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	filter := bson.M{
+		"room_id":     roomID,
+		"sender_id":   bson.M{"$ne": userID},
+		"is_deleted":  bson.M{"$ne": true},
+		"deleted_for": bson.M{"$ne": userID},
+		"expires_at":  notExpired(time.Now()),
+	}
+	if !after.IsZero() {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to count unread messages: %v", err), "mongo")
+	}
+
+	return count, nil
+}
+
+// ReconcileUnreadCounts writes up to limit changed counters back to room_members. Counters that fail to be
+// written are marked as changed again for the next run. Returns how many counters were written.
+func (r *ChatRepo) ReconcileUnreadCounts(ctx context.Context, limit int64) (int, *app_error.AppError) {
+	dirty, err := r.AppState.Redis.SPopN(ctx, unreadDirtyKey, limit).Result()
+	if err != nil {
+		return 0, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch changed unread counters: %v", err), "redis")
+	}
+
+	written := 0
+	for i, member := range dirty {
+		roomID, userID, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+
+		count, err := r.AppState.Redis.HGet(ctx, unreadKey(userID), roomID).Int64()
+		if err == redis.Nil {
+			continue
+		}
+		if err == nil {
+			err = r.AppState.DB.WithContext(ctx).Model(&entity.RoomMember{}).
+				Where("room_id = ? AND user_id = ?", roomID, userID).
+				Update("unread_count", count).Error
+		}
+		if err != nil {
+			r.AppState.Redis.SAdd(ctx, unreadDirtyKey, dirty[i:])
+			return written, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to write unread counter: %v", err), "db-error")
+		}
+
+		written++
+	}
+
+	return written, nil
+}
+
+func unreadMember(roomID, userID string) string {
+	return roomID + ":" + userID
+}
@@ -0,0 +1,171 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *ChatRepo) CreateUpload(ctx context.Context, upload *entity.FileUpload) *app_error.AppError {
+	if err := r.AppState.DB.WithContext(ctx).Create(upload).Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to save upload", "db-error")
+	}
+
+	return nil
+}
+
+func (r *ChatRepo) FindUploadByID(ctx context.Context, uploadID string) (*entity.FileUpload, *app_error.AppError) {
+	var upload entity.FileUpload
+	if err := r.AppState.DB.WithContext(ctx).Where("id = ?", uploadID).First(&upload).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, app_error.NewAppError(http.StatusNotFound, "file not found", "not-found")
+		}
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch upload", "db-error")
+	}
+
+	return &upload, nil
+}
+
+// AttachUploads binds unattached uploads of the uploader to a message, either all of them are attached
+// or none. The uploads are returned in the order of uploadIDs.
+func (r *ChatRepo) AttachUploads(ctx context.Context, uploaderID string, uploadIDs []string, roomID, messageID string) ([]*entity.FileUpload, *app_error.AppError) {
+	tx := r.AppState.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var uploads []*entity.FileUpload
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ? AND uploader_id = ? AND message_id IS NULL", uploadIDs, uploaderID).Find(&uploads).Error; err != nil {
+		tx.Rollback()
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to fetch uploads", "db-error")
+	}
+
+	byID := make(map[string]*entity.FileUpload, len(uploads))
+	for _, upload := range uploads {
+		byID[upload.ID] = upload
+	}
+
+	ordered := make([]*entity.FileUpload, 0, len(uploadIDs))
+	for _, uploadID := range uploadIDs {
+		upload, ok := byID[uploadID]
+		if !ok {
+			tx.Rollback()
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("attachment %s does not exist or is already attached", uploadID), "attachment_ids")
+		}
+		upload.RoomID = &roomID
+		upload.MessageID = &messageID
+		ordered = append(ordered, upload)
+	}
+
+	if err := tx.Model(&entity.FileUpload{}).Where("id IN ?", uploadIDs).Updates(map[string]any{"room_id": roomID, "message_id": messageID}).Error; err != nil {
+		tx.Rollback()
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to attach uploads", "db-error")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to commit attachments", "db-error")
+	}
+
+	return ordered, nil
+}
+
+// DetachUploads frees the uploads of a message that could not be stored, so they can be attached again
+func (r *ChatRepo) DetachUploads(ctx context.Context, messageID string) *app_error.AppError {
+	if err := r.AppState.DB.WithContext(ctx).Model(&entity.FileUpload{}).Where("message_id = ?", messageID).Updates(map[string]any{"room_id": nil, "message_id": nil}).Error; err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to detach uploads", "db-error")
+	}
+
+	return nil
+}
+
+// FindReferencedUploadIDs returns which of the given uploads are still attached to a stored message,
+// forwarded copies share the uploads of the original
+func (r *ChatRepo) FindReferencedUploadIDs(ctx context.Context, uploadIDs []string) (map[string]bool, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	var found []string
+	if err := collection.Distinct(ctx, "attachments.upload_id", bson.M{"attachments.upload_id": bson.M{"$in": uploadIDs}}).Decode(&found); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to fetch attached uploads: %v", err), "mongo")
+	}
+
+	referenced := make(map[string]bool, len(found))
+	for _, id := range found {
+		referenced[id] = true
+	}
+
+	return referenced, nil
+}
+
+// DeleteUploads removes the given uploads and returns them, the caller deletes their files
+func (r *ChatRepo) DeleteUploads(ctx context.Context, uploadIDs []string) ([]*entity.FileUpload, *app_error.AppError) {
+	var uploads []*entity.FileUpload
+	if err := r.AppState.DB.WithContext(ctx).Clauses(clause.Returning{}).Where("id IN ?", uploadIDs).Delete(&uploads).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to delete uploads", "db-error")
+	}
+
+	return uploads, nil
+}
+
+// UpdateUploadMetadata stores what the process_attachments job found out about a file
+func (r *ChatRepo) UpdateUploadMetadata(ctx context.Context, upload *entity.FileUpload) *app_error.AppError {
+	err := r.AppState.DB.WithContext(ctx).Model(&entity.FileUpload{}).Where("id = ?", upload.ID).Updates(map[string]any{
+		"mime_type":           upload.MimeType,
+		"size":                upload.Size,
+		"width":               gorm.Expr("NULLIF(?, 0)", upload.Width),
+		"height":              gorm.Expr("NULLIF(?, 0)", upload.Height),
+		"thumbnail_key":       gorm.Expr("NULLIF(?, '')", upload.ThumbnailKey),
+		"thumbnail_mime_type": gorm.Expr("NULLIF(?, '')", upload.ThumbnailMimeType),
+		"processed_at":        upload.ProcessedAt,
+	}).Error
+	if err != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to update upload", "db-error")
+	}
+
+	return nil
+}
+
+// UpdateAttachmentMetadata rewrites the attachment entry of one upload on a message, other attachments are
+// left alone. Returns the updated message, or nil when the message is gone or was deleted for everyone.
+func (r *ChatRepo) UpdateAttachmentMetadata(ctx context.Context, messageID primitive.ObjectID, attachment *entity.Attachment) (*entity.Message, *app_error.AppError) {
+	collection := r.AppState.Mongo.Database("chat_collection").Collection("messages")
+
+	filter := bson.M{
+		"_id":                   messageID,
+		"is_deleted":            bson.M{"$ne": true},
+		"attachments.upload_id": attachment.UploadID,
+	}
+	update := bson.M{"$set": bson.M{
+		"attachments.$[a].size":          attachment.Size,
+		"attachments.$[a].mime_type":     attachment.MimeType,
+		"attachments.$[a].width":         attachment.Width,
+		"attachments.$[a].height":        attachment.Height,
+		"attachments.$[a].thumbnail_url": attachment.ThumbnailURL,
+	}}
+
+	var msg entity.Message
+	err := collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().
+			SetArrayFilters([]any{bson.M{"a.upload_id": attachment.UploadID}}).
+			SetReturnDocument(options.After),
+	).Decode(&msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update attachment: %v", err), "mongo")
+	}
+
+	return &msg, nil
+}
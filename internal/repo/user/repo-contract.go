@@ -12,4 +12,6 @@ type UserRepoContract interface {
 	SaveUser(ctx context.Context, model entity.User) *app_error.AppError
 	VerifyUser(ctx context.Context, userId string) (*entity.User, *app_error.AppError)
 	FindUserByCredential(ctx context.Context, username string) (*entity.User, *app_error.AppError)
+	FindUsersByUsernames(ctx context.Context, usernames []string) ([]*entity.User, *app_error.AppError)
+	FindUsersByIDs(ctx context.Context, userIDs []string) ([]*entity.User, *app_error.AppError)
 }
@@ -87,3 +87,31 @@ func (r *UserRepo) FindUserByCredential(ctx context.Context, username string) (*
 
 	return &user, nil
 }
+
+func (r *UserRepo) FindUsersByUsernames(ctx context.Context, usernames []string) ([]*entity.User, *app_error.AppError) {
+	var users []*entity.User
+
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	if err := r.AppState.DB.WithContext(ctx).Where("username IN ? AND is_active = ?", usernames, true).Find(&users).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "unexpected error occur when fetch users", "db-error")
+	}
+
+	return users, nil
+}
+
+func (r *UserRepo) FindUsersByIDs(ctx context.Context, userIDs []string) ([]*entity.User, *app_error.AppError) {
+	var users []*entity.User
+
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	if err := r.AppState.DB.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "unexpected error occur when fetch users", "db-error")
+	}
+
+	return users, nil
+}
@@ -10,12 +10,78 @@ import (
 
 func ChatRouter(r chi.Router, state *state.AppState) {
 	chatHandler := chat_handler.NewChatHandler(state)
+
+	// signed download links carry their own expiry and signature instead of a token
+	r.Get("/api/v1/files/{uploadId}", handlers.WrapHandler(chatHandler.DownloadFile)) // query params expires, sig
+	r.Get("/api/v1/files/{uploadId}/thumbnail", handlers.WrapHandler(chatHandler.DownloadThumbnail))
+
 	r.Group(func(protected chi.Router) {
 		protected.Use(middleware.JWTAuthWithAutoRefresh(state.JwtSecret.Private, state.JwtSecret.Public, state.Redis))
 		protected.Post("/api/v1/chat/{receiverId}/messages", handlers.WrapHandler(chatHandler.SendPrivateMessage))
+		protected.Post("/api/v1/chat/{receiverId}/messages/scheduled", handlers.WrapHandler(chatHandler.ScheduleMessage)) // body send_at (RFC3339) must be in the future
 		protected.Get("/api/v1/chat/{roomId}/messages", handlers.WrapHandler(chatHandler.GetPrivateMessages))
 		protected.Post("/api/v1/chat/{roomId}", handlers.WrapHandler(chatHandler.ReplyPrivateMessage))
-		protected.Patch("/api/v1/chat/{roomId}/read", handlers.WrapHandler(chatHandler.MarkMessageAsRead)) // receive query param message_id
+		protected.Patch("/api/v1/chat/{roomId}/read", handlers.WrapHandler(chatHandler.MarkMessageAsRead))  // receive query param message_id, moves the read cursor up to it
+		protected.Get("/api/v1/chat/{roomId}/search", handlers.WrapHandler(chatHandler.SearchRoomMessages)) // same query params as /api/v1/search/messages
+		protected.Get("/api/v1/chat/{roomId}/messages/{messageId}/receipts", handlers.WrapHandler(chatHandler.GetMessageReceipts))
+		protected.Get("/api/v1/chat/{roomId}/messages/{messageId}/history", handlers.WrapHandler(chatHandler.GetMessageHistory)) // every revision, the original first
+		protected.Delete("/api/v1/chat/{roomId}/messages/{messageId}", handlers.WrapHandler(chatHandler.DeleteMessage))          // query param scope=me|everyone, defaults to me
+		protected.Get("/api/v1/chat/{roomId}/messages/{messageId}/thread", handlers.WrapHandler(chatHandler.GetThread))
+		protected.Post("/api/v1/chat/{roomId}/messages/{messageId}/pin", handlers.WrapHandler(chatHandler.PinMessage))
+		protected.Delete("/api/v1/chat/{roomId}/messages/{messageId}/pin", handlers.WrapHandler(chatHandler.UnpinMessage))
+		protected.Get("/api/v1/chat/{roomId}/pins", handlers.WrapHandler(chatHandler.GetRoomPins))
+		protected.Post("/api/v1/chat/{roomId}/messages/{messageId}/reactions", handlers.WrapHandler(chatHandler.AddReaction))
+		protected.Delete("/api/v1/chat/{roomId}/messages/{messageId}/reactions", handlers.WrapHandler(chatHandler.RemoveReaction))
 		protected.Put("/api/v1/chat/{roomId}/update", handlers.WrapHandler(chatHandler.UpdatePrivateMessage))
+
+		// multipart form with a "file" part, the returned upload_id goes into attachment_ids of a message
+		protected.Post("/api/v1/uploads", handlers.WrapHandler(chatHandler.UploadAttachment))
+
+		// search across the rooms of the caller, query params q, room_id, sender_id, from, to (RFC3339), limit, before_id
+		protected.Get("/api/v1/search/messages", handlers.WrapHandler(chatHandler.SearchMessages))
+
+		// room messages (private and group)
+		protected.Post("/api/v1/rooms/{roomId}/messages", handlers.WrapHandler(chatHandler.SendRoomMessage))
+		protected.Put("/api/v1/rooms/{roomId}/disappearing", handlers.WrapHandler(chatHandler.SetDisappearingTimer)) // body timer=off|1h|24h|7d
+		protected.Put("/api/v1/rooms/{roomId}/edit-window", handlers.WrapHandler(chatHandler.SetEditWindow))         // body window=off|default|<duration>
+		protected.Post("/api/v1/rooms/{roomId}/messages/forward", handlers.WrapHandler(chatHandler.ForwardMessages))
+		protected.Post("/api/v1/rooms/{roomId}/messages/{messageId}/reply", handlers.WrapHandler(chatHandler.ReplyRoomMessage))
+		protected.Put("/api/v1/rooms/{roomId}/messages/{messageId}", handlers.WrapHandler(chatHandler.UpdateRoomMessage))
+
+		// polls are stored as messages of the room, closes_at (RFC3339) is optional
+		protected.Post("/api/v1/rooms/{roomId}/polls", handlers.WrapHandler(chatHandler.CreatePoll))
+		protected.Put("/api/v1/rooms/{roomId}/polls/{messageId}/vote", handlers.WrapHandler(chatHandler.VotePoll)) // replaces an earlier vote
+		protected.Delete("/api/v1/rooms/{roomId}/polls/{messageId}/vote", handlers.WrapHandler(chatHandler.RetractPollVote))
+		protected.Post("/api/v1/rooms/{roomId}/polls/{messageId}/close", handlers.WrapHandler(chatHandler.ClosePoll))
+
+		// group rooms
+		protected.Post("/api/v1/groups", handlers.WrapHandler(chatHandler.CreateGroup))
+		protected.Patch("/api/v1/groups/{roomId}", handlers.WrapHandler(chatHandler.RenameGroup))
+		protected.Get("/api/v1/groups/{roomId}/members", handlers.WrapHandler(chatHandler.GetGroupMembers))
+		protected.Delete("/api/v1/groups/{roomId}", handlers.WrapHandler(chatHandler.DeleteGroup))
+		protected.Patch("/api/v1/groups/{roomId}/members/{userId}/role", handlers.WrapHandler(chatHandler.ChangeMemberRole))
+		protected.Post("/api/v1/groups/{roomId}/transfer-ownership", handlers.WrapHandler(chatHandler.TransferOwnership))
+		protected.Post("/api/v1/groups/{roomId}/leave", handlers.WrapHandler(chatHandler.LeaveRoom))
+		protected.Delete("/api/v1/groups/{roomId}/members/{userId}", handlers.WrapHandler(chatHandler.RemoveMember))
+		protected.Get("/api/v1/groups/{roomId}/members/events", handlers.WrapHandler(chatHandler.GetMembershipEvents))
+
+		// inbox of the caller, latest activity first, query params limit, before_id (next_cursor of the previous page)
+		protected.Get("/api/v1/me/rooms", handlers.WrapHandler(chatHandler.GetInbox))
+		protected.Patch("/api/v1/me/rooms/{roomId}", handlers.WrapHandler(chatHandler.SetInboxSettings)) // body muted, archived
+
+		// group invitations
+		protected.Post("/api/v1/groups/{roomId}/invites", handlers.WrapHandler(chatHandler.InviteToGroup))
+		protected.Post("/api/v1/groups/{roomId}/invite-links", handlers.WrapHandler(chatHandler.CreateInviteLink))
+		protected.Get("/api/v1/me/invites", handlers.WrapHandler(chatHandler.GetPendingInvitations))
+		protected.Get("/api/v1/me/mentions", handlers.WrapHandler(chatHandler.GetMentions))
+		protected.Get("/api/v1/me/bookmarks", handlers.WrapHandler(chatHandler.GetBookmarks))
+		protected.Post("/api/v1/me/bookmarks/{messageId}", handlers.WrapHandler(chatHandler.AddBookmark))
+		protected.Delete("/api/v1/me/bookmarks/{messageId}", handlers.WrapHandler(chatHandler.RemoveBookmark))
+		protected.Get("/api/v1/me/scheduled-messages", handlers.WrapHandler(chatHandler.GetScheduledMessages))
+		protected.Patch("/api/v1/me/scheduled-messages/{scheduledId}", handlers.WrapHandler(chatHandler.UpdateScheduledMessage))
+		protected.Delete("/api/v1/me/scheduled-messages/{scheduledId}", handlers.WrapHandler(chatHandler.CancelScheduledMessage))
+		protected.Post("/api/v1/invites/{inviteId}/accept", handlers.WrapHandler(chatHandler.AcceptInvitation))
+		protected.Post("/api/v1/invites/{inviteId}/decline", handlers.WrapHandler(chatHandler.DeclineInvitation))
+		protected.Post("/api/v1/invite-links/{code}/join", handlers.WrapHandler(chatHandler.JoinByInviteLink))
 	})
 }
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned by Open when no blob is stored under the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps the raw bytes of uploaded files, keys are opaque to the store and chosen by the caller
+type BlobStore interface {
+	// Put stores everything read from r under key and returns the number of bytes written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
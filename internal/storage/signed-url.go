@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/config"
+)
+
+var (
+	fallbackKey     []byte
+	fallbackKeyOnce sync.Once
+)
+
+// SignURL appends an expiry and a signature to path, the result can be fetched without a token until expiresAt
+func SignURL(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return fmt.Sprintf("%s?expires=%s&sig=%s", path, expires, signature(path, expires))
+}
+
+// VerifySignedURL checks the expires and sig query values of a request for path
+func VerifySignedURL(path, expires, sig string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(sig), []byte(signature(path, expires)))
+}
+
+func signature(path, expires string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(path + "|" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signingKey falls back to a random per process key, signed URLs then stop working after a restart
+// and are not shared between instances
+func signingKey() []byte {
+	if config.Conf != nil && config.Conf.STORAGE.SigningKey != "" {
+		return []byte(config.Conf.STORAGE.SigningKey)
+	}
+
+	fallbackKeyOnce.Do(func() {
+		fallbackKey = make([]byte, 32)
+		if _, err := rand.Read(fallbackKey); err != nil {
+			panic(fmt.Sprintf("failed to generate url signing key: %v", err))
+		}
+		log.Warn().Msg("STORAGE.SIGNING_KEY is not set, signed file URLs use a random key")
+	})
+
+	return fallbackKey
+}
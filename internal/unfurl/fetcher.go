@@ -0,0 +1,161 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	fetchTimeout = 10 * time.Second
+	maxRedirects = 5
+	maxBodySize  = 512 << 10 // the metadata lives in <head>, the rest of the page is not needed
+	userAgent    = "chat-system-unfurl/1.0"
+)
+
+var (
+	ErrBlockedAddress = errors.New("address is not allowed")
+	ErrUnsupportedURL = errors.New("only http and https links are unfurled")
+	ErrNotHTML        = errors.New("link does not point at an html page")
+)
+
+// Preview is the metadata of a linked page, every field but URL may be empty
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Fetcher loads the preview of a link, tests swap the http implementation for a local stub
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Preview, error)
+}
+
+// HTTPFetcher reads Open Graph and html metadata over http. Private, loopback and link-local
+// addresses are refused when connecting, so neither the link nor a redirect or a DNS answer can
+// make the server call into its own network.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+func NewHTTPFetcher() *HTTPFetcher {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: refusePrivateAddress,
+	}
+
+	return &HTTPFetcher{
+		Client: &http.Client{
+			Timeout: fetchTimeout,
+			Transport: &http.Transport{
+				Proxy:                 nil, // a proxy would connect on our behalf and skip the address check
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   5 * time.Second,
+				ResponseHeaderTimeout: 5 * time.Second,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return checkURL(req.URL)
+			},
+		},
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if err := checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	// relative image links resolve against the final url after redirects
+	preview := parseHTML(io.LimitReader(resp.Body, maxBodySize), resp.Request.URL)
+	preview.URL = rawURL
+
+	return preview, nil
+}
+
+// checkURL rejects anything but http(s) links and hosts that are obviously internal, the resolved
+// address is checked again when connecting
+func checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return ErrUnsupportedURL
+	}
+
+	host := target.Hostname()
+	if host == "" || host == "localhost" {
+		return ErrBlockedAddress
+	}
+
+	if ip := net.ParseIP(host); ip != nil && IsBlockedIP(ip) {
+		return ErrBlockedAddress
+	}
+
+	return nil
+}
+
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || IsBlockedIP(ip) {
+		return ErrBlockedAddress
+	}
+
+	return nil
+}
+
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsBlockedIP reports addresses the fetcher must never connect to
+func IsBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		carrierGradeNAT.Contains(ip) ||
+		(ip.To4() != nil && ip.To4()[0] == 0)
+}
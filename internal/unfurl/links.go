@@ -0,0 +1,34 @@
+package unfurl
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"']+`)
+
+// ExtractURLs returns up to limit distinct http(s) links of a message in order of appearance,
+// punctuation that usually ends a sentence is not part of the link
+func ExtractURLs(content string, limit int) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, match := range linkPattern.FindAllString(content, -1) {
+		link := strings.TrimRight(match, ".,;:!?)]}*_~")
+		if link == "" || seen[link] {
+			continue
+		}
+
+		seen[link] = true
+		urls = append(urls, link)
+		if len(urls) == limit {
+			break
+		}
+	}
+
+	return urls
+}
+
+// HasLinks reports whether a message has anything to unfurl
+func HasLinks(content string) bool {
+	return len(ExtractURLs(content, 1)) > 0
+}
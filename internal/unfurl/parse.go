@@ -0,0 +1,135 @@
+package unfurl
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+)
+
+// parseHTML reads the preview from the <head> of a page, Open Graph tags win over <title> and the
+// description meta tag
+func parseHTML(r io.Reader, base *url.URL) *Preview {
+	var title, ogTitle, description, ogDescription, image, siteName string
+
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return newPreview(base, firstNonEmpty(ogTitle, title), firstNonEmpty(ogDescription, description), image, siteName)
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = true
+			case "body":
+				return newPreview(base, firstNonEmpty(ogTitle, title), firstNonEmpty(ogDescription, description), image, siteName)
+			case "meta":
+				key, content := metaAttributes(token)
+				switch key {
+				case "og:title", "twitter:title":
+					ogTitle = firstNonEmpty(ogTitle, content)
+				case "og:description", "twitter:description":
+					ogDescription = firstNonEmpty(ogDescription, content)
+				case "description":
+					description = firstNonEmpty(description, content)
+				case "og:image", "og:image:url", "twitter:image":
+					image = firstNonEmpty(image, content)
+				case "og:site_name":
+					siteName = firstNonEmpty(siteName, content)
+				}
+			}
+
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = string(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return newPreview(base, firstNonEmpty(ogTitle, title), firstNonEmpty(ogDescription, description), image, siteName)
+			}
+		}
+	}
+}
+
+func metaAttributes(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+
+	return key, content
+}
+
+func newPreview(base *url.URL, title, description, image, siteName string) *Preview {
+	return &Preview{
+		Title:       truncate(collapseSpaces(title), maxTitleLength),
+		Description: truncate(collapseSpaces(description), maxDescriptionLength),
+		ImageURL:    resolveImage(base, image),
+		SiteName:    truncate(collapseSpaces(siteName), maxTitleLength),
+	}
+}
+
+// resolveImage makes relative image links absolute, only http(s) images are kept
+func resolveImage(base *url.URL, image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return ""
+	}
+
+	return ref.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
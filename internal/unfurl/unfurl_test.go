@@ -0,0 +1,129 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    []string
+	}{
+		{"none", "no links here", 3, nil},
+		{"trailing punctuation", "see https://example.com/a, and (http://example.org/b).", 3, []string{"https://example.com/a", "http://example.org/b"}},
+		{"duplicates", "https://example.com https://example.com", 3, []string{"https://example.com"}},
+		{"limit", "https://a.example https://b.example https://c.example", 2, []string{"https://a.example", "https://b.example"}},
+		{"other schemes", "ftp://example.com javascript:alert(1)", 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractURLs(tt.content, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBlockedIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsBlockedIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+
+	t.Run("open graph wins", func(t *testing.T) {
+		page := `<html><head>
+			<title>Plain title</title>
+			<meta name="description" content="Plain description">
+			<meta property="og:title" content="  OG   title ">
+			<meta property="og:description" content="OG description">
+			<meta property="og:image" content="/img/cover.png">
+			<meta property="og:site_name" content="Example">
+		</head><body><meta property="og:title" content="ignored"></body></html>`
+
+		got := parseHTML(strings.NewReader(page), base)
+		want := &Preview{
+			Title:       "OG title",
+			Description: "OG description",
+			ImageURL:    "https://example.com/img/cover.png",
+			SiteName:    "Example",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseHTML() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("falls back to title and description", func(t *testing.T) {
+		page := `<html><head><title>Plain &amp; simple</title><meta name="description" content="About"></head></html>`
+
+		got := parseHTML(strings.NewReader(page), base)
+		if got.Title != "Plain & simple" || got.Description != "About" {
+			t.Errorf("parseHTML() = %+v", got)
+		}
+	})
+
+	t.Run("drops non http images", func(t *testing.T) {
+		page := `<head><meta property="og:image" content="javascript:alert(1)"></head>`
+
+		if got := parseHTML(strings.NewReader(page), base); got.ImageURL != "" {
+			t.Errorf("ImageURL = %q, want empty", got.ImageURL)
+		}
+	})
+}
+
+func TestHTTPFetcherRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<title>internal</title>"))
+	}))
+	defer srv.Close()
+
+	fetcher := NewHTTPFetcher()
+	for _, link := range []string{srv.URL, "http://localhost/", "http://[::1]/", "file:///etc/passwd"} {
+		if _, err := fetcher.Fetch(context.Background(), link); err == nil {
+			t.Errorf("Fetch(%s) succeeded, want it refused", link)
+		}
+	}
+
+	// a public name that resolves to an internal address is refused when connecting
+	if err := refusePrivateAddress("tcp", "127.0.0.1:80", nil); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("refusePrivateAddress() = %v, want ErrBlockedAddress", err)
+	}
+	if err := refusePrivateAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("refusePrivateAddress() = %v, want nil", err)
+	}
+}
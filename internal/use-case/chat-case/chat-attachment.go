@@ -0,0 +1,246 @@
+package chat_service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif" // registers the decoders used by image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/storage"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	thumbnailMaxSide = 320
+	thumbnailQuality = 80
+	// larger images only get their dimensions, decoding them would need too much memory
+	maxThumbnailPixels = 40_000_000
+)
+
+// imageInfo is what decoding an image yields, Thumbnail is empty when the image could not be decoded
+type imageInfo struct {
+	Width             int
+	Height            int
+	Thumbnail         []byte
+	ThumbnailMimeType string
+}
+
+// ProcessMessageAttachments fills in size and MIME type of every uploaded attachment of a message from the
+// stored file, images also get their dimensions and a thumbnail. Returns the updated message for a
+// message_updated event, nil when there is nothing to update or the message is gone.
+func (c *ChatService) ProcessMessageAttachments(ctx context.Context, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	msgID, convErr := primitive.ObjectIDFromHex(messageID)
+	if convErr != nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "invalid message id", "message_id")
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if msg.IsDeleted {
+		return nil, nil
+	}
+
+	var updated *entity.Message
+	for _, attachment := range msg.Attachments {
+		if attachment.UploadID == "" {
+			continue
+		}
+
+		upload, err := c.ChatRepo.FindUploadByID(ctx, attachment.UploadID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.describeUpload(ctx, upload); err != nil {
+			return nil, err
+		}
+
+		if err := c.ChatRepo.UpdateUploadMetadata(ctx, upload); err != nil {
+			return nil, err
+		}
+
+		metadata := &entity.Attachment{
+			UploadID: upload.ID,
+			Size:     upload.Size,
+			MimeType: upload.MimeType,
+			Width:    upload.Width,
+			Height:   upload.Height,
+		}
+		if upload.ThumbnailKey != "" {
+			metadata.ThumbnailURL = thumbnailURLPath(upload.ID)
+		}
+
+		updated, err = c.ChatRepo.UpdateAttachmentMetadata(ctx, msgID, metadata)
+		if err != nil {
+			return nil, err
+		}
+		// deleted for everyone while processing
+		if updated == nil {
+			return nil, nil
+		}
+	}
+
+	if updated == nil {
+		return nil, nil
+	}
+
+	return c.messageUpdatedResponse(ctx, updated)
+}
+
+// messageUpdatedResponse is the message_updated event of a background update like attachment metadata or link
+// previews, it goes to every active member of the room
+func (c *ChatService) messageUpdatedResponse(ctx context.Context, msg *entity.Message) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(msg.RoomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	members, err := c.ChatRepo.FindRoomMembers(ctx, msg.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.RoomMessageResponse{
+		MessageID:    msg.ID.Hex(),
+		RoomID:       msg.RoomID,
+		SenderID:     msg.SenderID,
+		ReceiverID:   msg.ReceiverID,
+		MessageType:  messageType(msg.Type),
+		Content:      msg.Content,
+		Format:       messageFormat(msg.Format),
+		PlainText:    plainTextOf(msg),
+		Attachments:  toAttachmentDTOs(msg.Attachments),
+		LinkPreviews: toLinkPreviewDTOs(msg.LinkPreviews),
+		IsEdited:     msg.IsEdited,
+		CreatedAt:    msg.CreatedAt,
+		UpdatedAt:    msg.UpdatedAt,
+		ExpiresAt:    msg.ExpiresAt,
+		Recipients:   activeMemberIDs(members),
+	}, nil
+}
+
+// OpenThumbnail opens the thumbnail of an image upload, the caller checks the signed URL and closes the reader
+func (c *ChatService) OpenThumbnail(ctx context.Context, uploadID string) (*chat_dto.UploadResponse, io.ReadCloser, *app_error.AppError) {
+	upload, err := c.ChatRepo.FindUploadByID(ctx, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if upload.ThumbnailKey == "" {
+		return nil, nil, app_error.NewAppError(http.StatusNotFound, "file has no thumbnail", "not-found")
+	}
+
+	blob, err := c.openBlob(ctx, upload.ThumbnailKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &chat_dto.UploadResponse{
+		UploadID:  upload.ID,
+		Type:      entity.AttachmentTypeImage,
+		Filename:  upload.Filename,
+		MimeType:  upload.ThumbnailMimeType,
+		CreatedAt: upload.CreatedAt,
+	}, blob, nil
+}
+
+// describeUpload reads the stored file back and records its real size and type, the thumbnail of an
+// image is stored next to the file
+func (c *ChatService) describeUpload(ctx context.Context, upload *entity.FileUpload) *app_error.AppError {
+	blob, err := c.openBlob(ctx, upload.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, readErr := io.ReadAll(blob)
+	blob.Close()
+	if readErr != nil {
+		return app_error.NewAppError(http.StatusInternalServerError, "failed to read file", "storage")
+	}
+
+	upload.Size = int64(len(data))
+	upload.MimeType = sniffMimeType(data)
+
+	if attachmentType(upload.MimeType) == entity.AttachmentTypeImage {
+		info := decodeImage(data)
+		upload.Width, upload.Height = info.Width, info.Height
+
+		if len(info.Thumbnail) > 0 {
+			key := upload.StorageKey + ".thumb"
+			if _, err := c.Blobs.Put(ctx, key, bytes.NewReader(info.Thumbnail)); err != nil {
+				return app_error.NewAppError(http.StatusInternalServerError, "failed to store thumbnail", "storage")
+			}
+			upload.ThumbnailKey, upload.ThumbnailMimeType = key, info.ThumbnailMimeType
+		}
+	}
+
+	now := time.Now()
+	upload.ProcessedAt = &now
+
+	return nil
+}
+
+func (c *ChatService) openBlob(ctx context.Context, key string) (io.ReadCloser, *app_error.AppError) {
+	blob, err := c.Blobs.Open(ctx, key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, app_error.NewAppError(http.StatusNotFound, "file not found", "not-found")
+	}
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to open file", "storage")
+	}
+
+	return blob, nil
+}
+
+// decodeImage reads the dimensions of a JPEG, PNG or GIF and renders a thumbnail, JPEGs stay JPEG and the
+// rest become PNG so transparency is kept. Formats the standard library cannot decode yield an empty info.
+func decodeImage(data []byte) imageInfo {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return imageInfo{}
+	}
+
+	info := imageInfo{Width: cfg.Width, Height: cfg.Height}
+	if cfg.Width*cfg.Height > maxThumbnailPixels {
+		return info
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return info
+	}
+
+	var buf bytes.Buffer
+	thumb := utils.Thumbnail(img, thumbnailMaxSide)
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: thumbnailQuality})
+		info.ThumbnailMimeType = "image/jpeg"
+	} else {
+		err = png.Encode(&buf, thumb)
+		info.ThumbnailMimeType = "image/png"
+	}
+	if err != nil {
+		return imageInfo{Width: cfg.Width, Height: cfg.Height}
+	}
+
+	info.Thumbnail = buf.Bytes()
+	return info
+}
+
+func thumbnailURLPath(uploadID string) string {
+	return fileURLPath(uploadID) + "/thumbnail"
+}
@@ -0,0 +1,60 @@
+package chat_service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestDecodeImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, src, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+	}{
+		{"png", pngData.Bytes(), "image/png"},
+		{"jpeg", jpegData.Bytes(), "image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := decodeImage(tt.data)
+			if info.Width != 800 || info.Height != 400 {
+				t.Errorf("decodeImage() size = %dx%d, want 800x400", info.Width, info.Height)
+			}
+			if info.ThumbnailMimeType != tt.mimeType {
+				t.Errorf("decodeImage() thumbnail type = %q, want %q", info.ThumbnailMimeType, tt.mimeType)
+			}
+
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(info.Thumbnail))
+			if err != nil {
+				t.Fatalf("thumbnail does not decode: %v", err)
+			}
+			if thumb.Width != thumbnailMaxSide || thumb.Height != thumbnailMaxSide/2 {
+				t.Errorf("thumbnail size = %dx%d, want %dx%d", thumb.Width, thumb.Height, thumbnailMaxSide, thumbnailMaxSide/2)
+			}
+		})
+	}
+
+	if info := decodeImage([]byte("%PDF-1.7\n")); info.Width != 0 || len(info.Thumbnail) != 0 {
+		t.Errorf("decodeImage() of a pdf = %+v, want empty", info)
+	}
+}
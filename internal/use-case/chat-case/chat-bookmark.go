@@ -0,0 +1,150 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (c *ChatService) AddBookmark(ctx context.Context, userID, messageID string) (*chat_dto.BookmarkResponse, *app_error.AppError) {
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// messages of a deleted room can't be bookmarked
+	_, members, err := c.findActiveRoomWithMembers(ctx, msg.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.findActiveMember(members, userID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	if msg.IsDeleted || isHiddenFor(msg, userID) {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "deleted message cannot be bookmarked", "deleted")
+	}
+
+	bookmark := &entity.MessageBookmark{
+		UserID:    userID,
+		RoomID:    msg.RoomID,
+		MessageID: msg.ID.Hex(),
+	}
+	created, err := c.ChatRepo.CreateBookmark(ctx, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, app_error.NewAppError(http.StatusConflict, "message is already bookmarked", "bookmarked")
+	}
+
+	return &chat_dto.BookmarkResponse{
+		BookmarkID:   bookmark.ID,
+		BookmarkedAt: bookmark.CreatedAt,
+		Message:      toPrivateMessage(members, userID, msg),
+	}, nil
+}
+
+// RemoveBookmark works without a membership check so bookmarks of rooms the user left can still be cleaned up
+func (c *ChatService) RemoveBookmark(ctx context.Context, userID, messageID string) *app_error.AppError {
+	if _, err := primitive.ObjectIDFromHex(messageID); err != nil {
+		return app_error.NewAppError(http.StatusBadRequest, "invalid message ID", "invalid-id")
+	}
+
+	deleted, err := c.ChatRepo.DeleteBookmark(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return app_error.NewAppError(http.StatusNotFound, "bookmark not found", "not-found")
+	}
+
+	return nil
+}
+
+func (c *ChatService) GetBookmarks(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.BookmarksResponse, *app_error.AppError) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// the cursor is the id of the oldest bookmark of the previous page
+	var beforeID int64
+	if req.BeforeID != nil {
+		id, err := strconv.ParseInt(*req.BeforeID, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, app_error.NewAppError(http.StatusBadRequest, "before_id must be a bookmark id", "before-id")
+		}
+		beforeID = id
+	}
+
+	bookmarks, err := c.ChatRepo.FindBookmarks(ctx, userID, limit, beforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		if id, err := primitive.ObjectIDFromHex(bookmark.MessageID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	messages, err := c.ChatRepo.FindMessagesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*entity.Message, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID.Hex()] = msg
+	}
+
+	// membership is checked once per room, rooms the user left (or that were deleted) are skipped
+	// but their bookmarks are kept so they come back after a rejoin
+	roomMembers := make(map[string][]*entity.RoomMember)
+	respBookmarks := make([]chat_dto.BookmarkResponse, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		msg, ok := byID[bookmark.MessageID]
+		if !ok || isHiddenFor(msg, userID) {
+			continue
+		}
+
+		members, seen := roomMembers[bookmark.RoomID]
+		if !seen {
+			_, members, err = c.findActiveRoomWithMembers(ctx, bookmark.RoomID)
+			if err != nil {
+				members = nil
+			}
+			roomMembers[bookmark.RoomID] = members
+		}
+		if c.findActiveMember(members, userID) == nil {
+			continue
+		}
+
+		// messages deleted for everyone stay listed as tombstones
+		respBookmarks = append(respBookmarks, chat_dto.BookmarkResponse{
+			BookmarkID:   bookmark.ID,
+			BookmarkedAt: bookmark.CreatedAt,
+			Message:      toPrivateMessage(members, userID, msg),
+		})
+	}
+
+	var nextCursor *string
+	if len(bookmarks) > 0 {
+		oldestBookmarkID := strconv.FormatInt(bookmarks[len(bookmarks)-1].ID, 10)
+		nextCursor = &oldestBookmarkID
+	}
+
+	return &chat_dto.BookmarksResponse{
+		Bookmarks:  respBookmarks,
+		NextCursor: nextCursor,
+		HasMore:    len(bookmarks) == limit,
+	}, nil
+}
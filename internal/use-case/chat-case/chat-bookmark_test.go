@@ -0,0 +1,58 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestAddBookmark(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newFakeChatService(t)
+
+	room := repo.addRoom(entity.RoomTypeGroup, "u1", "u2")
+	msg := repo.addMessage(room, "u2", "hello")
+
+	resp, err := c.AddBookmark(ctx, "u1", msg.ID.Hex())
+	if err != nil {
+		t.Fatalf("AddBookmark() error = %v", err.Message)
+	}
+	if resp.Message.MessageID != msg.ID.Hex() {
+		t.Errorf("AddBookmark() bookmarked %s, want %s", resp.Message.MessageID, msg.ID.Hex())
+	}
+
+	deleted := repo.addMessage(room, "u2", "gone")
+	deleted.IsDeleted = true
+	hidden := repo.addMessage(room, "u2", "gone for u1")
+	hidden.DeletedFor = []string{"u1"}
+
+	deletedRoom := repo.addRoom(entity.RoomTypeGroup, "u1", "u2")
+	inDeletedRoom := repo.addMessage(deletedRoom, "u2", "hello")
+	deletedAt := time.Now()
+	deletedRoom.DeletedAt = &deletedAt
+
+	tests := []struct {
+		name      string
+		userID    string
+		messageID string
+		wantCode  int
+	}{
+		{"duplicate bookmark", "u1", msg.ID.Hex(), http.StatusConflict},
+		{"not a member", "u3", msg.ID.Hex(), http.StatusForbidden},
+		{"deleted for everyone", "u1", deleted.ID.Hex(), http.StatusBadRequest},
+		{"deleted for the user", "u1", hidden.ID.Hex(), http.StatusBadRequest},
+		{"deleted room", "u1", inDeletedRoom.ID.Hex(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.AddBookmark(ctx, tt.userID, tt.messageID)
+			if err == nil || err.Code != tt.wantCode {
+				t.Errorf("AddBookmark() error = %v, want code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
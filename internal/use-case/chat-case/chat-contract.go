@@ -2,6 +2,8 @@ package chat_service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
 	app_error "github.com/xenn00/chat-system/internal/errors"
@@ -9,8 +11,63 @@ import (
 
 type ChatServiceContract interface {
 	SendPrivateMessage(ctx context.Context, req chat_dto.SendPrivateMessageRequest, senderID, receiverID string) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError)
-	GetPrivateMessage(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, roomID string) (*chat_dto.GetPrivateMessagesResponse, *app_error.AppError)
+	ScheduleMessage(ctx context.Context, req chat_dto.ScheduleMessageRequest, senderID, receiverID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError)
+	GetScheduledMessages(ctx context.Context, userID string) (*chat_dto.ScheduledMessagesResponse, *app_error.AppError)
+	UpdateScheduledMessage(ctx context.Context, req chat_dto.UpdateScheduledMessageRequest, userID, scheduledID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError)
+	CancelScheduledMessage(ctx context.Context, userID, scheduledID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError)
+	DeliverScheduledMessage(ctx context.Context, scheduledID string, version int) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError)
+	RecoverScheduledMessages(ctx context.Context, before time.Time, limit int) ([]chat_dto.ScheduledMessageResponse, *app_error.AppError)
+	GetPrivateMessage(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID, roomID string) (*chat_dto.GetPrivateMessagesResponse, *app_error.AppError)
 	ReplyPrivateMessage(ctx context.Context, req chat_dto.ReplyPrivateMessageRequest, senderID, roomID string) (*chat_dto.ReplyPrivateMessageResponse, *app_error.AppError)
-	MarkPrivateMessageAsRead(ctx context.Context, receiverID, roomID, messageID string) *app_error.AppError
+	MarkMessageAsRead(ctx context.Context, userID, roomID, messageID string) (*chat_dto.ReadCursorResponse, *app_error.AppError)
+	GetMessageReceipts(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessageReceiptsResponse, *app_error.AppError)
+	GetMessageHistory(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessageHistoryResponse, *app_error.AppError)
 	UpdatePrivateMessage(ctx context.Context, req chat_dto.UpdatePrivateMessageRequest, senderID, roomID, messageID string) (*chat_dto.UpdatePrivateMessageResponse, *app_error.AppError)
+	SendRoomMessage(ctx context.Context, req chat_dto.SendRoomMessageRequest, senderID, roomID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	ReplyRoomMessage(ctx context.Context, req chat_dto.ReplyRoomMessageRequest, senderID, roomID, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	UpdateRoomMessage(ctx context.Context, req chat_dto.UpdateRoomMessageRequest, senderID, roomID, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	DeleteMessage(ctx context.Context, userID, roomID, messageID, scope string) (*chat_dto.MessageDeletedResponse, *app_error.AppError)
+	UploadAttachment(ctx context.Context, uploaderID, filename string, content io.Reader) (*chat_dto.UploadResponse, *app_error.AppError)
+	OpenUpload(ctx context.Context, uploadID string) (*chat_dto.UploadResponse, io.ReadCloser, *app_error.AppError)
+	OpenThumbnail(ctx context.Context, uploadID string) (*chat_dto.UploadResponse, io.ReadCloser, *app_error.AppError)
+	ProcessMessageAttachments(ctx context.Context, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	UnfurlMessageLinks(ctx context.Context, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	CreatePoll(ctx context.Context, req chat_dto.CreatePollRequest, senderID, roomID string) (*chat_dto.RoomMessageResponse, *app_error.AppError)
+	VotePoll(ctx context.Context, req chat_dto.VotePollRequest, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError)
+	RetractPollVote(ctx context.Context, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError)
+	ClosePoll(ctx context.Context, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError)
+	CloseExpiredPoll(ctx context.Context, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError)
+	ForwardMessages(ctx context.Context, req chat_dto.ForwardMessagesRequest, senderID, roomID string) (*chat_dto.ForwardMessagesResponse, *app_error.AppError)
+	SetDisappearingTimer(ctx context.Context, req chat_dto.DisappearingTimerRequest, userID, roomID string) (*chat_dto.DisappearingTimerResponse, *app_error.AppError)
+	SetEditWindow(ctx context.Context, req chat_dto.EditWindowRequest, userID, roomID string) (*chat_dto.EditWindowResponse, *app_error.AppError)
+	SweepExpiredMessages(ctx context.Context, now time.Time, limit int) ([]*chat_dto.MessageDeletedResponse, *app_error.AppError)
+	ReconcileUnreadCounts(ctx context.Context, limit int) (int, *app_error.AppError)
+	GetThread(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID, roomID, messageID string) (*chat_dto.ThreadResponse, *app_error.AppError)
+	GetInbox(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.InboxResponse, *app_error.AppError)
+	SetInboxSettings(ctx context.Context, req chat_dto.InboxSettingsRequest, userID, roomID string) (*chat_dto.InboxSettingsResponse, *app_error.AppError)
+	GetMentions(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.MentionsResponse, *app_error.AppError)
+	SearchMessages(ctx context.Context, req chat_dto.SearchMessagesRequest, userID string) (*chat_dto.SearchMessagesResponse, *app_error.AppError)
+	PinMessage(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessagePinnedResponse, *app_error.AppError)
+	UnpinMessage(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessagePinnedResponse, *app_error.AppError)
+	GetRoomPins(ctx context.Context, userID, roomID string) (*chat_dto.RoomPinsResponse, *app_error.AppError)
+	AddBookmark(ctx context.Context, userID, messageID string) (*chat_dto.BookmarkResponse, *app_error.AppError)
+	RemoveBookmark(ctx context.Context, userID, messageID string) *app_error.AppError
+	GetBookmarks(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.BookmarksResponse, *app_error.AppError)
+	AddReaction(ctx context.Context, req chat_dto.ReactionRequest, userID, roomID, messageID string) (*chat_dto.MessageReactionResponse, *app_error.AppError)
+	RemoveReaction(ctx context.Context, req chat_dto.ReactionRequest, userID, roomID, messageID string) (*chat_dto.MessageReactionResponse, *app_error.AppError)
+	CreateGroup(ctx context.Context, req chat_dto.CreateGroupRequest, creatorID string) (*chat_dto.GroupResponse, *app_error.AppError)
+	RenameGroup(ctx context.Context, req chat_dto.UpdateGroupRequest, userID, roomID string) (*chat_dto.GroupResponse, *app_error.AppError)
+	GetGroupMembers(ctx context.Context, userID, roomID string) (*chat_dto.GroupMembersResponse, *app_error.AppError)
+	DeleteGroup(ctx context.Context, userID, roomID string) *app_error.AppError
+	ChangeMemberRole(ctx context.Context, req chat_dto.ChangeMemberRoleRequest, actorID, roomID, targetID string) (*chat_dto.MemberRoleChangedResponse, *app_error.AppError)
+	TransferOwnership(ctx context.Context, req chat_dto.TransferOwnershipRequest, ownerID, roomID string) (*chat_dto.OwnershipTransferredResponse, *app_error.AppError)
+	LeaveRoom(ctx context.Context, userID, roomID string) (*chat_dto.RoomLeftResponse, *app_error.AppError)
+	RemoveMember(ctx context.Context, actorID, roomID, targetID string) (*chat_dto.RoomLeftResponse, *app_error.AppError)
+	GetMembershipEvents(ctx context.Context, userID, roomID string, limit int) (*chat_dto.MembershipEventsResponse, *app_error.AppError)
+	InviteToGroup(ctx context.Context, req chat_dto.InviteMembersRequest, inviterID, roomID string) ([]chat_dto.InvitationResponse, *app_error.AppError)
+	GetPendingInvitations(ctx context.Context, userID string) ([]chat_dto.InvitationResponse, *app_error.AppError)
+	AcceptInvitation(ctx context.Context, userID, invitationID string) (*chat_dto.RoomJoinedResponse, *app_error.AppError)
+	DeclineInvitation(ctx context.Context, userID, invitationID string) *app_error.AppError
+	CreateInviteLink(ctx context.Context, req chat_dto.CreateInviteLinkRequest, userID, roomID string) (*chat_dto.InviteLinkResponse, *app_error.AppError)
+	JoinByInviteLink(ctx context.Context, userID, code string) (*chat_dto.RoomJoinedResponse, *app_error.AppError)
 }
@@ -0,0 +1,101 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/config"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+)
+
+const defaultDeleteForEveryoneWindow = time.Hour
+
+func (c *ChatService) DeleteMessage(ctx context.Context, userID, roomID, messageID, scope string) (*chat_dto.MessageDeletedResponse, *app_error.AppError) {
+	if scope == "" {
+		scope = entity.DeleteScopeMe
+	}
+	if scope != entity.DeleteScopeMe && scope != entity.DeleteScopeEveryone {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "scope must be either me or everyone", "scope")
+	}
+
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	if scope == entity.DeleteScopeEveryone {
+		if msg.IsDeleted {
+			return nil, app_error.NewAppError(http.StatusConflict, "message was already deleted", "deleted")
+		}
+
+		// senders can take back their own message for a while, afterwards (or for anyone else's) it takes a moderator
+		action := ActionPostMessage
+		if msg.SenderID != userID || time.Since(msg.CreatedAt) > deleteForEveryoneWindow() {
+			action = ActionDeleteAnyMessage
+		}
+		if _, err := c.authorize(members, userID, action); err != nil {
+			return nil, err
+		}
+	} else if c.findActiveMember(members, userID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	now := time.Now()
+	if err := c.ChatRepo.DeleteMessage(ctx, msg.ID, userID, scope, now); err != nil {
+		return nil, err
+	}
+
+	// a tombstone is not worth a pin slot
+	if scope == entity.DeleteScopeEveryone {
+		if _, err := c.ChatRepo.UnpinMessage(ctx, roomID, msg.ID.Hex()); err != nil {
+			log.Warn().Msgf("failed to unpin deleted message %s: %s", msg.ID.Hex(), err.Message)
+		}
+	}
+
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(roomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	// deleting for me only concerns the caller's own devices
+	recipients := []string{userID}
+	if scope == entity.DeleteScopeEveryone {
+		recipients = activeMemberIDs(members)
+	}
+
+	return &chat_dto.MessageDeletedResponse{
+		MessageID:  msg.ID.Hex(),
+		RoomID:     roomID,
+		Scope:      scope,
+		DeletedBy:  userID,
+		DeletedAt:  now,
+		Recipients: recipients,
+	}, nil
+}
+
+func deleteForEveryoneWindow() time.Duration {
+	if config.Conf == nil || config.Conf.CHAT.DeleteForEveryoneWindow <= 0 {
+		return defaultDeleteForEveryoneWindow
+	}
+
+	return config.Conf.CHAT.DeleteForEveryoneWindow
+}
+
+// isHiddenFor reports whether the user deleted the message for themselves
+func isHiddenFor(msg *entity.Message, userID string) bool {
+	return slices.Contains(msg.DeletedFor, userID)
+}
@@ -0,0 +1,159 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetDisappearingTimer changes how long new messages of the room live, existing messages keep their expiry.
+// Groups need the change settings role, in a private room both members may change it.
+func (c *ChatService) SetDisappearingTimer(ctx context.Context, req chat_dto.DisappearingTimerRequest, userID, roomID string) (*chat_dto.DisappearingTimerResponse, *app_error.AppError) {
+	ttl, ok := entity.DisappearingTimers[req.Timer]
+	if !ok {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "timer must be one of off, 1h, 24h or 7d", "timer")
+	}
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := ActionChangeSettings
+	if room.RT == entity.RoomTypePrivate {
+		action = ActionPostMessage
+	}
+	if _, err := c.authorize(members, userID, action); err != nil {
+		return nil, err
+	}
+
+	if err := c.ChatRepo.UpdateRoomMessageTTL(ctx, roomID, int(ttl.Seconds())); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.DisappearingTimerResponse{
+		RoomID:     roomID,
+		Timer:      req.Timer,
+		TTLSeconds: int(ttl.Seconds()),
+		UpdatedBy:  userID,
+		Recipients: activeMemberIDs(members),
+	}, nil
+}
+
+// SweepExpiredMessages removes up to limit expired messages and returns one deletion per message
+// for the worker to broadcast as message_deleted
+func (c *ChatService) SweepExpiredMessages(ctx context.Context, now time.Time, limit int) ([]*chat_dto.MessageDeletedResponse, *app_error.AppError) {
+	expired, err := c.ChatRepo.FindExpiredMessages(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(expired))
+	for _, msg := range expired {
+		ids = append(ids, msg.ID)
+	}
+	if err := c.ChatRepo.DeleteExpiredMessages(ctx, ids, now); err != nil {
+		return nil, err
+	}
+	c.releaseExpiredUploads(ctx, expired)
+
+	recipients := make(map[string][]string)
+	deletions := make([]*chat_dto.MessageDeletedResponse, 0, len(expired))
+	for _, msg := range expired {
+		roomRecipients, seen := recipients[msg.RoomID]
+		if !seen {
+			members, err := c.ChatRepo.FindRoomMembers(ctx, msg.RoomID)
+			if err != nil {
+				log.Error().Str("room_id", msg.RoomID).Str("error", err.Message).Msg("failed to load members for expired messages")
+			}
+			roomRecipients = activeMemberIDs(members)
+			recipients[msg.RoomID] = roomRecipients
+
+			utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, createMessageCacheKey(msg.RoomID))
+		}
+
+		if _, err := c.ChatRepo.UnpinMessage(ctx, msg.RoomID, msg.ID.Hex()); err != nil {
+			log.Error().Str("message_id", msg.ID.Hex()).Str("error", err.Message).Msg("failed to unpin expired message")
+		}
+
+		deletions = append(deletions, &chat_dto.MessageDeletedResponse{
+			MessageID:  msg.ID.Hex(),
+			RoomID:     msg.RoomID,
+			Scope:      entity.DeleteScopeExpired,
+			DeletedAt:  *msg.ExpiresAt,
+			Recipients: roomRecipients,
+		})
+	}
+
+	return deletions, nil
+}
+
+// releaseExpiredUploads deletes the files of expired messages so their signed URLs stop working, files a
+// forwarded copy still shows are kept. The messages are already gone, so a failure is only logged.
+func (c *ChatService) releaseExpiredUploads(ctx context.Context, expired []*entity.Message) {
+	var uploadIDs []string
+	for _, msg := range expired {
+		for _, attachment := range msg.Attachments {
+			if attachment.UploadID != "" {
+				uploadIDs = append(uploadIDs, attachment.UploadID)
+			}
+		}
+	}
+	if len(uploadIDs) == 0 {
+		return
+	}
+
+	referenced, err := c.ChatRepo.FindReferencedUploadIDs(ctx, uploadIDs)
+	if err != nil {
+		log.Error().Str("error", err.Message).Msg("failed to check uploads of expired messages")
+		return
+	}
+
+	released := make([]string, 0, len(uploadIDs))
+	for _, id := range uploadIDs {
+		if !referenced[id] {
+			released = append(released, id)
+		}
+	}
+	if len(released) == 0 {
+		return
+	}
+
+	uploads, err := c.ChatRepo.DeleteUploads(ctx, released)
+	if err != nil {
+		log.Error().Str("error", err.Message).Msg("failed to release uploads of expired messages")
+		return
+	}
+
+	for _, upload := range uploads {
+		c.deleteBlob(ctx, upload.StorageKey)
+		if upload.ThumbnailKey != "" {
+			c.deleteBlob(ctx, upload.ThumbnailKey)
+		}
+	}
+}
+
+// messageExpiry is when a message created at createdAt disappears, nil when the room timer is off
+func messageExpiry(room *entity.Room, createdAt time.Time) *time.Time {
+	if room == nil || room.MessageTTLSeconds <= 0 {
+		return nil
+	}
+
+	expiresAt := createdAt.Add(time.Duration(room.MessageTTLSeconds) * time.Second)
+	return &expiresAt
+}
+
+// isExpired reports whether the timer of the message ran out, cached pages may still hold such messages
+func isExpired(msg *entity.Message, now time.Time) bool {
+	return msg.ExpiresAt != nil && !msg.ExpiresAt.After(now)
+}
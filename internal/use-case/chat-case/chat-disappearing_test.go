@@ -0,0 +1,46 @@
+package chat_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestMessageExpiry(t *testing.T) {
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	if got := messageExpiry(&entity.Room{}, createdAt); got != nil {
+		t.Errorf("timer off: got %v, want nil", got)
+	}
+
+	room := &entity.Room{MessageTTLSeconds: int(entity.DisappearingTimers["24h"].Seconds())}
+	got := messageExpiry(room, createdAt)
+	if got == nil || !got.Equal(createdAt.Add(24*time.Hour)) {
+		t.Errorf("timer 24h: got %v, want %v", got, createdAt.Add(24*time.Hour))
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Second), now.Add(time.Second)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"no timer", nil, false},
+		{"expired", &past, true},
+		{"expires now", &now, true},
+		{"still running", &future, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isExpired(&entity.Message{ExpiresAt: tt.expiresAt}, now); got != tt.want {
+				t.Errorf("isExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/config"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+)
+
+const (
+	defaultEditWindow      = 15 * time.Minute
+	defaultMaxMessageEdits = 20
+
+	minEditWindow = time.Minute
+	maxEditWindow = 7 * 24 * time.Hour
+)
+
+// SetEditWindow changes how long messages of the room stay editable, it applies to existing messages too.
+// Groups need the change settings role, in a private room both members may change it.
+func (c *ChatService) SetEditWindow(ctx context.Context, req chat_dto.EditWindowRequest, userID, roomID string) (*chat_dto.EditWindowResponse, *app_error.AppError) {
+	windowSeconds, err := parseEditWindow(req.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := ActionChangeSettings
+	if room.RT == entity.RoomTypePrivate {
+		action = ActionPostMessage
+	}
+	if _, err := c.authorize(members, userID, action); err != nil {
+		return nil, err
+	}
+
+	if err := c.ChatRepo.UpdateRoomEditWindow(ctx, roomID, windowSeconds); err != nil {
+		return nil, err
+	}
+
+	room.EditWindowSeconds = windowSeconds
+
+	return &chat_dto.EditWindowResponse{
+		RoomID:        roomID,
+		Window:        req.Window,
+		WindowSeconds: int(editWindowOf(room).Seconds()),
+		UpdatedBy:     userID,
+		Recipients:    activeMemberIDs(members),
+	}, nil
+}
+
+// GetMessageHistory lists the revisions of a message to a member of its room
+func (c *ChatService) GetMessageHistory(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessageHistoryResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.findActiveMember(members, userID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	// deleting a message for everyone drops its history
+	if msg.IsDeleted || isHiddenFor(msg, userID) || isExpired(msg, time.Now()) {
+		return nil, app_error.NewAppError(http.StatusNotFound, "message not found", "not-found")
+	}
+
+	return &chat_dto.MessageHistoryResponse{
+		MessageID: msg.ID.Hex(),
+		RoomID:    msg.RoomID,
+		EditCount: len(msg.MessageEditHistory),
+		Revisions: messageRevisions(msg),
+	}, nil
+}
+
+// messageEdit is an edit stored by editMessage, the private and the room edit map it to their own response
+type messageEdit struct {
+	original *entity.Message
+	edited   *entity.Message // the message as stored by this edit
+	history  []*entity.MessageEditEntry
+	members  []*entity.RoomMember
+}
+
+// editMessage is the edit shared by private and room messages. Editing someone else's message takes a
+// moderator, the edit window of the room applies and the content is sanitized like a new message. The edit is
+// stored with its history entry under the edit cap and the cached page of the room is dropped.
+func (c *ChatService) editMessage(ctx context.Context, editorID, roomID, messageID, format, content string) (*messageEdit, *app_error.AppError) {
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	originalMsg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if originalMsg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	if originalMsg.IsDeleted {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "Deleted message cannot be edited", "deleted")
+	}
+
+	if originalMsg.Type == entity.MessageTypeSystem {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "System messages cannot be edited", "system")
+	}
+
+	if originalMsg.Type == entity.MessageTypePoll {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "Polls cannot be edited", "poll")
+	}
+
+	action := ActionPostMessage
+	if originalMsg.SenderID != editorID {
+		action = ActionEditAnyMessage
+	}
+	if _, err := c.authorize(members, editorID, action); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkEditWindow(room, originalMsg, now); err != nil {
+		return nil, err
+	}
+
+	updatedMsg, err := formatEdit(originalMsg, format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedMsg.Mentions = c.resolveMentions(ctx, updatedMsg.PlainText, editorID, activeMemberIDs(members))
+	updatedMsg.IsEdited = true
+	updatedMsg.UpdatedAt = &now
+
+	entry := &entity.MessageEditEntry{
+		MessageID:       originalMsg.ID,
+		OriginalContent: originalMsg.Content,
+		NewContent:      updatedMsg.Content,
+		EditedBy:        editorID,
+		EditedAt:        now,
+	}
+
+	// optimistic locking on the last update, the edit cap is checked in the same write
+	updated, err := c.ChatRepo.UpdateMessage(ctx, updatedMsg, entry, originalMsg.UpdatedAt, maxMessageEdits())
+	if err != nil {
+		return nil, err
+	}
+
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, createMessageCacheKey(roomID))
+
+	return &messageEdit{
+		original: originalMsg,
+		edited:   updatedMsg,
+		history:  updated.MessageEditHistory,
+		members:  members,
+	}, nil
+}
+
+// checkEditWindow rejects edits of messages older than the edit window of their room
+func checkEditWindow(room *entity.Room, msg *entity.Message, now time.Time) *app_error.AppError {
+	window := editWindowOf(room)
+	if window > 0 && now.Sub(msg.CreatedAt) > window {
+		return app_error.NewAppError(http.StatusForbidden, "Message edit time window expired", "time_expired")
+	}
+
+	return nil
+}
+
+// editWindowOf is how long messages of the room stay editable, 0 means no limit
+func editWindowOf(room *entity.Room) time.Duration {
+	if room != nil && room.EditWindowSeconds != nil {
+		return time.Duration(*room.EditWindowSeconds) * time.Second
+	}
+
+	if config.Conf == nil || config.Conf.CHAT.EditWindow <= 0 {
+		return defaultEditWindow
+	}
+
+	return config.Conf.CHAT.EditWindow
+}
+
+// parseEditWindow turns the requested window into the stored seconds, nil for the server default
+func parseEditWindow(window string) (*int, *app_error.AppError) {
+	switch window {
+	case entity.EditWindowDefault:
+		return nil, nil
+	case entity.EditWindowOff:
+		seconds := 0
+		return &seconds, nil
+	}
+
+	d, err := time.ParseDuration(window)
+	if err != nil || d < minEditWindow || d > maxEditWindow {
+		return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("window must be off, default or a duration between %s and %s", minEditWindow, maxEditWindow), "window")
+	}
+
+	seconds := int(d.Seconds())
+	return &seconds, nil
+}
+
+func maxMessageEdits() int {
+	if config.Conf == nil || config.Conf.CHAT.MaxMessageEdits <= 0 {
+		return defaultMaxMessageEdits
+	}
+
+	return config.Conf.CHAT.MaxMessageEdits
+}
+
+// messageRevisions rebuilds every content of a message from its edit history, the oldest first
+func messageRevisions(msg *entity.Message) []chat_dto.MessageRevision {
+	original := msg.Content
+	if len(msg.MessageEditHistory) > 0 {
+		original = msg.MessageEditHistory[0].OriginalContent
+	}
+
+	revisions := make([]chat_dto.MessageRevision, 0, len(msg.MessageEditHistory)+1)
+	revisions = append(revisions, chat_dto.MessageRevision{
+		Revision:  0,
+		Content:   original,
+		WrittenBy: msg.SenderID,
+		WrittenAt: msg.CreatedAt,
+	})
+
+	for i, entry := range msg.MessageEditHistory {
+		revisions = append(revisions, chat_dto.MessageRevision{
+			Revision:  i + 1,
+			Content:   entry.NewContent,
+			WrittenBy: entry.EditedBy,
+			WrittenAt: entry.EditedAt,
+		})
+	}
+
+	return revisions
+}
+
+// toReplyMessage maps the snapshot a reply keeps of the message it quotes, nil for other messages
+func toReplyMessage(replyTo *entity.ReplyTo) *chat_dto.ReplyMessage {
+	if replyTo == nil {
+		return nil
+	}
+
+	return &chat_dto.ReplyMessage{
+		RepliedMessageID: replyTo.MessageID.Hex(),
+		Content:          replyTo.Content,
+		SenderID:         replyTo.SenderID,
+	}
+}
+
+func toEditHistoryDTOs(entries []*entity.MessageEditEntry) []*chat_dto.MessageEditEntry {
+	history := make([]*chat_dto.MessageEditEntry, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, &chat_dto.MessageEditEntry{
+			MessageID:       entry.MessageID.Hex(),
+			OriginalContent: entry.OriginalContent,
+			NewContent:      entry.NewContent,
+			EditedBy:        entry.EditedBy,
+			EditedAt:        entry.EditedAt,
+		})
+	}
+
+	return history
+}
@@ -0,0 +1,118 @@
+package chat_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestParseEditWindow(t *testing.T) {
+	seconds, err := parseEditWindow(entity.EditWindowDefault)
+	if err != nil || seconds != nil {
+		t.Errorf("parseEditWindow(default) = %v, %v, want nil", seconds, err)
+	}
+
+	seconds, err = parseEditWindow(entity.EditWindowOff)
+	if err != nil || seconds == nil || *seconds != 0 {
+		t.Errorf("parseEditWindow(off) = %v, %v, want 0", seconds, err)
+	}
+
+	seconds, err = parseEditWindow("90m")
+	if err != nil || seconds == nil || *seconds != 5400 {
+		t.Errorf("parseEditWindow(90m) = %v, %v, want 5400", seconds, err)
+	}
+
+	for _, window := range []string{"soon", "30s", "169h", "-1h"} {
+		if _, err := parseEditWindow(window); err == nil {
+			t.Errorf("parseEditWindow(%q) accepted", window)
+		}
+	}
+}
+
+func TestCheckEditWindow(t *testing.T) {
+	now := time.Now()
+	msg := &entity.Message{CreatedAt: now.Add(-time.Hour)}
+
+	if err := checkEditWindow(&entity.Room{}, msg, now); err == nil {
+		t.Error("checkEditWindow() allowed an edit past the default window")
+	}
+
+	hours := int((2 * time.Hour).Seconds())
+	if err := checkEditWindow(&entity.Room{EditWindowSeconds: &hours}, msg, now); err != nil {
+		t.Errorf("checkEditWindow() rejected an edit inside the room window: %v", err.Message)
+	}
+
+	off := 0
+	if err := checkEditWindow(&entity.Room{EditWindowSeconds: &off}, &entity.Message{CreatedAt: now.AddDate(-1, 0, 0)}, now); err != nil {
+		t.Errorf("checkEditWindow() rejected an edit with the window off: %v", err.Message)
+	}
+}
+
+func TestMessageRevisions(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	edited := created.Add(time.Minute)
+	msg := &entity.Message{
+		SenderID:  "sender",
+		Content:   "third",
+		CreatedAt: created,
+		MessageEditHistory: []*entity.MessageEditEntry{
+			{OriginalContent: "first", NewContent: "second", EditedBy: "sender", EditedAt: edited},
+			{OriginalContent: "second", NewContent: "third", EditedBy: "sender", EditedAt: edited.Add(time.Minute)},
+		},
+	}
+
+	revisions := messageRevisions(msg)
+	if len(revisions) != 3 {
+		t.Fatalf("messageRevisions() returned %d revisions, want 3", len(revisions))
+	}
+	if revisions[0].Content != "first" || !revisions[0].WrittenAt.Equal(created) {
+		t.Errorf("messageRevisions()[0] = %+v, want the original", revisions[0])
+	}
+	if revisions[2].Content != "third" || revisions[2].Revision != 2 || !revisions[2].WrittenAt.Equal(edited.Add(time.Minute)) {
+		t.Errorf("messageRevisions()[2] = %+v, want the latest edit", revisions[2])
+	}
+
+	if revisions := messageRevisions(&entity.Message{Content: "only"}); len(revisions) != 1 || revisions[0].Content != "only" {
+		t.Errorf("messageRevisions() of an unedited message = %+v", revisions)
+	}
+}
+
+// TestEditedBy checks that an edit names whoever made it, mentions added by a moderator are sent in their name
+func TestEditedBy(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newFakeChatService(t)
+	room := repo.addRoom(entity.RoomTypeGroup, "owner", "author")
+
+	tests := []struct {
+		name   string
+		editor string
+	}{
+		{"author", "author"},
+		{"moderator", "owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := repo.addMessage(room, "author", "hello")
+			resp, err := c.UpdatePrivateMessage(ctx, chat_dto.UpdatePrivateMessageRequest{Content: "hello there"}, tt.editor, room.ID.String(), msg.ID.Hex())
+			if err != nil {
+				t.Fatalf("UpdatePrivateMessage() error = %v", err.Message)
+			}
+			if resp.SenderID != "author" || resp.EditedBy != tt.editor {
+				t.Errorf("UpdatePrivateMessage() sender %s edited by %s, want author edited by %s", resp.SenderID, resp.EditedBy, tt.editor)
+			}
+
+			msg = repo.addMessage(room, "author", "hello")
+			roomResp, err := c.UpdateRoomMessage(ctx, chat_dto.UpdateRoomMessageRequest{Content: "hello there"}, tt.editor, room.ID.String(), msg.ID.Hex())
+			if err != nil {
+				t.Fatalf("UpdateRoomMessage() error = %v", err.Message)
+			}
+			if roomResp.SenderID != "author" || roomResp.EditedBy != tt.editor {
+				t.Errorf("UpdateRoomMessage() sender %s edited by %s, want author edited by %s", roomResp.SenderID, roomResp.EditedBy, tt.editor)
+			}
+		})
+	}
+}
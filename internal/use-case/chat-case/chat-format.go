@@ -0,0 +1,63 @@
+package chat_service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/markdown"
+)
+
+// messageFormat defaults to plain, messages stored before formats existed have none
+func messageFormat(format string) string {
+	if format == "" {
+		return entity.MessageFormatPlain
+	}
+
+	return format
+}
+
+// formatContent sanitizes markdown and renders the plain text kept for search and notifications,
+// plain content is stored as written since clients never interpret it
+func formatContent(format, content string) (string, string) {
+	if format != entity.MessageFormatMarkdown {
+		return content, content
+	}
+
+	sanitized := markdown.Sanitize(content)
+	return sanitized, markdown.PlainText(sanitized)
+}
+
+// plainTextOf is the plain text of a stored message, falling back to the content when it has none
+func plainTextOf(msg *entity.Message) string {
+	if msg.PlainText != "" {
+		return msg.PlainText
+	}
+
+	return msg.Content
+}
+
+// formatEdit sanitizes the new content of an edit into the updated message, the format stays the same unless
+// the edit sets one. Rejects edits left empty by the sanitizer and edits that change nothing.
+func formatEdit(original *entity.Message, format, content string) (*entity.Message, *app_error.AppError) {
+	if format == "" {
+		format = messageFormat(original.Format)
+	}
+
+	content, plainText := formatContent(format, content)
+	if strings.TrimSpace(content) == "" {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "content is empty after removing unsupported markup", "content")
+	}
+
+	if strings.TrimSpace(content) == strings.TrimSpace(original.Content) && format == messageFormat(original.Format) {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "New content must be different", "content")
+	}
+
+	return &entity.Message{
+		ID:        original.ID,
+		Content:   content,
+		Format:    format,
+		PlainText: plainText,
+	}, nil
+}
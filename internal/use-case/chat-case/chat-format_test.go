@@ -0,0 +1,45 @@
+package chat_service
+
+import (
+	"testing"
+
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestFormatContent(t *testing.T) {
+	content, plainText := formatContent(entity.MessageFormatMarkdown, "**hi** <img src=x onerror=alert(1)>[there](javascript:alert(1))")
+	if content != "**hi** there" || plainText != "hi there" {
+		t.Errorf("formatContent(markdown) = %q, %q", content, plainText)
+	}
+
+	content, plainText = formatContent(entity.MessageFormatPlain, "**hi** <b>")
+	if content != "**hi** <b>" || plainText != content {
+		t.Errorf("formatContent(plain) = %q, %q", content, plainText)
+	}
+}
+
+func TestFormatEdit(t *testing.T) {
+	original := &entity.Message{Content: "**hi**", Format: entity.MessageFormatMarkdown, PlainText: "hi"}
+
+	updated, err := formatEdit(original, "", "**hi** <script>x</script>there")
+	if err != nil {
+		t.Fatalf("formatEdit() error = %v", err.Message)
+	}
+	if updated.Format != entity.MessageFormatMarkdown || updated.Content != "**hi** xthere" || updated.PlainText != "hi xthere" {
+		t.Errorf("formatEdit() = %+v", updated)
+	}
+
+	if _, err := formatEdit(original, "", "<b></b>"); err == nil {
+		t.Error("formatEdit() accepted content the sanitizer emptied")
+	}
+
+	if _, err := formatEdit(original, "", "**hi**<br>"); err == nil {
+		t.Error("formatEdit() accepted an edit that changes nothing")
+	}
+
+	// switching the format alone is an edit
+	updated, err = formatEdit(original, entity.MessageFormatPlain, "**hi**")
+	if err != nil || updated.PlainText != "**hi**" {
+		t.Errorf("formatEdit(plain) = %+v, %v", updated, err)
+	}
+}
@@ -0,0 +1,178 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type forwardTarget struct {
+	room    *entity.Room
+	members []*entity.RoomMember
+}
+
+// ForwardMessages copies messages of the source room into every target room. All sources and
+// targets are checked before anything is written, so a forward is either complete or not done at all.
+func (c *ChatService) ForwardMessages(ctx context.Context, req chat_dto.ForwardMessagesRequest, senderID, roomID string) (*chat_dto.ForwardMessagesResponse, *app_error.AppError) {
+	// copies carry their own client ids, a retry the claim missed finds them one by one instead of by req.ClientMsgID
+	resp, replayed, err := sendOnce(ctx, c, senderID, req.ClientMsgID, func() (*chat_dto.ForwardMessagesResponse, *app_error.AppError) {
+		return c.forwardMessages(ctx, req, senderID, roomID)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Replayed = replayed
+	return resp, nil
+}
+
+func (c *ChatService) forwardMessages(ctx context.Context, req chat_dto.ForwardMessagesRequest, senderID, roomID string) (*chat_dto.ForwardMessagesResponse, *app_error.AppError) {
+	_, sourceMembers, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.findActiveMember(sourceMembers, senderID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	sources := make([]*entity.Message, 0, len(req.MessageIDs))
+	for _, messageID := range req.MessageIDs {
+		msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+		if err != nil {
+			return nil, err
+		}
+
+		if msg.RoomID != roomID {
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("message %s does not belong to this room", messageID), "message_ids")
+		}
+
+		if msg.IsDeleted || isHiddenFor(msg, senderID) {
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("message %s was deleted", messageID), "message_ids")
+		}
+
+		// the votes of a poll belong to the members of its room
+		if msg.Type == entity.MessageTypeSystem {
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("message %s is a room event, it cannot be forwarded", messageID), "message_ids")
+		}
+
+		if msg.Type == entity.MessageTypePoll {
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("message %s is a poll, polls cannot be forwarded", messageID), "message_ids")
+		}
+
+		sources = append(sources, msg)
+	}
+
+	targets := make([]forwardTarget, 0, len(req.TargetRoomIDs))
+	for _, targetRoomID := range req.TargetRoomIDs {
+		room, members, err := c.findActiveRoomWithMembers(ctx, targetRoomID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, forwardTarget{room: room, members: members})
+	}
+
+	forwarded := make([]*chat_dto.RoomMessageResponse, 0, len(sources)*len(targets))
+	for _, target := range targets {
+		targetRoomID := target.room.ID.String()
+
+		var lastMsgID primitive.ObjectID
+		created := 0 // copies stored by an earlier attempt were already counted as unread
+		for _, source := range sources {
+			msg := &entity.Message{
+				ID:            primitive.NewObjectID(),
+				RoomID:        targetRoomID,
+				SenderID:      senderID,
+				ClientMsgID:   forwardClientMsgID(req.ClientMsgID, len(forwarded)),
+				ReceiverID:    privateReceiverID(target.room, target.members, senderID),
+				Content:       source.Content,
+				Format:        source.Format,
+				PlainText:     source.PlainText,
+				Attachments:   source.Attachments, // attachments are shared, only the references are copied
+				LinkPreviews:  source.LinkPreviews,
+				ForwardedFrom: forwardedFromOf(source),
+				IsEdited:      false,
+				CreatedAt:     time.Now(),
+			}
+			msg.ExpiresAt = messageExpiry(target.room, msg.CreatedAt)
+
+			msgID, err := c.ChatRepo.CreateMessage(ctx, msg)
+			if err == nil {
+				created++
+			}
+			if err != nil && err.Code == http.StatusConflict && msg.ClientMsgID != "" {
+				// stored by an earlier attempt of this forward
+				msg, err = c.ChatRepo.FindMessageByClientMsgID(ctx, senderID, msg.ClientMsgID)
+				if err == nil {
+					msgID = msg.ID
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			lastMsgID = msgID
+
+			forwarded = append(forwarded, toForwardedResponse(msg, activeMemberIDs(target.members)))
+		}
+
+		if err := c.ChatRepo.UpdateRoomMetadata(ctx, targetRoomID, senderID, lastMsgID); err != nil {
+			return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update metadata message: %v", err), "update-room-meta")
+		}
+		forwarded[len(forwarded)-1].Unread = c.bumpUnread(ctx, targetRoomID, senderID, created)
+
+		// invalidate cache key
+		cacheKey := createMessageCacheKey(targetRoomID)
+		utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+	}
+
+	return &chat_dto.ForwardMessagesResponse{ClientMsgID: req.ClientMsgID, Messages: forwarded}, nil
+}
+
+// forwardedFromOf references the original message, forwarding a forwarded copy keeps pointing at the original
+func forwardedFromOf(source *entity.Message) *entity.ForwardedFrom {
+	if source.ForwardedFrom != nil {
+		return source.ForwardedFrom
+	}
+
+	return &entity.ForwardedFrom{
+		MessageID: source.ID,
+		RoomID:    source.RoomID,
+		SenderID:  source.SenderID,
+	}
+}
+
+func toForwardedResponse(msg *entity.Message, recipients []string) *chat_dto.RoomMessageResponse {
+	return &chat_dto.RoomMessageResponse{
+		MessageID:   msg.ID.Hex(),
+		ClientMsgID: msg.ClientMsgID,
+		RoomID:      msg.RoomID,
+		SenderID:    msg.SenderID,
+		ReceiverID:  msg.ReceiverID,
+		MessageType: messageType(msg.Type),
+		Content:     msg.Content,
+		Format:      messageFormat(msg.Format),
+		PlainText:   plainTextOf(msg),
+		ForwardedFrom: &chat_dto.ForwardedFrom{
+			MessageID: msg.ForwardedFrom.MessageID.Hex(),
+			RoomID:    msg.ForwardedFrom.RoomID,
+			SenderID:  msg.ForwardedFrom.SenderID,
+		},
+		Attachments:  toAttachmentDTOs(msg.Attachments),
+		LinkPreviews: toLinkPreviewDTOs(msg.LinkPreviews),
+		CreatedAt:    msg.CreatedAt,
+		ExpiresAt:    msg.ExpiresAt,
+		Recipients:   recipients,
+	}
+}
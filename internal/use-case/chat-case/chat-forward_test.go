@@ -0,0 +1,111 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestForwardMessages(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newFakeChatService(t)
+
+	source := repo.addRoom(entity.RoomTypeGroup, "u1", "u2")
+	first := repo.addMessage(source, "u2", "first")
+	second := repo.addMessage(source, "u1", "second")
+	group := repo.addRoom(entity.RoomTypeGroup, "u1", "u3")
+	private := repo.addRoom(entity.RoomTypePrivate, "u1", "u4")
+
+	req := chat_dto.ForwardMessagesRequest{
+		MessageIDs:    []string{first.ID.Hex(), second.ID.Hex()},
+		TargetRoomIDs: []string{group.ID.String(), private.ID.String()},
+		ClientMsgID:   "fw",
+	}
+	resp, err := c.ForwardMessages(ctx, req, "u1", source.ID.String())
+	if err != nil {
+		t.Fatalf("ForwardMessages() error = %v", err.Message)
+	}
+	if len(resp.Messages) != 4 {
+		t.Fatalf("ForwardMessages() returned %d copies, want 4", len(resp.Messages))
+	}
+
+	// copies are numbered across all targets in the order they are written
+	for i, copied := range resp.Messages {
+		if want := fmt.Sprintf("fw:%d", i); copied.ClientMsgID != want {
+			t.Errorf("copy %d client_msg_id = %q, want %q", i, copied.ClientMsgID, want)
+		}
+		if copied.ForwardedFrom.RoomID != source.ID.String() {
+			t.Errorf("copy %d forwarded from room %s, want %s", i, copied.ForwardedFrom.RoomID, source.ID)
+		}
+	}
+	if resp.Messages[0].RoomID != group.ID.String() || resp.Messages[0].ReceiverID != "" {
+		t.Errorf("group copy = room %s receiver %q", resp.Messages[0].RoomID, resp.Messages[0].ReceiverID)
+	}
+	if resp.Messages[2].RoomID != private.ID.String() || resp.Messages[2].ReceiverID != "u4" {
+		t.Errorf("private copy = room %s receiver %q", resp.Messages[2].RoomID, resp.Messages[2].ReceiverID)
+	}
+
+	// a retry whose claim got lost finds the copies by their suffixed ids instead of storing them again
+	retried, err := c.forwardMessages(ctx, req, "u1", source.ID.String())
+	if err != nil {
+		t.Fatalf("retried forward error = %v", err.Message)
+	}
+	for i, copied := range retried.Messages {
+		if copied.MessageID != resp.Messages[i].MessageID {
+			t.Errorf("retried copy %d = %s, want %s", i, copied.MessageID, resp.Messages[i].MessageID)
+		}
+	}
+	if n := repo.roomMessages(group.ID.String()) + repo.roomMessages(private.ID.String()); n != 4 {
+		t.Errorf("targets hold %d messages after the retry, want 4", n)
+	}
+}
+
+func TestForwardMessagesRejected(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newFakeChatService(t)
+
+	source := repo.addRoom(entity.RoomTypeGroup, "u1", "u2")
+	live := repo.addMessage(source, "u2", "live")
+	deleted := repo.addMessage(source, "u2", "deleted")
+	deleted.IsDeleted = true
+	hidden := repo.addMessage(source, "u2", "deleted for u1")
+	hidden.DeletedFor = []string{"u1"}
+	expired := repo.addMessage(source, "u2", "expired")
+	expiredAt := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &expiredAt
+
+	member := repo.addRoom(entity.RoomTypeGroup, "u1", "u3")
+	notMember := repo.addRoom(entity.RoomTypeGroup, "u3", "u4")
+
+	tests := []struct {
+		name       string
+		messageIDs []string
+		targets    []string
+		wantCode   int
+	}{
+		{"member of only some targets", []string{live.ID.Hex()}, []string{member.ID.String(), notMember.ID.String()}, http.StatusForbidden},
+		{"deleted for everyone", []string{live.ID.Hex(), deleted.ID.Hex()}, []string{member.ID.String()}, http.StatusBadRequest},
+		{"deleted for the sender", []string{live.ID.Hex(), hidden.ID.Hex()}, []string{member.ID.String()}, http.StatusBadRequest},
+		{"expired", []string{live.ID.Hex(), expired.ID.Hex()}, []string{member.ID.String()}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := chat_dto.ForwardMessagesRequest{MessageIDs: tt.messageIDs, TargetRoomIDs: tt.targets}
+			_, err := c.ForwardMessages(ctx, req, "u1", source.ID.String())
+			if err == nil || err.Code != tt.wantCode {
+				t.Errorf("ForwardMessages() error = %v, want code %d", err, tt.wantCode)
+			}
+
+			// nothing is written unless every source and target passed
+			if n := repo.roomMessages(member.ID.String()); n != 0 {
+				t.Errorf("target holds %d messages after a rejected forward", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+)
+
+func (c *ChatService) CreateGroup(ctx context.Context, req chat_dto.CreateGroupRequest, creatorID string) (*chat_dto.GroupResponse, *app_error.AppError) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "group name cannot be empty", "name")
+	}
+
+	// dedupe member list, creator is added by the repo as owner
+	seen := map[string]bool{creatorID: true}
+	memberIDs := make([]string, 0, len(req.MemberIDs))
+	for _, id := range req.MemberIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		memberIDs = append(memberIDs, id)
+	}
+
+	if len(memberIDs) == 0 {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "group needs at least one member besides the creator", "member_ids")
+	}
+
+	room, members, err := c.ChatRepo.CreateGroupRoom(ctx, creatorID, name, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.GroupResponse{
+		RoomID:    room.ID.String(),
+		Name:      room.Name,
+		CreatedBy: room.CreatedBy,
+		Members:   toGroupMemberResponses(members),
+		CreatedAt: room.CreatedAt,
+	}, nil
+}
+
+func (c *ChatService) RenameGroup(ctx context.Context, req chat_dto.UpdateGroupRequest, userID, roomID string) (*chat_dto.GroupResponse, *app_error.AppError) {
+	room, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, userID, ActionChangeSettings); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "group name cannot be empty", "name")
+	}
+
+	if err := c.ChatRepo.UpdateRoomName(ctx, roomID, name); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.GroupResponse{
+		RoomID:    room.ID.String(),
+		Name:      name,
+		CreatedBy: room.CreatedBy,
+		Members:   toGroupMemberResponses(activeMembers(members)),
+		CreatedAt: room.CreatedAt,
+	}, nil
+}
+
+func (c *ChatService) GetGroupMembers(ctx context.Context, userID, roomID string) (*chat_dto.GroupMembersResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.isUserMemberOfRoom(members, userID) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this group", "forbidden")
+	}
+
+	return &chat_dto.GroupMembersResponse{
+		RoomID:  roomID,
+		Members: toGroupMemberResponses(activeMembers(members)),
+	}, nil
+}
+
+func (c *ChatService) DeleteGroup(ctx context.Context, userID, roomID string) *app_error.AppError {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.authorize(members, userID, ActionDeleteRoom); err != nil {
+		return err
+	}
+
+	if err := c.ChatRepo.SoftDeleteRoom(ctx, roomID); err != nil {
+		return err
+	}
+
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(roomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	return nil
+}
+
+// findGroupWithMembers loads a group room that is not soft deleted, along with its member rows
+func (c *ChatService) findGroupWithMembers(ctx context.Context, roomID string) (*entity.Room, []*entity.RoomMember, *app_error.AppError) {
+	room, err := c.ChatRepo.FindRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if room.DeletedAt != nil {
+		return nil, nil, app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
+	}
+
+	if room.RT != entity.RoomTypeGroup {
+		return nil, nil, app_error.NewAppError(http.StatusBadRequest, "room is not a group", "invalid-room")
+	}
+
+	members, err := c.ChatRepo.FindRoomMembers(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return room, members, nil
+}
+
+func (c *ChatService) findActiveMember(members []*entity.RoomMember, userID string) *entity.RoomMember {
+	for _, member := range members {
+		if member.UserID == userID && member.LeftAt == nil {
+			return member
+		}
+	}
+
+	return nil
+}
+
+func activeMembers(members []*entity.RoomMember) []*entity.RoomMember {
+	active := make([]*entity.RoomMember, 0, len(members))
+	for _, member := range members {
+		if member.LeftAt == nil {
+			active = append(active, member)
+		}
+	}
+
+	return active
+}
+
+func toGroupMemberResponses(members []*entity.RoomMember) []chat_dto.GroupMemberResponse {
+	resp := make([]chat_dto.GroupMemberResponse, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, chat_dto.GroupMemberResponse{
+			UserID:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+
+	return resp
+}
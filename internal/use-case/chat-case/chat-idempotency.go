@@ -0,0 +1,80 @@
+package chat_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+const (
+	// how long a retry gets the stored response, later retries are still caught by the unique index
+	clientMessageTTL = 24 * time.Hour
+	// a send holding the claim longer than this is taken as crashed, the next retry may run it again
+	clientMessageClaimTTL = 30 * time.Second
+)
+
+// sendOnce runs send at most once per sender and client message id. A retry gets the response of the first send
+// back with replayed set. The claim lives in Redis, when Redis lost it the message is looked up by its client id
+// and rebuilt with replay, a nil replay skips the lookup.
+func sendOnce[T any](ctx context.Context, c *ChatService, senderID, clientMsgID string, send func() (*T, *app_error.AppError), replay func(*entity.Message) *T) (resp *T, replayed bool, appErr *app_error.AppError) {
+	if clientMsgID == "" {
+		resp, err := send()
+		return resp, false, err
+	}
+
+	stored, claimed, err := c.ChatRepo.ClaimClientMessage(ctx, senderID, clientMsgID, clientMessageClaimTTL)
+	switch {
+	case err != nil:
+		// the unique index still keeps a retry from being stored twice
+		log.Warn().Str("client_msg_id", clientMsgID).Msg("client message dedupe unavailable, falling back to the message index")
+	case !claimed && stored == "":
+		return nil, false, app_error.NewAppError(http.StatusConflict, "a message with this client_msg_id is still being sent", "client_msg_id")
+	case !claimed:
+		var cached T
+		if err := json.Unmarshal([]byte(stored), &cached); err == nil {
+			return &cached, true, nil
+		}
+	}
+
+	defer func() {
+		if appErr != nil {
+			if claimed {
+				c.ChatRepo.ReleaseClientMessage(ctx, senderID, clientMsgID)
+			}
+			return
+		}
+
+		if data, err := json.Marshal(resp); err == nil {
+			c.ChatRepo.StoreClientMessage(ctx, senderID, clientMsgID, data, clientMessageTTL)
+		}
+	}()
+
+	if replay != nil {
+		existing, err := c.ChatRepo.FindMessageByClientMsgID(ctx, senderID, clientMsgID)
+		if err != nil && err.Code != http.StatusNotFound {
+			return nil, false, err
+		}
+		if existing != nil {
+			return replay(existing), true, nil
+		}
+	}
+
+	resp, appErr = send()
+	return resp, false, appErr
+}
+
+// forwardClientMsgID is the client id of the n-th copy of a forward, so a retried forward only adds the copies
+// that are still missing
+func forwardClientMsgID(clientMsgID string, n int) string {
+	if clientMsgID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", clientMsgID, n)
+}
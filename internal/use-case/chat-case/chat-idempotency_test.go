@@ -0,0 +1,84 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	chat_repo "github.com/xenn00/chat-system/internal/repo/chat"
+	"github.com/xenn00/chat-system/state"
+)
+
+func TestSendOnce(t *testing.T) {
+	mockRedis := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mockRedis.Addr()})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	appState := &state.AppState{Ctx: ctx, Redis: rdb}
+	c := &ChatService{AppState: appState, ChatRepo: chat_repo.NewChatRepo(appState)}
+
+	sends := 0
+	send := func() (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+		sends++
+		return &chat_dto.RoomMessageResponse{MessageID: "m1", ClientMsgID: "c1", Recipients: []string{"u2"}}, nil
+	}
+
+	for i := range 2 {
+		resp, replayed, err := sendOnce(ctx, c, "u1", "c1", send, nil)
+		if err != nil {
+			t.Fatalf("sendOnce() error = %v", err.Message)
+		}
+		if resp.MessageID != "m1" || resp.ClientMsgID != "c1" || replayed != (i == 1) {
+			t.Errorf("sendOnce() attempt %d = %+v, replayed %v", i, resp, replayed)
+		}
+	}
+	if sends != 1 {
+		t.Errorf("send ran %d times, want 1", sends)
+	}
+
+	// another sender may use the same client id
+	if _, replayed, _ := sendOnce(ctx, c, "u2", "c1", send, nil); replayed || sends != 2 {
+		t.Errorf("sendOnce() replayed the send of another sender")
+	}
+
+	// a failed send gives the id back for the retry
+	failing := func() (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "nope", "content")
+	}
+	if _, _, err := sendOnce(ctx, c, "u1", "c2", failing, nil); err == nil {
+		t.Fatal("sendOnce() swallowed the send error")
+	}
+	if _, replayed, err := sendOnce(ctx, c, "u1", "c2", send, nil); err != nil || replayed {
+		t.Errorf("sendOnce() after a failed send = replayed %v, err %v", replayed, err)
+	}
+
+	// a send still holding the claim is not run twice
+	if _, claimed, _ := c.ChatRepo.ClaimClientMessage(ctx, "u1", "c3", clientMessageClaimTTL); !claimed {
+		t.Fatal("ClaimClientMessage() did not claim a new id")
+	}
+	if _, _, err := sendOnce(ctx, c, "u1", "c3", send, nil); err == nil || err.Code != http.StatusConflict {
+		t.Errorf("sendOnce() during a running send = %v, want a conflict", err)
+	}
+
+	// without a client id every call sends
+	before := sends
+	sendOnce(ctx, c, "u1", "", send, nil)
+	sendOnce(ctx, c, "u1", "", send, nil)
+	if sends != before+2 {
+		t.Errorf("sendOnce() deduplicated sends without a client id")
+	}
+}
+
+func TestForwardClientMsgID(t *testing.T) {
+	if got := forwardClientMsgID("abc", 3); got != "abc:3" {
+		t.Errorf("forwardClientMsgID() = %q, want abc:3", got)
+	}
+	if got := forwardClientMsgID("", 3); got != "" {
+		t.Errorf("forwardClientMsgID() without an id = %q, want empty", got)
+	}
+}
@@ -0,0 +1,258 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	chat_repo "github.com/xenn00/chat-system/internal/repo/chat"
+	"github.com/xenn00/chat-system/internal/utils"
+)
+
+const (
+	defaultInboxLimit = 20
+	// edits and deletions of a last message do not drop the cache, they show up once it expires
+	inboxCacheTTL      = time.Minute
+	inboxPreviewLength = 100
+)
+
+// GetInbox lists the rooms of the caller, latest activity first. Only the first page with the default size is
+// cached, a new message in any of the rooms drops the cache.
+func (c *ChatService) GetInbox(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.InboxResponse, *app_error.AppError) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultInboxLimit
+	}
+
+	// the cursor is the next_cursor of the previous page
+	var before *entity.InboxCursor
+	if req.BeforeID != nil {
+		cursor, err := parseInboxCursor(*req.BeforeID)
+		if err != nil {
+			return nil, err
+		}
+		before = cursor
+	}
+
+	cacheable := before == nil && limit == defaultInboxLimit
+	cacheKey := chat_repo.InboxCacheKey(userID)
+
+	if cacheable {
+		cached, err := utils.GetCacheData[chat_dto.InboxResponse](c.AppState.Ctx, c.AppState.Redis, cacheKey)
+		if err != nil {
+			log.Warn().Msgf("cache miss, '%s'", cacheKey)
+		}
+		if cached != nil {
+			return cached, nil
+		}
+	}
+
+	entries, err := c.ChatRepo.FindInbox(ctx, userID, limit, before)
+	if err != nil {
+		return nil, err
+	}
+
+	roomIDs := make([]string, 0, len(entries))
+	privateRoomIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		roomIDs = append(roomIDs, entry.RoomID)
+		if entry.RoomType == entity.RoomTypePrivate {
+			privateRoomIDs = append(privateRoomIDs, entry.RoomID)
+		}
+	}
+
+	lastMessages, err := c.ChatRepo.FindLastMessages(ctx, roomIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := c.privateParticipants(ctx, privateRoomIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the live counters are in Redis, room_members only has them as of the last reconcile
+	unread, err := c.ChatRepo.FindUnreadCounts(ctx, userID, roomIDs)
+	if err != nil {
+		log.Warn().Str("user_id", userID).Msgf("unread counters unavailable, '%s'", err.Message)
+		unread = map[string]int64{}
+	}
+
+	rooms := make([]chat_dto.InboxRoom, 0, len(entries))
+	for _, entry := range entries {
+		room := chat_dto.InboxRoom{
+			RoomID:         entry.RoomID,
+			RoomType:       entry.RoomType,
+			Participant:    participants[entry.RoomID],
+			UnreadCount:    entry.UnreadCount,
+			IsMuted:        entry.IsMuted,
+			IsArchived:     entry.IsArchived,
+			LastActivityAt: entry.LastActivityAt,
+		}
+		if count, ok := unread[entry.RoomID]; ok {
+			room.UnreadCount = count
+		}
+		if entry.RoomType == entity.RoomTypeGroup {
+			room.Name = entry.RoomName
+		}
+		if msg, ok := lastMessages[entry.RoomID]; ok {
+			room.LastMessage = toLastMessagePreview(msg)
+		}
+
+		rooms = append(rooms, room)
+	}
+
+	var nextCursor *string
+	if len(entries) > 0 {
+		cursor := inboxCursorOf(entries[len(entries)-1])
+		nextCursor = &cursor
+	}
+
+	resp := &chat_dto.InboxResponse{
+		Rooms:      rooms,
+		NextCursor: nextCursor,
+		HasMore:    len(entries) == limit,
+	}
+
+	if cacheable {
+		utils.SetCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey, resp, inboxCacheTTL)
+	}
+
+	return resp, nil
+}
+
+// SetInboxSettings mutes or archives a room for the caller only, flags missing from the request are kept
+func (c *ChatService) SetInboxSettings(ctx context.Context, req chat_dto.InboxSettingsRequest, userID, roomID string) (*chat_dto.InboxSettingsResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	member := c.findActiveMember(members, userID)
+	if member == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	if err := c.ChatRepo.UpdateInboxFlags(ctx, roomID, userID, req.Muted, req.Archived); err != nil {
+		return nil, err
+	}
+
+	resp := &chat_dto.InboxSettingsResponse{
+		RoomID:     roomID,
+		IsMuted:    member.IsMuted,
+		IsArchived: member.IsArchived,
+	}
+	if req.Muted != nil {
+		resp.IsMuted = *req.Muted
+	}
+	if req.Archived != nil {
+		resp.IsArchived = *req.Archived
+	}
+
+	return resp, nil
+}
+
+// privateParticipants returns the profile of the other member of every private room, keyed by room ID
+func (c *ChatService) privateParticipants(ctx context.Context, roomIDs []string, userID string) (map[string]*chat_dto.UserProfile, *app_error.AppError) {
+	participants := make(map[string]*chat_dto.UserProfile, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return participants, nil
+	}
+
+	members, err := c.ChatRepo.FindMembersOfRooms(ctx, roomIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	otherIDs := make(map[string]string, len(roomIDs))
+	userIDs := make([]string, 0, len(roomIDs))
+	for _, member := range members {
+		if member.UserID == userID {
+			continue
+		}
+		otherIDs[member.RoomID] = member.UserID
+		userIDs = append(userIDs, member.UserID)
+	}
+
+	users, err := c.UserRepo.FindUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*chat_dto.UserProfile, len(users))
+	for _, user := range users {
+		profiles[user.ID] = &chat_dto.UserProfile{
+			UserID:   user.ID,
+			Username: user.Username,
+			IsActive: user.IsActive,
+		}
+	}
+
+	for roomID, otherID := range otherIDs {
+		if profile, ok := profiles[otherID]; ok {
+			participants[roomID] = profile
+		}
+	}
+
+	return participants, nil
+}
+
+func toLastMessagePreview(msg *entity.Message) *chat_dto.LastMessagePreview {
+	return &chat_dto.LastMessagePreview{
+		MessageID:       msg.ID.Hex(),
+		SenderID:        msg.SenderID,
+		MessageType:     messageType(msg.Type),
+		Preview:         shortenPreview(plainTextOf(msg), inboxPreviewLength),
+		AttachmentCount: len(msg.Attachments),
+		IsDeleted:       msg.IsDeleted,
+		CreatedAt:       msg.CreatedAt,
+	}
+}
+
+// shortenPreview cuts text down to maxRunes, whitespace runs become single spaces so a preview stays on one line
+func shortenPreview(text string, maxRunes int) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+
+	return strings.TrimSpace(string(runes[:maxRunes-1])) + "…"
+}
+
+// inboxCursorOf encodes the position of an inbox entry as "<last activity in unix microseconds>_<room id>"
+func inboxCursorOf(entry *entity.InboxEntry) string {
+	return fmt.Sprintf("%d_%s", entry.LastActivityAt.UnixMicro(), entry.RoomID)
+}
+
+func parseInboxCursor(cursor string) (*entity.InboxCursor, *app_error.AppError) {
+	invalid := app_error.NewAppError(http.StatusBadRequest, "before_id must be the next_cursor of the previous page", "before-id")
+
+	rawTime, roomID, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, invalid
+	}
+
+	micros, err := strconv.ParseInt(rawTime, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	if _, err := uuid.Parse(roomID); err != nil {
+		return nil, invalid
+	}
+
+	return &entity.InboxCursor{
+		LastActivityAt: time.UnixMicro(micros),
+		RoomID:         roomID,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package chat_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestInboxCursor(t *testing.T) {
+	entry := &entity.InboxEntry{
+		RoomMember:     entity.RoomMember{RoomID: "3f0c9a2e-7d1b-4c5e-9a8f-1b2c3d4e5f60"},
+		LastActivityAt: time.Date(2026, 10, 17, 9, 30, 0, 123456000, time.UTC),
+	}
+
+	cursor, err := parseInboxCursor(inboxCursorOf(entry))
+	if err != nil {
+		t.Fatalf("parseInboxCursor() error = %v", err.Message)
+	}
+	if cursor.RoomID != entry.RoomID || !cursor.LastActivityAt.Equal(entry.LastActivityAt) {
+		t.Errorf("parseInboxCursor() = %+v, want the entry position back", cursor)
+	}
+
+	for _, invalid := range []string{"", "123", "abc_3f0c9a2e-7d1b-4c5e-9a8f-1b2c3d4e5f60", "123_not-a-room"} {
+		if _, err := parseInboxCursor(invalid); err == nil {
+			t.Errorf("parseInboxCursor(%q) accepted", invalid)
+		}
+	}
+}
+
+func TestShortenPreview(t *testing.T) {
+	if got := shortenPreview("  hello\n\tthere  ", 100); got != "hello there" {
+		t.Errorf("shortenPreview() = %q, want the whitespace collapsed", got)
+	}
+
+	got := shortenPreview(strings.Repeat("é", 120), 100)
+	if len([]rune(got)) != 100 || !strings.HasSuffix(got, "…") {
+		t.Errorf("shortenPreview() = %q (%d runes), want 100 runes ending in an ellipsis", got, len([]rune(got)))
+	}
+}
@@ -0,0 +1,204 @@
+package chat_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+func (c *ChatService) InviteToGroup(ctx context.Context, req chat_dto.InviteMembersRequest, inviterID, roomID string) ([]chat_dto.InvitationResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, inviterID, ActionInviteMember); err != nil {
+		return nil, err
+	}
+
+	// skip users that are already active members and duplicated ids
+	seen := make(map[string]bool, len(req.UserIDs))
+	inviteeIDs := make([]string, 0, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if seen[id] || c.isUserMemberOfRoom(members, id) {
+			continue
+		}
+		seen[id] = true
+		inviteeIDs = append(inviteeIDs, id)
+	}
+
+	if len(inviteeIDs) == 0 {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "all invited users are already members of this group", "user_ids")
+	}
+
+	invitations, err := c.ChatRepo.CreateInvitations(ctx, roomID, inviterID, inviteeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvitationResponses(invitations), nil
+}
+
+func (c *ChatService) GetPendingInvitations(ctx context.Context, userID string) ([]chat_dto.InvitationResponse, *app_error.AppError) {
+	invitations, err := c.ChatRepo.FindPendingInvitationsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvitationResponses(invitations), nil
+}
+
+func (c *ChatService) AcceptInvitation(ctx context.Context, userID, invitationID string) (*chat_dto.RoomJoinedResponse, *app_error.AppError) {
+	invitation, err := c.findOwnPendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the group might have been deleted after the invitation was sent
+	if _, _, err := c.findGroupWithMembers(ctx, invitation.RoomID); err != nil {
+		return nil, err
+	}
+
+	if err := c.ChatRepo.AcceptInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return c.roomJoined(ctx, invitation.RoomID, userID)
+}
+
+func (c *ChatService) DeclineInvitation(ctx context.Context, userID, invitationID string) *app_error.AppError {
+	invitation, err := c.findOwnPendingInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	return c.ChatRepo.DeclineInvitation(ctx, invitation)
+}
+
+func (c *ChatService) CreateInviteLink(ctx context.Context, req chat_dto.CreateInviteLinkRequest, userID, roomID string) (*chat_dto.InviteLinkResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, userID, ActionInviteMember); err != nil {
+		return nil, err
+	}
+
+	code, genErr := generateInviteCode()
+	if genErr != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, "failed to generate invite code", "invite-link")
+	}
+
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	expiresAt := time.Now().Add(ttl)
+	link := &entity.InviteLink{
+		Code:      code,
+		RoomID:    roomID,
+		CreatedBy: userID,
+		MaxUses:   req.MaxUses,
+		Uses:      0,
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	if err := c.ChatRepo.CreateInviteLink(ctx, link, ttl); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.InviteLinkResponse{
+		Code:      code,
+		RoomID:    roomID,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (c *ChatService) JoinByInviteLink(ctx context.Context, userID, code string) (*chat_dto.RoomJoinedResponse, *app_error.AppError) {
+	link, err := c.ChatRepo.FindInviteLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	_, members, err := c.findGroupWithMembers(ctx, link.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.isUserMemberOfRoom(members, userID) {
+		return nil, app_error.NewAppError(http.StatusConflict, "you are already a member of this group", "member")
+	}
+
+	if err := c.ChatRepo.ConsumeInviteLink(ctx, code); err != nil {
+		return nil, err
+	}
+
+	if err := c.ChatRepo.AddRoomMember(ctx, link.RoomID, userID, entity.RoomRoleMember); err != nil {
+		c.ChatRepo.ReleaseInviteLink(ctx, code)
+		return nil, err
+	}
+
+	return c.roomJoined(ctx, link.RoomID, userID)
+}
+
+func (c *ChatService) findOwnPendingInvitation(ctx context.Context, userID, invitationID string) (*entity.RoomInvitation, *app_error.AppError) {
+	invitation, err := c.ChatRepo.FindInvitationByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// do not leak invitations of other users
+	if invitation.InviteeID != userID {
+		return nil, app_error.NewAppError(http.StatusNotFound, "invitation not found", "not-found")
+	}
+
+	if invitation.Status != entity.InvitationStatusPending {
+		return nil, app_error.NewAppError(http.StatusConflict, "invitation was already answered", "invitation")
+	}
+
+	return invitation, nil
+}
+
+// roomJoined builds the payload for the room_joined event from the current member list
+func (c *ChatService) roomJoined(ctx context.Context, roomID, userID string) (*chat_dto.RoomJoinedResponse, *app_error.AppError) {
+	members, err := c.ChatRepo.FindRoomMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.RoomJoinedResponse{
+		RoomID:       roomID,
+		UserID:       userID,
+		Participants: activeMemberIDs(members),
+	}, nil
+}
+
+func generateInviteCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func toInvitationResponses(invitations []*entity.RoomInvitation) []chat_dto.InvitationResponse {
+	resp := make([]chat_dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		resp = append(resp, chat_dto.InvitationResponse{
+			InvitationID: invitation.ID,
+			RoomID:       invitation.RoomID,
+			InviterID:    invitation.InviterID,
+			InviteeID:    invitation.InviteeID,
+			Status:       invitation.Status,
+			CreatedAt:    invitation.CreatedAt,
+		})
+	}
+
+	return resp
+}
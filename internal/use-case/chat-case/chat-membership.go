@@ -0,0 +1,112 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+func (c *ChatService) LeaveRoom(ctx context.Context, userID, roomID string) (*chat_dto.RoomLeftResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	member := c.findActiveMember(members, userID)
+	if member == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this group", "forbidden")
+	}
+
+	// the group must not be left without an owner while other members are still in it
+	if member.Role == entity.RoomRoleOwner && len(activeMembers(members)) > 1 {
+		return nil, app_error.NewAppError(http.StatusConflict, "transfer ownership before leaving the group", "owner")
+	}
+
+	if err := c.ChatRepo.DeactivateRoomMember(ctx, roomID, userID, userID, entity.MembershipEventLeft); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.RoomLeftResponse{
+		RoomID:     roomID,
+		UserID:     userID,
+		Reason:     entity.MembershipEventLeft,
+		ActorID:    userID,
+		Recipients: activeMemberIDs(members),
+	}, nil
+}
+
+func (c *ChatService) RemoveMember(ctx context.Context, actorID, roomID, targetID string) (*chat_dto.RoomLeftResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := c.authorize(members, actorID, ActionRemoveMember)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorID == targetID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "use leave to remove yourself from the group", "user_id")
+	}
+
+	target := c.findActiveMember(members, targetID)
+	if target == nil {
+		return nil, app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
+	}
+
+	if !Outranks(actor, target) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you can only remove members below your role", "forbidden")
+	}
+
+	if err := c.ChatRepo.DeactivateRoomMember(ctx, roomID, targetID, actorID, entity.MembershipEventRemoved); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.RoomLeftResponse{
+		RoomID:     roomID,
+		UserID:     targetID,
+		Reason:     entity.MembershipEventRemoved,
+		ActorID:    actorID,
+		Recipients: activeMemberIDs(members),
+	}, nil
+}
+
+func (c *ChatService) GetMembershipEvents(ctx context.Context, userID, roomID string, limit int) (*chat_dto.MembershipEventsResponse, *app_error.AppError) {
+	_, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trail is a moderation tool, same audience as removing members
+	if _, err := c.authorize(members, userID, ActionRemoveMember); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	events, err := c.ChatRepo.FindMembershipEvents(ctx, roomID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]chat_dto.MembershipEventResponse, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, chat_dto.MembershipEventResponse{
+			UserID:    event.UserID,
+			ActorID:   event.ActorID,
+			Event:     event.Event,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return &chat_dto.MembershipEventsResponse{
+		RoomID: roomID,
+		Events: resp,
+	}, nil
+}
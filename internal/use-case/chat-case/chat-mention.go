@@ -0,0 +1,112 @@
+package chat_service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+// mentionPattern matches @username tokens that start a word, so e-mail addresses are not picked up
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.\-]{3,50})`)
+
+func (c *ChatService) GetMentions(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID string) (*chat_dto.MentionsResponse, *app_error.AppError) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// mentions from rooms the user has left are no longer theirs to read
+	roomIDs, err := c.ChatRepo.FindActiveRoomIDsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roomIDs) == 0 {
+		return &chat_dto.MentionsResponse{Mentions: []chat_dto.MentionResponse{}}, nil
+	}
+
+	messages, err := c.ChatRepo.FindMentions(ctx, userID, roomIDs, limit, req.BeforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first for the feed, messages come back oldest first
+	mentions := make([]chat_dto.MentionResponse, 0, len(messages))
+	for i := len(messages) - 1; i >= 0; i-- {
+		mentions = append(mentions, chat_dto.MentionResponse{
+			MessageID: messages[i].ID.Hex(),
+			RoomID:    messages[i].RoomID,
+			SenderID:  messages[i].SenderID,
+			Content:   plainTextOf(messages[i]), // the feed is a notification list, shown without markup
+			CreatedAt: messages[i].CreatedAt,
+		})
+	}
+
+	var nextCursor *string
+	if len(messages) > 0 {
+		oldestMsgID := messages[0].ID.Hex()
+		nextCursor = &oldestMsgID
+	}
+
+	return &chat_dto.MentionsResponse{
+		Mentions:   mentions,
+		NextCursor: nextCursor,
+		HasMore:    len(messages) == limit,
+	}, nil
+}
+
+// resolveMentions looks up the @username tokens of content and keeps the users among candidateIDs,
+// the sender never mentions themselves. Lookup failures are logged, the message is sent without mentions.
+func (c *ChatService) resolveMentions(ctx context.Context, content, senderID string, candidateIDs []string) []string {
+	usernames := extractMentionUsernames(content)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	users, err := c.UserRepo.FindUsersByUsernames(ctx, usernames)
+	if err != nil {
+		log.Warn().Msgf("failed to resolve mentions: %s", err.Message)
+		return nil
+	}
+
+	var mentions []string
+	for _, user := range users {
+		if user.ID != senderID && slices.Contains(candidateIDs, user.ID) && !slices.Contains(mentions, user.ID) {
+			mentions = append(mentions, user.ID)
+		}
+	}
+
+	return mentions
+}
+
+// extractMentionUsernames returns the distinct usernames mentioned in content, in order of appearance
+func extractMentionUsernames(content string) []string {
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// a mention at the end of a sentence keeps its trailing punctuation out of the username
+		username := strings.TrimRight(match[1], ".-")
+		if len(username) < 3 || slices.Contains(usernames, username) {
+			continue
+		}
+		usernames = append(usernames, username)
+	}
+
+	return usernames
+}
+
+// newMentions returns the mentions of an edited message that were not there before the edit
+func newMentions(before, after []string) []string {
+	var added []string
+	for _, userID := range after {
+		if !slices.Contains(before, userID) {
+			added = append(added, userID)
+		}
+	}
+
+	return added
+}
@@ -0,0 +1,34 @@
+package chat_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMentionUsernames(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"no mention", "hello there", nil},
+		{"single mention", "@alice hi", []string{"alice"}},
+		{"several mentions in order", "hey @bob and @alice", []string{"bob", "alice"}},
+		{"duplicates collapsed", "@bob @bob!", []string{"bob"}},
+		{"trailing punctuation", "ping @carol.", []string{"carol"}},
+		{"email is not a mention", "mail me at dave@example.com", nil},
+		{"too short", "@ab", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractMentionUsernames(tt.content))
+		})
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	assert.Equal(t, []string{"u2"}, newMentions([]string{"u1"}, []string{"u1", "u2"}))
+	assert.Nil(t, newMentions([]string{"u1", "u2"}, []string{"u1"}))
+}
@@ -0,0 +1,158 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/config"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultMaxPinsPerRoom = 50
+
+func (c *ChatService) PinMessage(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessagePinnedResponse, *app_error.AppError) {
+	msg, members, err := c.findPinnableMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.IsDeleted {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "deleted message cannot be pinned", "deleted")
+	}
+
+	pin := &entity.RoomPin{
+		RoomID:    roomID,
+		MessageID: msg.ID.Hex(),
+		PinnedBy:  userID,
+		PinnedAt:  time.Now(),
+	}
+	if err := c.ChatRepo.PinMessage(ctx, pin, maxPinsPerRoom()); err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.MessagePinnedResponse{
+		MessageID:  pin.MessageID,
+		RoomID:     roomID,
+		Pinned:     true,
+		PinnedBy:   userID,
+		PinnedAt:   pin.PinnedAt,
+		Recipients: activeMemberIDs(members),
+	}, nil
+}
+
+func (c *ChatService) UnpinMessage(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessagePinnedResponse, *app_error.AppError) {
+	msg, members, err := c.findPinnableMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	unpinned, err := c.ChatRepo.UnpinMessage(ctx, roomID, msg.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if !unpinned {
+		return nil, app_error.NewAppError(http.StatusNotFound, "message is not pinned", "not-found")
+	}
+
+	return &chat_dto.MessagePinnedResponse{
+		MessageID:  msg.ID.Hex(),
+		RoomID:     roomID,
+		Pinned:     false,
+		PinnedBy:   userID,
+		PinnedAt:   time.Now(),
+		Recipients: activeMemberIDs(members),
+	}, nil
+}
+
+func (c *ChatService) GetRoomPins(ctx context.Context, userID, roomID string) (*chat_dto.RoomPinsResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.findActiveMember(members, userID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	pins, err := c.ChatRepo.FindRoomPins(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(pins))
+	for _, pin := range pins {
+		if id, err := primitive.ObjectIDFromHex(pin.MessageID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	messages, err := c.ChatRepo.FindMessagesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*entity.Message, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID.Hex()] = msg
+	}
+
+	// keep the pin order, pins whose message is gone or hidden for the caller are left out
+	respPins := make([]chat_dto.PinnedMessageResponse, 0, len(pins))
+	for _, pin := range pins {
+		msg, ok := byID[pin.MessageID]
+		if !ok || msg.IsDeleted || isHiddenFor(msg, userID) {
+			continue
+		}
+
+		respPins = append(respPins, chat_dto.PinnedMessageResponse{
+			Message:  toPrivateMessage(members, userID, msg),
+			PinnedBy: pin.PinnedBy,
+			PinnedAt: pin.PinnedAt,
+		})
+	}
+
+	return &chat_dto.RoomPinsResponse{
+		Pins:    respPins,
+		MaxPins: maxPinsPerRoom(),
+	}, nil
+}
+
+// findPinnableMessage checks the caller may pin in the room and the message belongs to it.
+// Group pins follow the room roles, in a private room both members may pin.
+func (c *ChatService) findPinnableMessage(ctx context.Context, userID, roomID, messageID string) (*entity.Message, []*entity.RoomMember, *app_error.AppError) {
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	action := ActionPinMessage
+	if room.RT == entity.RoomTypePrivate {
+		action = ActionPostMessage
+	}
+	if _, err := c.authorize(members, userID, action); err != nil {
+		return nil, nil, err
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	return msg, members, nil
+}
+
+func maxPinsPerRoom() int {
+	if config.Conf == nil || config.Conf.CHAT.MaxPinsPerRoom <= 0 {
+		return defaultMaxPinsPerRoom
+	}
+
+	return config.Conf.CHAT.MaxPinsPerRoom
+}
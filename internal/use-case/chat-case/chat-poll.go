@@ -0,0 +1,355 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	minPollDuration = time.Minute
+	maxPollDuration = 30 * 24 * time.Hour
+)
+
+// CreatePoll posts a poll as a message of the room, a poll with a closing time is closed by the close_poll job
+func (c *ChatService) CreatePoll(ctx context.Context, req chat_dto.CreatePollRequest, senderID, roomID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	now := time.Now()
+	poll, err := newPoll(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+		return nil, err
+	}
+
+	msg := &entity.Message{
+		ID:         primitive.NewObjectID(),
+		RoomID:     roomID,
+		SenderID:   senderID,
+		ReceiverID: privateReceiverID(room, members, senderID),
+		Type:       entity.MessageTypePoll,
+		Content:    poll.Question,
+		Format:     entity.MessageFormatPlain,
+		PlainText:  poll.Question,
+		Poll:       poll,
+		CreatedAt:  now,
+	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
+
+	msgId, err := c.ChatRepo.CreateMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.ChatRepo.UpdateRoomMetadata(ctx, roomID, senderID, msgId); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update metadata message: %v", err), "update-room-meta")
+	}
+
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(roomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	return &chat_dto.RoomMessageResponse{
+		MessageID:   msgId.Hex(),
+		RoomID:      roomID,
+		SenderID:    senderID,
+		ReceiverID:  msg.ReceiverID,
+		MessageType: entity.MessageTypePoll,
+		Content:     msg.Content,
+		Format:      msg.Format,
+		PlainText:   msg.PlainText,
+		Poll:        toPollDTO(poll, senderID, now),
+		CreatedAt:   msg.CreatedAt,
+		ExpiresAt:   msg.ExpiresAt,
+		Recipients:  activeMemberIDs(members),
+		Unread:      c.bumpUnread(ctx, roomID, senderID, 1),
+	}, nil
+}
+
+// VotePoll records the choice of a member, voting again replaces the previous choice
+func (c *ChatService) VotePoll(ctx context.Context, req chat_dto.VotePollRequest, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError) {
+	msg, members, _, err := c.findPoll(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePollVote(msg.Poll, req.OptionIDs); err != nil {
+		return nil, err
+	}
+
+	return c.setPollVote(ctx, msg, members, userID, req.OptionIDs)
+}
+
+// RetractPollVote withdraws the vote of a member, retracting a missing vote is a no-op
+func (c *ChatService) RetractPollVote(ctx context.Context, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError) {
+	msg, members, _, err := c.findPoll(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.setPollVote(ctx, msg, members, userID, nil)
+}
+
+// ClosePoll ends a poll before its closing time, only its creator and moderators can close it
+func (c *ChatService) ClosePoll(ctx context.Context, userID, roomID, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError) {
+	msg, members, member, err := c.findPoll(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.SenderID != userID && !CanPerform(member, ActionEditAnyMessage) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "only the creator of the poll or a moderator can close it", "forbidden")
+	}
+
+	now := time.Now()
+	if pollClosed(msg.Poll, now) {
+		return nil, app_error.NewAppError(http.StatusConflict, "the poll is already closed", "closed")
+	}
+
+	updated, err := c.ChatRepo.ClosePoll(ctx, msg.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, app_error.NewAppError(http.StatusConflict, "the poll is already closed", "closed")
+	}
+
+	return c.pollUpdatedResponse(updated, members, userID), nil
+}
+
+// CloseExpiredPoll closes a poll whose closing time has passed, it runs from the close_poll job.
+// Returns nil when the poll was already closed or is gone.
+func (c *ChatService) CloseExpiredPoll(ctx context.Context, messageID string) (*chat_dto.PollUpdatedResponse, *app_error.AppError) {
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if msg.Type != entity.MessageTypePoll || msg.Poll == nil || msg.Poll.ClosedAt != nil || msg.Poll.ClosesAt == nil {
+		return nil, nil
+	}
+
+	updated, err := c.ChatRepo.ClosePoll(ctx, msg.ID, *msg.Poll.ClosesAt)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, nil
+	}
+
+	members, err := c.ChatRepo.FindRoomMembers(ctx, updated.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.pollUpdatedResponse(updated, members, ""), nil
+}
+
+// findPoll checks the caller can post in the room and the message is a live poll of it
+func (c *ChatService) findPoll(ctx context.Context, userID, roomID, messageID string) (*entity.Message, []*entity.RoomMember, *entity.RoomMember, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	member, err := c.authorize(members, userID, ActionPostMessage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, nil, nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	if msg.IsDeleted || msg.Type != entity.MessageTypePoll || msg.Poll == nil {
+		return nil, nil, nil, app_error.NewAppError(http.StatusNotFound, "poll not found", "not-found")
+	}
+
+	return msg, members, member, nil
+}
+
+func (c *ChatService) setPollVote(ctx context.Context, msg *entity.Message, members []*entity.RoomMember, userID string, optionIDs []string) (*chat_dto.PollUpdatedResponse, *app_error.AppError) {
+	now := time.Now()
+	if pollClosed(msg.Poll, now) {
+		return nil, app_error.NewAppError(http.StatusConflict, "the poll is closed", "closed")
+	}
+
+	updated, err := c.ChatRepo.SetPollVote(ctx, msg.ID, &entity.PollVote{
+		UserID:    userID,
+		OptionIDs: optionIDs,
+		VotedAt:   now,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	// closed or deleted since it was read
+	if updated == nil {
+		return nil, app_error.NewAppError(http.StatusConflict, "the poll is closed", "closed")
+	}
+
+	return c.pollUpdatedResponse(updated, members, userID), nil
+}
+
+func (c *ChatService) pollUpdatedResponse(msg *entity.Message, members []*entity.RoomMember, viewerID string) *chat_dto.PollUpdatedResponse {
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(msg.RoomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	return &chat_dto.PollUpdatedResponse{
+		MessageID:  msg.ID.Hex(),
+		RoomID:     msg.RoomID,
+		Poll:       toPollDTO(msg.Poll, viewerID, time.Now()),
+		Recipients: activeMemberIDs(members),
+	}
+}
+
+// newPoll validates a poll request into a poll without votes, options are numbered from 1 in the given order
+func newPoll(req chat_dto.CreatePollRequest, now time.Time) (*entity.Poll, *app_error.AppError) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "question is required", "question")
+	}
+
+	options := make([]*entity.PollOption, 0, len(req.Options))
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, app_error.NewAppError(http.StatusBadRequest, "options cannot be empty", "options")
+		}
+
+		key := strings.ToLower(text)
+		if seen[key] {
+			return nil, app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("option %q is listed twice", text), "options")
+		}
+		seen[key] = true
+
+		options = append(options, &entity.PollOption{ID: strconv.Itoa(i + 1), Text: text})
+	}
+
+	if req.ClosesAt != nil {
+		if req.ClosesAt.Before(now.Add(minPollDuration)) {
+			return nil, app_error.NewAppError(http.StatusBadRequest, "closes_at must be at least a minute in the future", "closes_at")
+		}
+		if req.ClosesAt.After(now.Add(maxPollDuration)) {
+			return nil, app_error.NewAppError(http.StatusBadRequest, "closes_at cannot be more than 30 days in the future", "closes_at")
+		}
+	}
+
+	var closesAt *time.Time
+	if req.ClosesAt != nil {
+		t := req.ClosesAt.UTC()
+		closesAt = &t
+	}
+
+	return &entity.Poll{
+		Question:       question,
+		Options:        options,
+		MultipleChoice: req.MultipleChoice,
+		Anonymous:      req.Anonymous,
+		ClosesAt:       closesAt,
+		Votes:          []*entity.PollVote{},
+	}, nil
+}
+
+// validatePollVote checks every chosen option exists and a single choice poll gets one option
+func validatePollVote(poll *entity.Poll, optionIDs []string) *app_error.AppError {
+	if !poll.MultipleChoice && len(optionIDs) > 1 {
+		return app_error.NewAppError(http.StatusBadRequest, "this poll allows a single option", "option_ids")
+	}
+
+	for _, id := range optionIDs {
+		if !slices.ContainsFunc(poll.Options, func(option *entity.PollOption) bool { return option.ID == id }) {
+			return app_error.NewAppError(http.StatusBadRequest, fmt.Sprintf("option %q does not exist", id), "option_ids")
+		}
+	}
+
+	return nil
+}
+
+// pollClosed reports whether a poll takes no more votes, the closing time counts even before the close_poll job ran
+func pollClosed(poll *entity.Poll, now time.Time) bool {
+	return poll.ClosedAt != nil || (poll.ClosesAt != nil && !now.Before(*poll.ClosesAt))
+}
+
+// toPollDTO counts the votes per option in the option order, an empty viewerID leaves out MyVote
+func toPollDTO(poll *entity.Poll, viewerID string, now time.Time) *chat_dto.Poll {
+	if poll == nil {
+		return nil
+	}
+
+	options := make([]chat_dto.PollOptionResult, 0, len(poll.Options))
+	index := make(map[string]int, len(poll.Options))
+	for i, option := range poll.Options {
+		index[option.ID] = i
+		options = append(options, chat_dto.PollOptionResult{ID: option.ID, Text: option.Text})
+	}
+
+	dto := &chat_dto.Poll{
+		Question:       poll.Question,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		IsClosed:       pollClosed(poll, now),
+	}
+
+	for _, vote := range poll.Votes {
+		if len(vote.OptionIDs) == 0 {
+			continue
+		}
+		dto.TotalVoters++
+
+		if viewerID != "" && vote.UserID == viewerID {
+			dto.MyVote = vote.OptionIDs
+		}
+
+		for _, id := range vote.OptionIDs {
+			i, ok := index[id]
+			if !ok {
+				continue
+			}
+
+			options[i].Votes++
+			if !poll.Anonymous {
+				options[i].Voters = append(options[i].Voters, vote.UserID)
+			}
+		}
+	}
+	dto.Options = options
+
+	return dto
+}
+
+// messageType is text for every message that is not a poll, stored messages have no type
+func messageType(msgType string) string {
+	if msgType == "" {
+		return entity.MessageTypeText
+	}
+
+	return msgType
+}
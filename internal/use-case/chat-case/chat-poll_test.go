@@ -0,0 +1,94 @@
+package chat_service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestNewPoll(t *testing.T) {
+	now := time.Now()
+	closesAt := now.Add(time.Hour)
+
+	poll, err := newPoll(chat_dto.CreatePollRequest{
+		Question: " Lunch? ",
+		Options:  []string{"Pizza", " Sushi "},
+		ClosesAt: &closesAt,
+	}, now)
+	if err != nil {
+		t.Fatalf("newPoll() error = %v", err.Message)
+	}
+	if poll.Question != "Lunch?" || len(poll.Options) != 2 || poll.Options[1].ID != "2" || poll.Options[1].Text != "Sushi" {
+		t.Errorf("newPoll() = %+v", poll)
+	}
+	if poll.Votes == nil {
+		t.Error("newPoll() left votes nil, the vote update needs an array")
+	}
+
+	cases := map[string]chat_dto.CreatePollRequest{
+		"blank question":    {Question: "  ", Options: []string{"a", "b"}},
+		"blank option":      {Question: "q", Options: []string{"a", " "}},
+		"duplicate options": {Question: "q", Options: []string{"Yes", "yes "}},
+		"closes too soon":   {Question: "q", Options: []string{"a", "b"}, ClosesAt: &now},
+	}
+	for name, req := range cases {
+		if _, err := newPoll(req, now); err == nil {
+			t.Errorf("newPoll() accepted %s", name)
+		}
+	}
+}
+
+func TestValidatePollVote(t *testing.T) {
+	poll := &entity.Poll{Options: []*entity.PollOption{{ID: "1"}, {ID: "2"}}}
+
+	if err := validatePollVote(poll, []string{"2"}); err != nil {
+		t.Errorf("validatePollVote() error = %v", err.Message)
+	}
+	if err := validatePollVote(poll, []string{"1", "2"}); err == nil {
+		t.Error("validatePollVote() accepted two options on a single choice poll")
+	}
+	if err := validatePollVote(poll, []string{"3"}); err == nil {
+		t.Error("validatePollVote() accepted an unknown option")
+	}
+
+	poll.MultipleChoice = true
+	if err := validatePollVote(poll, []string{"1", "2"}); err != nil {
+		t.Errorf("validatePollVote(multiple) error = %v", err.Message)
+	}
+}
+
+func TestToPollDTO(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	poll := &entity.Poll{
+		Question:       "q",
+		Options:        []*entity.PollOption{{ID: "1", Text: "a"}, {ID: "2", Text: "b"}},
+		MultipleChoice: true,
+		ClosesAt:       &past,
+		Votes: []*entity.PollVote{
+			{UserID: "u1", OptionIDs: []string{"1", "2"}},
+			{UserID: "u2", OptionIDs: []string{"2"}},
+		},
+	}
+
+	dto := toPollDTO(poll, "u2", now)
+	if dto.TotalVoters != 2 || dto.Options[0].Votes != 1 || dto.Options[1].Votes != 2 {
+		t.Errorf("toPollDTO() counts = %+v", dto.Options)
+	}
+	if !slices.Equal(dto.MyVote, []string{"2"}) || !slices.Equal(dto.Options[1].Voters, []string{"u1", "u2"}) {
+		t.Errorf("toPollDTO() my vote = %v, voters = %v", dto.MyVote, dto.Options[1].Voters)
+	}
+	// the closing time passed before the close_poll job ran
+	if !dto.IsClosed {
+		t.Error("toPollDTO() reports a poll past its closing time as open")
+	}
+
+	poll.Anonymous = true
+	dto = toPollDTO(poll, "", now)
+	if dto.MyVote != nil || dto.Options[1].Voters != nil {
+		t.Errorf("toPollDTO(anonymous) = %+v", dto)
+	}
+}
@@ -0,0 +1,117 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+)
+
+const (
+	ReactionAdded   = "added"
+	ReactionRemoved = "removed"
+)
+
+func (c *ChatService) AddReaction(ctx context.Context, req chat_dto.ReactionRequest, userID, roomID, messageID string) (*chat_dto.MessageReactionResponse, *app_error.AppError) {
+	msg, members, err := c.findReactableMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, changed, err := c.ChatRepo.AddReaction(ctx, msg.ID, &entity.Reaction{
+		UserID:    userID,
+		Emoji:     req.Emoji,
+		ReactedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reactionResponse(msg, members, userID, req.Emoji, ReactionAdded, reactions, changed), nil
+}
+
+func (c *ChatService) RemoveReaction(ctx context.Context, req chat_dto.ReactionRequest, userID, roomID, messageID string) (*chat_dto.MessageReactionResponse, *app_error.AppError) {
+	msg, members, err := c.findReactableMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, changed, err := c.ChatRepo.RemoveReaction(ctx, msg.ID, userID, req.Emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reactionResponse(msg, members, userID, req.Emoji, ReactionRemoved, reactions, changed), nil
+}
+
+// findReactableMessage checks the caller can post in the room and the message is a live message of it
+func (c *ChatService) findReactableMessage(ctx context.Context, userID, roomID, messageID string) (*entity.Message, []*entity.RoomMember, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := c.authorize(members, userID, ActionPostMessage); err != nil {
+		return nil, nil, err
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	if msg.IsDeleted {
+		return nil, nil, app_error.NewAppError(http.StatusBadRequest, "deleted message cannot be reacted to", "deleted")
+	}
+
+	return msg, members, nil
+}
+
+func (c *ChatService) reactionResponse(msg *entity.Message, members []*entity.RoomMember, userID, emoji, action string, reactions []*entity.Reaction, changed bool) *chat_dto.MessageReactionResponse {
+	if changed {
+		// invalidate cache key
+		cacheKey := createMessageCacheKey(msg.RoomID)
+		utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+	}
+
+	return &chat_dto.MessageReactionResponse{
+		MessageID:  msg.ID.Hex(),
+		RoomID:     msg.RoomID,
+		UserID:     userID,
+		Emoji:      emoji,
+		Action:     action,
+		Reactions:  summarizeReactions(reactions, userID),
+		Changed:    changed,
+		Recipients: activeMemberIDs(members),
+	}
+}
+
+// summarizeReactions counts reactions per emoji in the order each emoji was first used
+func summarizeReactions(reactions []*entity.Reaction, viewerID string) []chat_dto.ReactionSummary {
+	summaries := make([]chat_dto.ReactionSummary, 0)
+	index := make(map[string]int)
+
+	for _, reaction := range reactions {
+		i, ok := index[reaction.Emoji]
+		if !ok {
+			i = len(summaries)
+			index[reaction.Emoji] = i
+			summaries = append(summaries, chat_dto.ReactionSummary{Emoji: reaction.Emoji})
+		}
+
+		summaries[i].Count++
+		if reaction.UserID == viewerID {
+			summaries[i].Reacted = true
+		}
+	}
+
+	return summaries
+}
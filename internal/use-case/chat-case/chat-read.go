@@ -0,0 +1,134 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+// MarkMessageAsRead moves the caller's read cursor up to the given message, everything before it counts as read too
+func (c *ChatService) MarkMessageAsRead(ctx context.Context, userID, roomID, messageID string) (*chat_dto.ReadCursorResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.isUserMemberOfRoom(members, userID) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	readAt := time.Now()
+	advanced, err := c.ChatRepo.AdvanceReadCursor(ctx, roomID, userID, msg.ID, readAt)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &chat_dto.ReadCursorResponse{
+		RoomID:        roomID,
+		UserID:        userID,
+		LastReadMsgID: msg.ID.Hex(),
+		ReadAt:        readAt,
+		Advanced:      advanced,
+		Recipients:    activeMemberIDs(members),
+	}
+
+	if advanced {
+		unread, err := c.resetUnread(ctx, roomID, userID, msg.ID)
+		if err != nil {
+			return nil, err
+		}
+		resp.UnreadCount = &unread
+	}
+
+	return resp, nil
+}
+
+func (c *ChatService) GetMessageReceipts(ctx context.Context, userID, roomID, messageID string) (*chat_dto.MessageReceiptsResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.isUserMemberOfRoom(members, userID) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	// read_at is when the member cursor last moved, which is at or after the moment the message was read
+	readBy := make([]chat_dto.MessageReceipt, 0)
+	unread := make([]string, 0)
+	for _, member := range activeMembers(members) {
+		if member.UserID == msg.SenderID {
+			continue
+		}
+
+		if cursorCovers(member, msg) {
+			readBy = append(readBy, chat_dto.MessageReceipt{
+				UserID: member.UserID,
+				ReadAt: member.LastReadAt,
+			})
+		} else {
+			unread = append(unread, member.UserID)
+		}
+	}
+
+	return &chat_dto.MessageReceiptsResponse{
+		MessageID: msg.ID.Hex(),
+		RoomID:    roomID,
+		ReadBy:    readBy,
+		Unread:    unread,
+	}, nil
+}
+
+// cursorCovers reports whether the member read cursor is at or past the message.
+// ObjectID hex strings have a fixed length so comparing them as text follows their creation order.
+func cursorCovers(member *entity.RoomMember, msg *entity.Message) bool {
+	return member.LastReadMsgID != "" && member.LastReadMsgID >= msg.ID.Hex()
+}
+
+// isReadFor resolves the is_read flag as seen by viewerID: an own message is read once every
+// other active member has read it, someone else's message is read once the viewer has read it
+func isReadFor(members []*entity.RoomMember, viewerID string, msg *entity.Message) bool {
+	if msg.SenderID != viewerID {
+		for _, member := range members {
+			if member.UserID == viewerID {
+				return cursorCovers(member, msg)
+			}
+		}
+		return false
+	}
+
+	others := 0
+	for _, member := range activeMembers(members) {
+		if member.UserID == viewerID {
+			continue
+		}
+		if !cursorCovers(member, msg) {
+			return false
+		}
+		others++
+	}
+
+	return others > 0
+}
@@ -0,0 +1,159 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	chat_repo "github.com/xenn00/chat-system/internal/repo/chat"
+	"github.com/xenn00/chat-system/state"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeChatRepo keeps rooms, members, messages and bookmarks in memory. Everything else goes to the real repo,
+// which only has Redis (miniredis) behind it.
+type fakeChatRepo struct {
+	chat_repo.ChatRepoContract
+	rooms     map[string]*entity.Room
+	members   map[string][]*entity.RoomMember
+	messages  map[primitive.ObjectID]*entity.Message
+	bookmarks map[string]bool
+}
+
+func newFakeChatService(t *testing.T) (*ChatService, *fakeChatRepo) {
+	t.Helper()
+
+	mockRedis := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mockRedis.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	appState := &state.AppState{Ctx: context.Background(), Redis: rdb}
+	repo := &fakeChatRepo{
+		ChatRepoContract: chat_repo.NewChatRepo(appState),
+		rooms:            make(map[string]*entity.Room),
+		members:          make(map[string][]*entity.RoomMember),
+		messages:         make(map[primitive.ObjectID]*entity.Message),
+		bookmarks:        make(map[string]bool),
+	}
+
+	return &ChatService{AppState: appState, ChatRepo: repo}, repo
+}
+
+// addRoom stores a room with the given members, the first one owns it
+func (r *fakeChatRepo) addRoom(rt string, userIDs ...string) *entity.Room {
+	room := &entity.Room{ID: uuid.New(), RT: rt, CreatedAt: time.Now()}
+	r.rooms[room.ID.String()] = room
+
+	for i, userID := range userIDs {
+		role := entity.RoomRoleMember
+		if i == 0 {
+			role = entity.RoomRoleOwner
+		}
+		r.members[room.ID.String()] = append(r.members[room.ID.String()], &entity.RoomMember{RoomID: room.ID.String(), UserID: userID, Role: role})
+	}
+
+	return room
+}
+
+func (r *fakeChatRepo) addMessage(room *entity.Room, senderID, content string) *entity.Message {
+	msg := &entity.Message{ID: primitive.NewObjectID(), RoomID: room.ID.String(), SenderID: senderID, Content: content, CreatedAt: time.Now()}
+	r.messages[msg.ID] = msg
+
+	return msg
+}
+
+// roomMessages counts the messages stored in a room
+func (r *fakeChatRepo) roomMessages(roomID string) int {
+	count := 0
+	for _, msg := range r.messages {
+		if msg.RoomID == roomID {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r *fakeChatRepo) FindRoomByID(ctx context.Context, roomID string) (*entity.Room, *app_error.AppError) {
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return nil, app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
+	}
+
+	return room, nil
+}
+
+func (r *fakeChatRepo) FindRoomMembers(ctx context.Context, roomID string) ([]*entity.RoomMember, *app_error.AppError) {
+	return r.members[roomID], nil
+}
+
+// FindMessageByID hides expired messages like the mongo query does
+func (r *fakeChatRepo) FindMessageByID(ctx context.Context, messageID string) (*entity.Message, *app_error.AppError) {
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "invalid message ID", "invalid-id")
+	}
+
+	msg, ok := r.messages[id]
+	if !ok || isExpired(msg, time.Now()) {
+		return nil, app_error.NewAppError(http.StatusNotFound, "message not found or has been deleted", "not-found")
+	}
+
+	return msg, nil
+}
+
+func (r *fakeChatRepo) CreateMessage(ctx context.Context, msg *entity.Message) (primitive.ObjectID, *app_error.AppError) {
+	if msg.ClientMsgID != "" {
+		if _, err := r.FindMessageByClientMsgID(ctx, msg.SenderID, msg.ClientMsgID); err == nil {
+			return primitive.NilObjectID, app_error.NewAppError(http.StatusConflict, "a message with this client_msg_id was already sent", "client_msg_id")
+		}
+	}
+
+	r.messages[msg.ID] = msg
+	return msg.ID, nil
+}
+
+func (r *fakeChatRepo) FindMessageByClientMsgID(ctx context.Context, senderID, clientMsgID string) (*entity.Message, *app_error.AppError) {
+	for _, msg := range r.messages {
+		if msg.SenderID == senderID && msg.ClientMsgID == clientMsgID {
+			return msg, nil
+		}
+	}
+
+	return nil, app_error.NewAppError(http.StatusNotFound, "message not found", "not-found")
+}
+
+func (r *fakeChatRepo) UpdateRoomMetadata(ctx context.Context, roomID, senderID string, msgID primitive.ObjectID) error {
+	return nil
+}
+
+func (r *fakeChatRepo) IncrementUnread(ctx context.Context, roomID, senderID string, by int64) (map[string]int64, *app_error.AppError) {
+	return nil, nil
+}
+
+// UpdateMessage stores the edit without the version check and the edit cap of the mongo update
+func (r *fakeChatRepo) UpdateMessage(ctx context.Context, msg *entity.Message, messageEditEntry *entity.MessageEditEntry, originalTimestamp *time.Time, maxEdits int) (*entity.Message, *app_error.AppError) {
+	msg.MessageEditHistory = append(msg.MessageEditHistory, messageEditEntry)
+	r.messages[msg.ID] = msg
+
+	return msg, nil
+}
+
+func (r *fakeChatRepo) CreateBookmark(ctx context.Context, bookmark *entity.MessageBookmark) (bool, *app_error.AppError) {
+	key := fmt.Sprintf("%s:%s", bookmark.UserID, bookmark.MessageID)
+	if r.bookmarks[key] {
+		return false, nil
+	}
+
+	r.bookmarks[key] = true
+	bookmark.ID = int64(len(r.bookmarks))
+	bookmark.CreatedAt = time.Now()
+	return true, nil
+}
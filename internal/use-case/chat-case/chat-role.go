@@ -0,0 +1,142 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (c *ChatService) ChangeMemberRole(ctx context.Context, req chat_dto.ChangeMemberRoleRequest, actorID, roomID, targetID string) (*chat_dto.MemberRoleChangedResponse, *app_error.AppError) {
+	room, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := c.authorize(members, actorID, ActionManageRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorID == targetID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "you cannot change your own role", "user_id")
+	}
+
+	target := c.findActiveMember(members, targetID)
+	if target == nil {
+		return nil, app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
+	}
+
+	// members can only be managed by someone above them, and never lifted to the actor's own rank
+	if !Outranks(actor, target) || roleRank[req.Role] >= roleRank[actor.Role] {
+		return nil, app_error.NewAppError(http.StatusForbidden, "your role is not allowed to assign this role", "forbidden")
+	}
+
+	if target.Role == req.Role {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "member already has this role", "role")
+	}
+
+	if err := c.ChatRepo.UpdateMemberRole(ctx, roomID, targetID, req.Role); err != nil {
+		return nil, err
+	}
+
+	change := "demoted"
+	if roleRank[req.Role] > roleRank[target.Role] {
+		change = "promoted"
+	}
+
+	announcement := c.postSystemMessage(ctx, room, actorID, fmt.Sprintf("%s was %s to %s by %s", targetID, change, req.Role, actorID), map[string]string{
+		"event":         "member_" + change,
+		"user_id":       targetID,
+		"previous_role": target.Role,
+		"role":          req.Role,
+		"changed_by":    actorID,
+	})
+
+	return &chat_dto.MemberRoleChangedResponse{
+		RoomID:       roomID,
+		UserID:       targetID,
+		PreviousRole: target.Role,
+		Role:         req.Role,
+		Change:       change,
+		ChangedBy:    actorID,
+		Recipients:   activeMemberIDs(members),
+		Announcement: announcement,
+	}, nil
+}
+
+func (c *ChatService) TransferOwnership(ctx context.Context, req chat_dto.TransferOwnershipRequest, ownerID, roomID string) (*chat_dto.OwnershipTransferredResponse, *app_error.AppError) {
+	room, members, err := c.findGroupWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, ownerID, ActionTransferOwnership); err != nil {
+		return nil, err
+	}
+
+	if ownerID == req.UserID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "you already own this group", "user_id")
+	}
+
+	if c.findActiveMember(members, req.UserID) == nil {
+		return nil, app_error.NewAppError(http.StatusNotFound, "member not found", "not-found")
+	}
+
+	if err := c.ChatRepo.TransferOwnership(ctx, roomID, ownerID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	announcement := c.postSystemMessage(ctx, room, ownerID, fmt.Sprintf("%s transferred ownership to %s", ownerID, req.UserID), map[string]string{
+		"event":             "ownership_transferred",
+		"previous_owner_id": ownerID,
+		"new_owner_id":      req.UserID,
+	})
+
+	return &chat_dto.OwnershipTransferredResponse{
+		RoomID:          roomID,
+		PreviousOwnerID: ownerID,
+		NewOwnerID:      req.UserID,
+		Recipients:      activeMemberIDs(members),
+		Announcement:    announcement,
+	}, nil
+}
+
+// postSystemMessage stores a room event in the history, sent by the member that caused it. The change itself
+// is already made, so a failed insert is only logged and the event is still broadcast, without a message ID.
+func (c *ChatService) postSystemMessage(ctx context.Context, room *entity.Room, actorID, content string, data map[string]string) *chat_dto.SystemMessage {
+	roomID := room.ID.String()
+	msg := &entity.Message{
+		ID:         primitive.NewObjectID(),
+		RoomID:     roomID,
+		SenderID:   actorID,
+		Type:       entity.MessageTypeSystem,
+		Content:    content,
+		Format:     entity.MessageFormatPlain,
+		PlainText:  content,
+		SystemData: data,
+		CreatedAt:  time.Now(),
+	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
+
+	announcement := &chat_dto.SystemMessage{Content: content, Data: data}
+	if _, err := c.ChatRepo.CreateMessage(ctx, msg); err != nil {
+		log.Error().Str("room_id", roomID).Msgf("failed to store system message: %s", err.Message)
+		return announcement
+	}
+
+	// invalidate cache key
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, createMessageCacheKey(roomID))
+
+	announcement.MessageID = msg.ID.Hex()
+	announcement.Data["message_id"] = announcement.MessageID
+
+	return announcement
+}
@@ -0,0 +1,271 @@
+package chat_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (c *ChatService) SendRoomMessage(ctx context.Context, req chat_dto.SendRoomMessageRequest, senderID, roomID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	resp, replayed, err := sendOnce(ctx, c, senderID, req.ClientMsgID, func() (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+		return c.sendRoomMessage(ctx, req, senderID, roomID)
+	}, toSentRoomMessageResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Replayed = replayed
+	return resp, nil
+}
+
+func (c *ChatService) sendRoomMessage(ctx context.Context, req chat_dto.SendRoomMessageRequest, senderID, roomID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	format := messageFormat(req.Format)
+	content, plainText := formatContent(format, req.Content)
+	if err := validateMessageBody(content, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+		return nil, err
+	}
+
+	msg := &entity.Message{
+		ID:          primitive.NewObjectID(),
+		RoomID:      roomID,
+		SenderID:    senderID,
+		ClientMsgID: req.ClientMsgID,
+		ReceiverID:  privateReceiverID(room, members, senderID),
+		Content:     content,
+		Format:      format,
+		PlainText:   plainText,
+		Mentions:    c.resolveMentions(ctx, plainText, senderID, activeMemberIDs(members)),
+		IsEdited:    false,
+		CreatedAt:   time.Now(),
+	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
+
+	msg.Attachments, err = c.attachUploads(ctx, senderID, req.AttachmentIDs, roomID, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	msgId, err := c.ChatRepo.CreateMessage(ctx, msg)
+	if err != nil {
+		c.detachUploads(ctx, msg)
+		return nil, err
+	}
+
+	if err := c.ChatRepo.UpdateRoomMetadata(ctx, roomID, senderID, msgId); err != nil {
+		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update metadata message: %v", err), "update-room-meta")
+	}
+
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(roomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	resp := toSentRoomMessageResponse(msg)
+	resp.Recipients = activeMemberIDs(members)
+	resp.Unread = c.bumpUnread(ctx, roomID, senderID, 1)
+
+	return resp, nil
+}
+
+func (c *ChatService) ReplyRoomMessage(ctx context.Context, req chat_dto.ReplyRoomMessageRequest, senderID, roomID, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	resp, replayed, err := sendOnce(ctx, c, senderID, req.ClientMsgID, func() (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+		return c.replyRoomMessage(ctx, req, senderID, roomID, messageID)
+	}, toSentRoomMessageResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Replayed = replayed
+	return resp, nil
+}
+
+func (c *ChatService) replyRoomMessage(ctx context.Context, req chat_dto.ReplyRoomMessageRequest, senderID, roomID, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	format := messageFormat(req.Format)
+	content, plainText := formatContent(format, req.Content)
+	if err := validateMessageBody(content, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+		return nil, err
+	}
+
+	// validate reply_to message exist in the room
+	repliedMsg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if repliedMsg.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message you are replying to does not belong to this room", "forbidden")
+	}
+
+	if repliedMsg.IsDeleted {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message you are replying to was deleted", "deleted")
+	}
+
+	msg := &entity.Message{
+		ID:          primitive.NewObjectID(),
+		RoomID:      roomID,
+		SenderID:    senderID,
+		ClientMsgID: req.ClientMsgID,
+		ReceiverID:  privateReceiverID(room, members, senderID),
+		Content:     content,
+		Format:      format,
+		PlainText:   plainText,
+		Mentions:    c.resolveMentions(ctx, plainText, senderID, activeMemberIDs(members)),
+		ReplyTo: &entity.ReplyTo{
+			MessageID: repliedMsg.ID,
+			Content:   plainTextOf(repliedMsg),
+			SenderID:  repliedMsg.SenderID,
+		},
+		ThreadRootID: threadRootOf(repliedMsg),
+		IsEdited:     false,
+		CreatedAt:    time.Now(),
+	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
+
+	msg.Attachments, err = c.attachUploads(ctx, senderID, req.AttachmentIDs, roomID, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.ChatRepo.ReplyMessage(ctx, msg); err != nil {
+		c.detachUploads(ctx, msg)
+		return nil, err
+	}
+
+	thread := c.recordThreadReply(ctx, msg, members)
+
+	// invalidate cache key
+	cacheKey := createMessageCacheKey(roomID)
+	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
+
+	resp := toSentRoomMessageResponse(msg)
+	resp.Thread = thread
+	resp.Recipients = activeMemberIDs(members)
+	resp.Unread = c.bumpUnread(ctx, roomID, senderID, 1)
+
+	return resp, nil
+}
+
+// toSentRoomMessageResponse is the response of a new message or reply, the caller adds recipients and thread
+func toSentRoomMessageResponse(msg *entity.Message) *chat_dto.RoomMessageResponse {
+	resp := &chat_dto.RoomMessageResponse{
+		MessageID:   msg.ID.Hex(),
+		ClientMsgID: msg.ClientMsgID,
+		RoomID:      msg.RoomID,
+		SenderID:    msg.SenderID,
+		ReceiverID:  msg.ReceiverID,
+		MessageType: messageType(msg.Type),
+		Content:     msg.Content,
+		Format:      messageFormat(msg.Format),
+		PlainText:   plainTextOf(msg),
+		Attachments: toAttachmentDTOs(msg.Attachments),
+		CreatedAt:   msg.CreatedAt,
+		ExpiresAt:   msg.ExpiresAt,
+		Mentions:    msg.Mentions,
+		Mentioned:   msg.Mentions,
+	}
+
+	if msg.ReplyTo != nil {
+		resp.ReplyTo = &chat_dto.ReplyMessage{
+			RepliedMessageID: msg.ReplyTo.MessageID.Hex(),
+			Content:          msg.ReplyTo.Content,
+			SenderID:         msg.ReplyTo.SenderID,
+		}
+	}
+
+	return resp
+}
+
+func (c *ChatService) UpdateRoomMessage(ctx context.Context, req chat_dto.UpdateRoomMessageRequest, senderID, roomID, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	edit, err := c.editMessage(ctx, senderID, roomID, messageID, req.Format, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat_dto.RoomMessageResponse{
+		MessageID:          edit.original.ID.Hex(),
+		RoomID:             roomID,
+		SenderID:           edit.original.SenderID,
+		ReceiverID:         edit.original.ReceiverID,
+		MessageType:        messageType(edit.original.Type),
+		Content:            edit.edited.Content,
+		Format:             edit.edited.Format,
+		PlainText:          edit.edited.PlainText,
+		ReplyTo:            toReplyMessage(edit.original.ReplyTo),
+		IsEdited:           true,
+		MessageEditHistory: toEditHistoryDTOs(edit.history),
+		EditedBy:           senderID,
+		CreatedAt:          edit.original.CreatedAt,
+		ExpiresAt:          edit.original.ExpiresAt,
+		UpdatedAt:          edit.edited.UpdatedAt,
+		Recipients:         activeMemberIDs(edit.members),
+		Mentions:           edit.edited.Mentions,
+		Mentioned:          newMentions(edit.original.Mentions, edit.edited.Mentions),
+	}, nil
+}
+
+// findActiveRoomWithMembers loads a room of any type that is not soft deleted, along with its member rows
+func (c *ChatService) findActiveRoomWithMembers(ctx context.Context, roomID string) (*entity.Room, []*entity.RoomMember, *app_error.AppError) {
+	room, err := c.ChatRepo.FindRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if room.DeletedAt != nil {
+		return nil, nil, app_error.NewAppError(http.StatusNotFound, "room not found", "not-found")
+	}
+
+	members, err := c.ChatRepo.FindRoomMembers(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return room, members, nil
+}
+
+// privateReceiverID keeps receiver_id filled for private rooms, group messages have no single receiver
+func privateReceiverID(room *entity.Room, members []*entity.RoomMember, senderID string) string {
+	if room.RT != entity.RoomTypePrivate {
+		return ""
+	}
+
+	for _, member := range members {
+		if member.UserID != senderID {
+			return member.UserID
+		}
+	}
+
+	return ""
+}
+
+func activeMemberIDs(members []*entity.RoomMember) []string {
+	ids := make([]string, 0, len(members))
+	for _, member := range activeMembers(members) {
+		ids = append(ids, member.UserID)
+	}
+
+	return ids
+}
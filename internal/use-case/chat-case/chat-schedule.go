@@ -0,0 +1,268 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/config"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultMaxScheduleAhead    = 30 * 24 * time.Hour
+	defaultMaxScheduledPerUser = 100
+)
+
+func (c *ChatService) ScheduleMessage(ctx context.Context, req chat_dto.ScheduleMessageRequest, senderID, receiverID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError) {
+	if senderID == receiverID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "cannot schedule a message to yourself", "receiver_id")
+	}
+
+	now := time.Now()
+	if err := validateSendAt(req.SendAt, now); err != nil {
+		return nil, err
+	}
+
+	pending, err := c.ChatRepo.CountScheduledMessages(ctx, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= int64(maxScheduledPerUser()) {
+		return nil, app_error.NewAppError(http.StatusConflict, "too many scheduled messages, cancel one first", "limit")
+	}
+
+	// resolving the room up front rejects unknown receivers before anything is queued
+	room, err := c.ChatRepo.FindOrCreateRoom(ctx, senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &entity.ScheduledMessage{
+		ID:         primitive.NewObjectID(),
+		RoomID:     room.ID.String(),
+		SenderID:   senderID,
+		ReceiverID: receiverID,
+		Content:    req.Content,
+		SendAt:     req.SendAt,
+		Status:     entity.ScheduledStatusPending,
+		Version:    1,
+		CreatedAt:  now,
+	}
+	if err := c.ChatRepo.CreateScheduledMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	resp := toScheduledMessageResponse(msg)
+	return &resp, nil
+}
+
+func (c *ChatService) GetScheduledMessages(ctx context.Context, userID string) (*chat_dto.ScheduledMessagesResponse, *app_error.AppError) {
+	messages, err := c.ChatRepo.FindScheduledMessages(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make([]chat_dto.ScheduledMessageResponse, 0, len(messages))
+	for _, msg := range messages {
+		scheduled = append(scheduled, toScheduledMessageResponse(msg))
+	}
+
+	return &chat_dto.ScheduledMessagesResponse{ScheduledMessages: scheduled}, nil
+}
+
+// UpdateScheduledMessage edits the content or the send time, the caller has to queue a job for the returned version
+func (c *ChatService) UpdateScheduledMessage(ctx context.Context, req chat_dto.UpdateScheduledMessageRequest, userID, scheduledID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError) {
+	if req.Content == nil && req.SendAt == nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "content or send_at is required", "body")
+	}
+
+	msg, err := c.findOwnScheduledMessage(ctx, userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	content, sendAt := msg.Content, msg.SendAt
+	if req.Content != nil {
+		content = *req.Content
+	}
+	if req.SendAt != nil {
+		if err := validateSendAt(*req.SendAt, now); err != nil {
+			return nil, err
+		}
+		sendAt = *req.SendAt
+	}
+
+	updated, err := c.ChatRepo.RescheduleMessage(ctx, msg.ID, msg.Version, content, sendAt, now)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, app_error.NewAppError(http.StatusConflict, "scheduled message was sent, cancelled or changed meanwhile", "conflict")
+	}
+
+	resp := toScheduledMessageResponse(updated)
+	return &resp, nil
+}
+
+// CancelScheduledMessage stops a pending message, a job that is already queued finds it cancelled and sends nothing
+func (c *ChatService) CancelScheduledMessage(ctx context.Context, userID, scheduledID string) (*chat_dto.ScheduledMessageResponse, *app_error.AppError) {
+	msg, err := c.findOwnScheduledMessage(ctx, userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := c.ChatRepo.CancelScheduledMessage(ctx, msg.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if cancelled == nil {
+		return nil, app_error.NewAppError(http.StatusConflict, "scheduled message is already being sent", "conflict")
+	}
+
+	resp := toScheduledMessageResponse(cancelled)
+	return &resp, nil
+}
+
+// DeliverScheduledMessage is run by the worker at the due time. It claims the message for the given version
+// and sends it through SendPrivateMessage. Returns nil without an error when there is nothing to send,
+// because the message was cancelled, edited after the job was queued or already delivered.
+func (c *ChatService) DeliverScheduledMessage(ctx context.Context, scheduledID string, version int) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError) {
+	id, parseErr := primitive.ObjectIDFromHex(scheduledID)
+	if parseErr != nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "invalid scheduled message ID", "invalid-id")
+	}
+
+	msg, err := c.ChatRepo.ClaimScheduledMessage(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		log.Info().Str("scheduled_id", scheduledID).Int("version", version).Msg("scheduled message is no longer due for this job, skipping")
+		return nil, nil
+	}
+
+	// the scheduled ID as client id makes a delivery after a crash replay the message stored by the first one
+	req := chat_dto.SendPrivateMessageRequest{Content: msg.Content, ClientMsgID: scheduledClientMsgID(msg.ID)}
+	resp, err := c.SendPrivateMessage(ctx, req, msg.SenderID, msg.ReceiverID)
+	if err != nil {
+		// server errors and a send still holding the client id hand the message back so the job retry can claim
+		// it again, anything else won't get better
+		status := entity.ScheduledStatusFailed
+		if err.Code >= http.StatusInternalServerError || err.Code == http.StatusConflict {
+			status = entity.ScheduledStatusPending
+		}
+		if finishErr := c.ChatRepo.FinishScheduledMessage(ctx, msg.ID, status, nil, err.Message, time.Now()); finishErr != nil {
+			log.Error().Str("scheduled_id", scheduledID).Str("error", finishErr.Message).Msg("failed to record scheduled message failure")
+		}
+		if status == entity.ScheduledStatusFailed {
+			log.Warn().Str("scheduled_id", scheduledID).Str("error", err.Message).Msg("scheduled message could not be sent")
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// the message is out, a failure here must not lead to a second send
+	msgID, _ := primitive.ObjectIDFromHex(resp.MessageID)
+	if err := c.ChatRepo.FinishScheduledMessage(ctx, msg.ID, entity.ScheduledStatusSent, &msgID, "", time.Now()); err != nil {
+		log.Error().Str("scheduled_id", scheduledID).Str("error", err.Message).Msg("failed to mark scheduled message as sent")
+	}
+
+	return resp, nil
+}
+
+// RecoverScheduledMessages looks for up to limit messages the queue lost track of before the cutoff: due messages
+// whose job expired or got lost and claims of a worker that died while sending. Stale claims are handed back to
+// pending. Returns the messages the caller has to deliver again, the client id keeps a message that was already
+// stored from being sent twice.
+func (c *ChatService) RecoverScheduledMessages(ctx context.Context, before time.Time, limit int) ([]chat_dto.ScheduledMessageResponse, *app_error.AppError) {
+	msgs, err := c.ChatRepo.FindOverdueScheduledMessages(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	recovered := make([]chat_dto.ScheduledMessageResponse, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.Status == entity.ScheduledStatusSending {
+			released, err := c.ChatRepo.ReleaseScheduledMessage(ctx, msg.ID, msg.ClaimedAt, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			if released == nil {
+				continue // the claim finished in the meantime
+			}
+			msg = released
+		}
+
+		recovered = append(recovered, toScheduledMessageResponse(msg))
+	}
+
+	return recovered, nil
+}
+
+// scheduledClientMsgID is the client message id a scheduled message is sent with
+func scheduledClientMsgID(id primitive.ObjectID) string {
+	return "scheduled:" + id.Hex()
+}
+
+// findOwnScheduledMessage hides scheduled messages of other users behind a 404
+func (c *ChatService) findOwnScheduledMessage(ctx context.Context, userID, scheduledID string) (*entity.ScheduledMessage, *app_error.AppError) {
+	msg, err := c.ChatRepo.FindScheduledMessageByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.SenderID != userID {
+		return nil, app_error.NewAppError(http.StatusNotFound, "scheduled message not found", "not-found")
+	}
+	if msg.Status != entity.ScheduledStatusPending {
+		return nil, app_error.NewAppError(http.StatusConflict, "scheduled message is already "+msg.Status, "conflict")
+	}
+
+	return msg, nil
+}
+
+func validateSendAt(sendAt, now time.Time) *app_error.AppError {
+	if !sendAt.After(now) {
+		return app_error.NewAppError(http.StatusBadRequest, "send_at must be in the future", "send_at")
+	}
+	if sendAt.After(now.Add(maxScheduleAhead())) {
+		return app_error.NewAppError(http.StatusBadRequest, "send_at is too far in the future", "send_at")
+	}
+
+	return nil
+}
+
+func toScheduledMessageResponse(msg *entity.ScheduledMessage) chat_dto.ScheduledMessageResponse {
+	return chat_dto.ScheduledMessageResponse{
+		ScheduledID: msg.ID.Hex(),
+		RoomID:      msg.RoomID,
+		ReceiverID:  msg.ReceiverID,
+		Content:     msg.Content,
+		SendAt:      msg.SendAt,
+		Status:      msg.Status,
+		Version:     msg.Version,
+		CreatedAt:   msg.CreatedAt,
+		UpdatedAt:   msg.UpdatedAt,
+	}
+}
+
+func maxScheduleAhead() time.Duration {
+	if config.Conf == nil || config.Conf.CHAT.MaxScheduleAhead <= 0 {
+		return defaultMaxScheduleAhead
+	}
+
+	return config.Conf.CHAT.MaxScheduleAhead
+}
+
+func maxScheduledPerUser() int {
+	if config.Conf == nil || config.Conf.CHAT.MaxScheduledPerUser <= 0 {
+		return defaultMaxScheduledPerUser
+	}
+
+	return config.Conf.CHAT.MaxScheduledPerUser
+}
@@ -0,0 +1,31 @@
+package chat_service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSendAt(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		sendAt time.Time
+		valid  bool
+	}{
+		{"past", now.Add(-time.Minute), false},
+		{"now", now, false},
+		{"next minute", now.Add(time.Minute), true},
+		{"at the limit", now.Add(defaultMaxScheduleAhead), true},
+		{"beyond the limit", now.Add(defaultMaxScheduleAhead + time.Second), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSendAt(tt.sendAt, now)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateSendAt(%v) error = %v, want valid %v", tt.sendAt, err, tt.valid)
+			}
+		})
+	}
+}
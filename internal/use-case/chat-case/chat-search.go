@@ -0,0 +1,181 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+const (
+	snippetLength  = 160 // characters of content shown around the first match
+	snippetContext = 40  // characters kept before the first match
+)
+
+// SearchMessages looks the query up in every room the caller is an active member of, or in req.RoomID only.
+// Results are newest first, the cursor is the oldest message of the page.
+func (c *ChatService) SearchMessages(ctx context.Context, req chat_dto.SearchMessagesRequest, userID string) (*chat_dto.SearchMessagesResponse, *app_error.AppError) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "from must be before to", "from")
+	}
+
+	// rooms the user has left are no longer theirs to search
+	roomIDs, err := c.ChatRepo.FindActiveRoomIDsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RoomID != nil {
+		if !slices.Contains(roomIDs, *req.RoomID) {
+			return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+		}
+		roomIDs = []string{*req.RoomID}
+	}
+
+	if len(roomIDs) == 0 {
+		return &chat_dto.SearchMessagesResponse{Results: []chat_dto.SearchResult{}}, nil
+	}
+
+	messages, err := c.ChatRepo.SearchMessages(ctx, entity.MessageSearchFilter{
+		Query:    req.Query,
+		ViewerID: userID,
+		RoomIDs:  roomIDs,
+		SenderID: req.SenderID,
+		From:     req.From,
+		To:       req.To,
+	}, limit, req.BeforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first, messages come back oldest first
+	results := make([]chat_dto.SearchResult, 0, len(messages))
+	for i := len(messages) - 1; i >= 0; i-- {
+		// the text index covers plain_text, markup would only get in the way of the snippet
+		snippet, highlights := buildSnippet(plainTextOf(messages[i]), req.Query)
+		results = append(results, chat_dto.SearchResult{
+			MessageID:  messages[i].ID.Hex(),
+			RoomID:     messages[i].RoomID,
+			SenderID:   messages[i].SenderID,
+			Content:    messages[i].Content,
+			Format:     messageFormat(messages[i].Format),
+			Snippet:    snippet,
+			Highlights: highlights,
+			CreatedAt:  messages[i].CreatedAt,
+		})
+	}
+
+	var nextCursor *string
+	if len(messages) > 0 {
+		oldestMsgID := messages[0].ID.Hex()
+		nextCursor = &oldestMsgID
+	}
+
+	return &chat_dto.SearchMessagesResponse{
+		Results:    results,
+		NextCursor: nextCursor,
+		HasMore:    len(messages) == limit,
+	}, nil
+}
+
+// buildSnippet cuts the part of content around the first word matching the query and returns
+// the ranges of every matching word inside the snippet. Matching is by prefix both ways, a rough
+// stand-in for the stemming of the text index ("run" highlights "running" and the other way around).
+func buildSnippet(content, query string) (string, []chat_dto.SearchHighlight) {
+	terms := searchTerms(query)
+	runes := []rune(content)
+
+	var matches []chat_dto.SearchHighlight
+	for _, word := range wordRanges(runes) {
+		lower := strings.ToLower(string(runes[word.Start:word.End]))
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) || (len([]rune(lower)) >= 3 && strings.HasPrefix(term, lower)) {
+				matches = append(matches, word)
+				break
+			}
+		}
+	}
+
+	start := 0
+	if len(matches) > 0 && matches[0].Start > snippetContext {
+		start = matches[0].Start - snippetContext
+	}
+	end := min(len(runes), start+snippetLength)
+	// near the end of the content, show more of what comes before instead
+	start = max(0, min(start, end-snippetLength))
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(runes) {
+		suffix = "…"
+	}
+	offset := len([]rune(prefix))
+
+	highlights := make([]chat_dto.SearchHighlight, 0, len(matches))
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			highlights = append(highlights, chat_dto.SearchHighlight{
+				Start: match.Start - start + offset,
+				End:   match.End - start + offset,
+			})
+		}
+	}
+
+	return prefix + string(runes[start:end]) + suffix, highlights
+}
+
+// searchTerms lowercases the words of a text query, negated words ("-word") are left out
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, term := range strings.FieldsFunc(strings.ToLower(field), isNotWordRune) {
+			if len([]rune(term)) >= 2 && !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return terms
+}
+
+// wordRanges returns the [start, end) rune ranges of the letter and digit runs of text
+func wordRanges(text []rune) []chat_dto.SearchHighlight {
+	var words []chat_dto.SearchHighlight
+	start := -1
+	for i, r := range text {
+		if isNotWordRune(r) {
+			if start >= 0 {
+				words = append(words, chat_dto.SearchHighlight{Start: start, End: i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, chat_dto.SearchHighlight{Start: start, End: len(text)})
+	}
+
+	return words
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,50 @@
+package chat_service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms(`Deploy "release notes" -draft deploy x`)
+	want := []string{"deploy", "release", "notes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildSnippet(t *testing.T) {
+	t.Run("short content is kept whole", func(t *testing.T) {
+		snippet, highlights := buildSnippet("Running the deploy now", "run deploy")
+		if snippet != "Running the deploy now" {
+			t.Errorf("snippet = %q", snippet)
+		}
+		want := []chat_dto.SearchHighlight{{Start: 0, End: 7}, {Start: 12, End: 18}}
+		if !reflect.DeepEqual(highlights, want) {
+			t.Errorf("highlights = %v, want %v", highlights, want)
+		}
+	})
+
+	t.Run("long content is cut around the first match", func(t *testing.T) {
+		content := strings.Repeat("filler ", 30) + "the pipeline failed " + strings.Repeat("filler ", 30)
+		snippet, highlights := buildSnippet(content, "pipeline")
+
+		runes := []rune(snippet)
+		if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+			t.Errorf("snippet should be cut on both ends: %q", snippet)
+		}
+		if len(highlights) != 1 || string(runes[highlights[0].Start:highlights[0].End]) != "pipeline" {
+			t.Errorf("highlights = %v in %q", highlights, snippet)
+		}
+	})
+
+	t.Run("no match shows the start of the content", func(t *testing.T) {
+		snippet, highlights := buildSnippet("héllo wörld", "missing")
+		if snippet != "héllo wörld" || len(highlights) != 0 {
+			t.Errorf("got %q %v", snippet, highlights)
+		}
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -12,6 +11,9 @@ import (
 	"github.com/xenn00/chat-system/internal/entity"
 	app_error "github.com/xenn00/chat-system/internal/errors"
 	chat_repo "github.com/xenn00/chat-system/internal/repo/chat"
+	user_repo "github.com/xenn00/chat-system/internal/repo/user"
+	"github.com/xenn00/chat-system/internal/storage"
+	"github.com/xenn00/chat-system/internal/unfurl"
 	"github.com/xenn00/chat-system/internal/utils"
 	"github.com/xenn00/chat-system/state"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,6 +22,9 @@ import (
 type ChatService struct {
 	AppState *state.AppState
 	ChatRepo chat_repo.ChatRepoContract
+	UserRepo user_repo.UserRepoContract
+	Blobs    storage.BlobStore
+	Fetcher  unfurl.Fetcher
 	// WS       *websocket.Hub
 }
 
@@ -27,6 +32,9 @@ func NewChatService(appState *state.AppState) ChatServiceContract {
 	return &ChatService{
 		AppState: appState,
 		ChatRepo: chat_repo.NewChatRepo(appState),
+		UserRepo: user_repo.NewUserRepo(appState),
+		Blobs:    storage.NewLocalStore(uploadDir()),
+		Fetcher:  unfurl.NewHTTPFetcher(),
 		// WS:       ws,
 	}
 }
@@ -38,24 +46,61 @@ func createMessageCacheKey(roomId string) string {
 }
 
 func (c *ChatService) SendPrivateMessage(ctx context.Context, req chat_dto.SendPrivateMessageRequest, senderID, receiverID string) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError) {
+	resp, replayed, err := sendOnce(ctx, c, senderID, req.ClientMsgID, func() (*chat_dto.SendPrivateMessageResponse, *app_error.AppError) {
+		return c.sendPrivateMessage(ctx, req, senderID, receiverID)
+	}, toSendPrivateMessageResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Replayed = replayed
+	return resp, nil
+}
+
+func (c *ChatService) sendPrivateMessage(ctx context.Context, req chat_dto.SendPrivateMessageRequest, senderID, receiverID string) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError) {
+	format := messageFormat(req.Format)
+	content, plainText := formatContent(format, req.Content)
+	if err := validateMessageBody(content, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
+
 	room, err := c.ChatRepo.FindOrCreateRoom(ctx, senderID, receiverID)
 	if err != nil {
 		return nil, err
 	}
 
+	_, members, err := c.findActiveRoomWithMembers(ctx, room.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+		return nil, err
+	}
+
 	msg := &entity.Message{
-		ID:         primitive.NewObjectID(),
-		RoomID:     room.ID.String(),
-		SenderID:   senderID,
-		ReceiverID: receiverID,
-		Content:    req.Content,
-		IsRead:     false,
-		IsEdited:   false,
-		CreatedAt:  time.Now(),
+		ID:          primitive.NewObjectID(),
+		RoomID:      room.ID.String(),
+		SenderID:    senderID,
+		ClientMsgID: req.ClientMsgID,
+		ReceiverID:  receiverID,
+		Content:     content,
+		Format:      format,
+		PlainText:   plainText,
+		Mentions:    c.resolveMentions(ctx, plainText, senderID, []string{receiverID}),
+		IsEdited:    false,
+		CreatedAt:   time.Now(),
+	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
+
+	msg.Attachments, err = c.attachUploads(ctx, senderID, req.AttachmentIDs, msg.RoomID, msg.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	msgId, err := c.ChatRepo.CreateMessage(ctx, msg)
 	if err != nil {
+		c.detachUploads(ctx, msg)
 		return nil, err
 	}
 
@@ -63,108 +108,196 @@ func (c *ChatService) SendPrivateMessage(ctx context.Context, req chat_dto.SendP
 		return nil, app_error.NewAppError(http.StatusInternalServerError, fmt.Sprintf("failed to update metadata message: %v", err), "update-room-meta")
 	}
 
-	return &chat_dto.SendPrivateMessageResponse{
-		MessageID:  msgId.Hex(),
-		RoomID:     room.ID.String(),
-		SenderID:   senderID,
-		ReceiverID: receiverID,
-		Content:    req.Content,
-		IsRead:     msg.IsRead,
-		CreatedAt:  room.CreatedAt,
-	}, nil
-}
-
-func (c *ChatService) GetPrivateMessage(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, roomID string) (*chat_dto.GetPrivateMessagesResponse, *app_error.AppError) {
-	// check cache
-	cacheKey := createMessageCacheKey(roomID)
+	resp := toSendPrivateMessageResponse(msg)
+	resp.Unread = c.bumpUnread(ctx, msg.RoomID, senderID, 1)
 
-	cachedMessage, err := utils.GetCacheData[chat_dto.GetPrivateMessagesResponse](c.AppState.Ctx, c.AppState.Redis, cacheKey)
-	if err != nil {
-		log.Warn().Msgf("cache miss, '%s'", cacheKey)
-	}
+	return resp, nil
+}
 
-	if cachedMessage != nil {
-		return cachedMessage, nil
+func toSendPrivateMessageResponse(msg *entity.Message) *chat_dto.SendPrivateMessageResponse {
+	return &chat_dto.SendPrivateMessageResponse{
+		MessageID:   msg.ID.Hex(),
+		ClientMsgID: msg.ClientMsgID,
+		RoomID:      msg.RoomID,
+		SenderID:    msg.SenderID,
+		ReceiverID:  msg.ReceiverID,
+		Content:     msg.Content,
+		Format:      messageFormat(msg.Format),
+		PlainText:   plainTextOf(msg),
+		Attachments: toAttachmentDTOs(msg.Attachments),
+		IsRead:      false,
+		CreatedAt:   msg.CreatedAt,
+		ExpiresAt:   msg.ExpiresAt,
+		Mentions:    msg.Mentions,
+		Mentioned:   msg.Mentions,
 	}
+}
 
+func (c *ChatService) GetPrivateMessage(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID, roomID string) (*chat_dto.GetPrivateMessagesResponse, *app_error.AppError) {
 	// validate room exist
 	room, err := c.ChatRepo.FindRoomByID(ctx, roomID)
 	if err != nil {
 		return nil, err
 	}
+
+	// read state is computed per caller from the member cursors, so members are always loaded fresh
+	members, err := c.ChatRepo.FindRoomMembers(ctx, room.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.isUserMemberOfRoom(members, userID) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
 	// get messages from repo (utilize cursor pagination)
 	limit := req.Limit
 	if limit == 0 {
 		limit = 20
 	}
 
-	messages, err := c.ChatRepo.GetPrivateMessages(ctx, room.ID.String(), limit, req.BeforeID)
-	if err != nil {
-		return nil, err
+	// only the latest page with the default size is cached
+	cacheable := req.BeforeID == nil && limit == 20
+	cacheKey := createMessageCacheKey(roomID)
+
+	var messages []*entity.Message
+	if cacheable {
+		cachedMessages, err := utils.GetCacheData[[]*entity.Message](c.AppState.Ctx, c.AppState.Redis, cacheKey)
+		if err != nil {
+			log.Warn().Msgf("cache miss, '%s'", cacheKey)
+		}
+		if cachedMessages != nil {
+			messages = *cachedMessages
+		}
+	}
+
+	if messages == nil {
+		messages, err = c.ChatRepo.GetPrivateMessages(ctx, room.ID.String(), limit, req.BeforeID)
+		if err != nil {
+			return nil, err
+		}
+
+		if cacheable {
+			utils.SetCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey, &messages, time.Minute*5)
+		}
 	}
+
 	// convert to dto
+	now := time.Now()
 	respMessages := make([]chat_dto.PrivateMessages, 0, len(messages))
 	for _, msg := range messages {
-		var replyTo *chat_dto.ReplyMessage
-		if msg.ReplyTo != nil {
-			replyTo = &chat_dto.ReplyMessage{
-				RepliedMessageID: msg.ReplyTo.MessageID.Hex(),
-				Content:          msg.ReplyTo.Content,
-				SenderID:         msg.ReplyTo.SenderID,
-			}
+		// the cached page is shared by both members, hide messages the caller deleted for themselves here
+		if isHiddenFor(msg, userID) {
+			continue
 		}
-		respMessages = append(respMessages, chat_dto.PrivateMessages{
-			MessageID:  msg.ID.Hex(),
-			RoomID:     msg.RoomID,
-			SenderID:   msg.SenderID,
-			ReceiverID: msg.ReceiverID,
-			Content:    msg.Content,
-			ReplyTo:    replyTo,
-			IsRead:     msg.IsRead,
-			CreatedAt:  msg.CreatedAt,
-		})
-	}
-	// // determine next cursor and has more
+		// the cached page may outlive a disappearing timer
+		if isExpired(msg, now) {
+			continue
+		}
+
+		respMessages = append(respMessages, toPrivateMessage(members, userID, msg))
+	}
+
+	// messages are in ascending order, the cursor for the next (older) page is the oldest one
 	var nextCursor *string
 	if len(messages) > 0 {
-		lastMsgID := messages[len(messages)-1].ID.Hex()
-		nextCursor = &lastMsgID
+		oldestMsgID := messages[0].ID.Hex()
+		nextCursor = &oldestMsgID
 	}
 
-	hasMore := len(messages) == req.Limit
-
-	res := &chat_dto.GetPrivateMessagesResponse{
+	return &chat_dto.GetPrivateMessagesResponse{
 		Messages:   respMessages,
 		NextCursor: nextCursor,
-		HasMore:    hasMore,
+		HasMore:    len(messages) == limit,
+	}, nil
+}
+
+// toPrivateMessage converts a stored message to the list dto, read and reaction state are relative to viewerID
+func toPrivateMessage(members []*entity.RoomMember, viewerID string, msg *entity.Message) chat_dto.PrivateMessages {
+	var replyTo *chat_dto.ReplyMessage
+	if msg.ReplyTo != nil {
+		replyTo = &chat_dto.ReplyMessage{
+			RepliedMessageID: msg.ReplyTo.MessageID.Hex(),
+			Content:          msg.ReplyTo.Content,
+			SenderID:         msg.ReplyTo.SenderID,
+		}
+	}
+
+	var threadRootID string
+	if msg.ThreadRootID != nil {
+		threadRootID = msg.ThreadRootID.Hex()
+	}
+
+	var forwardedFrom *chat_dto.ForwardedFrom
+	if msg.ForwardedFrom != nil {
+		forwardedFrom = &chat_dto.ForwardedFrom{
+			MessageID: msg.ForwardedFrom.MessageID.Hex(),
+			RoomID:    msg.ForwardedFrom.RoomID,
+			SenderID:  msg.ForwardedFrom.SenderID,
+		}
+	}
+
+	return chat_dto.PrivateMessages{
+		MessageID:     msg.ID.Hex(),
+		RoomID:        msg.RoomID,
+		SenderID:      msg.SenderID,
+		ReceiverID:    msg.ReceiverID,
+		MessageType:   messageType(msg.Type),
+		Content:       msg.Content,
+		Format:        messageFormat(msg.Format),
+		PlainText:     plainTextOf(msg),
+		Poll:          toPollDTO(msg.Poll, viewerID, time.Now()),
+		SystemData:    msg.SystemData,
+		ReplyTo:       replyTo,
+		IsRead:        isReadFor(members, viewerID, msg),
+		IsEdited:      msg.IsEdited,
+		IsDeleted:     msg.IsDeleted,
+		Reactions:     summarizeReactions(msg.Reactions, viewerID),
+		ForwardedFrom: forwardedFrom,
+		Attachments:   toAttachmentDTOs(msg.Attachments),
+		LinkPreviews:  toLinkPreviewDTOs(msg.LinkPreviews),
+		ThreadRootID:  threadRootID,
+		ReplyCount:    msg.ReplyCount,
+		LastReplyAt:   msg.LastReplyAt,
+		CreatedAt:     msg.CreatedAt,
+		ExpiresAt:     msg.ExpiresAt,
 	}
-	// set cache
-	utils.SetCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey, res, time.Minute*5)
-	// // return
-	return res, nil
 }
 
 func (c *ChatService) ReplyPrivateMessage(ctx context.Context, req chat_dto.ReplyPrivateMessageRequest, senderID, roomID string) (*chat_dto.ReplyPrivateMessageResponse, *app_error.AppError) {
-	// validate room exist
-	if _, err := c.ChatRepo.FindRoomByID(ctx, roomID); err != nil {
+	resp, replayed, err := sendOnce(ctx, c, senderID, req.ClientMsgID, func() (*chat_dto.ReplyPrivateMessageResponse, *app_error.AppError) {
+		return c.replyPrivateMessage(ctx, req, senderID, roomID)
+	}, toReplyPrivateMessageResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Replayed = replayed
+	return resp, nil
+}
+
+func (c *ChatService) replyPrivateMessage(ctx context.Context, req chat_dto.ReplyPrivateMessageRequest, senderID, roomID string) (*chat_dto.ReplyPrivateMessageResponse, *app_error.AppError) {
+	format := messageFormat(req.Format)
+	content, plainText := formatContent(format, req.Content)
+	if err := validateMessageBody(content, req.AttachmentIDs); err != nil {
 		return nil, err
 	}
-	// validate room member (sender and receiver is member of the room)
-	members, err := c.ChatRepo.FindRoomMembers(ctx, roomID)
+
+	room, members, err := c.findActiveRoomWithMembers(ctx, roomID)
 	if err != nil {
 		return nil, err
 	}
-	if len(members) != 2 {
-		return nil, app_error.NewAppError(http.StatusBadRequest, "room must have exactly 2 members, not private", "invalid-room")
+
+	if room.RT != entity.RoomTypePrivate {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "room is not a private room", "invalid-room")
 	}
-	isMember := false
-	for _, member := range members {
-		if member.UserID == senderID || member.UserID == req.ReceiverID {
-			isMember = true
-		}
+
+	if _, err := c.authorize(members, senderID, ActionPostMessage); err != nil {
+		return nil, err
 	}
-	if !isMember {
-		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+
+	if privateReceiverID(room, members, senderID) != req.ReceiverID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "receiver is not the other member of this room", "receiver_id")
 	}
 	// validate reply_to message exist in the room
 	repliedMsg, err := c.ChatRepo.FindMessageByID(ctx, req.ReplyTo)
@@ -176,197 +309,112 @@ func (c *ChatService) ReplyPrivateMessage(ctx context.Context, req chat_dto.Repl
 		return nil, app_error.NewAppError(http.StatusBadRequest, "the message you are replying to does not belong to this room", "forbidden")
 	}
 
+	if repliedMsg.IsDeleted {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message you are replying to was deleted", "deleted")
+	}
+
 	msg := &entity.Message{
-		ID:         primitive.NewObjectID(),
-		RoomID:     roomID,
-		SenderID:   senderID,
-		ReceiverID: req.ReceiverID,
-		Content:    req.Content,
+		ID:          primitive.NewObjectID(),
+		RoomID:      roomID,
+		SenderID:    senderID,
+		ClientMsgID: req.ClientMsgID,
+		ReceiverID:  req.ReceiverID,
+		Content:     content,
+		Format:      format,
+		PlainText:   plainText,
+		Mentions:    c.resolveMentions(ctx, plainText, senderID, activeMemberIDs(members)),
 		ReplyTo: &entity.ReplyTo{
 			MessageID: repliedMsg.ID,
-			Content:   repliedMsg.Content,
+			Content:   plainTextOf(repliedMsg), // quotes carry no markup, they are shown without the format
 			SenderID:  repliedMsg.SenderID,
 		},
-		IsRead:    false,
-		IsEdited:  false,
-		CreatedAt: time.Now(),
+		ThreadRootID: threadRootOf(repliedMsg),
+		IsEdited:     false,
+		CreatedAt:    time.Now(),
 	}
+	msg.ExpiresAt = messageExpiry(room, msg.CreatedAt)
 
-	objID, err := c.ChatRepo.ReplyMessage(ctx, msg)
+	msg.Attachments, err = c.attachUploads(ctx, senderID, req.AttachmentIDs, roomID, msg.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// invalidate cache key
-	cacheKey := createMessageCacheKey(roomID)
-	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
-
-	// response with reply message dto
-	return &chat_dto.ReplyPrivateMessageResponse{
-		MessageID:  objID.Hex(),
-		RoomID:     roomID,
-		SenderID:   senderID,
-		ReceiverID: msg.ReceiverID,
-		Content:    msg.Content,
-		ReplyTo: &chat_dto.ReplyMessage{
-			RepliedMessageID: repliedMsg.ID.Hex(),
-			Content:          repliedMsg.Content,
-			SenderID:         repliedMsg.SenderID,
-		},
-		IsRead:    msg.IsRead,
-		CreatedAt: msg.CreatedAt,
-	}, nil
-}
-
-func (c *ChatService) MarkPrivateMessageAsRead(ctx context.Context, receiverID, roomID, messageID string) *app_error.AppError {
-	// validate room, message, and receiver is member of the room
-	roomMember, err := c.ChatRepo.FindRoomMembers(ctx, roomID)
-	if err != nil {
-		return err
-	}
-
-	if len(roomMember) != PrivateRoomMemberCount {
-		return app_error.NewAppError(http.StatusBadRequest, "room must have exactly 2 members, not private", "invalid-room")
-	}
-
-	if !c.isUserMemberOfRoom(roomMember, receiverID) {
-		return app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
-	}
-
-	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	_, err = c.ChatRepo.ReplyMessage(ctx, msg)
 	if err != nil {
-		return err
-	}
-
-	if msg.RoomID != roomID {
-		return app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
-	}
-
-	if msg.SenderID == receiverID {
-		return app_error.NewAppError(http.StatusBadRequest, "cannot mark your own message as read", "invalid-action")
+		c.detachUploads(ctx, msg)
+		return nil, err
 	}
 
-	if msg.IsRead {
-		return nil
-	}
+	thread := c.recordThreadReply(ctx, msg, members)
 
 	// invalidate cache key
 	cacheKey := createMessageCacheKey(roomID)
 	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
 
-	return c.ChatRepo.MarkMessageAsRead(ctx, messageID)
+	// response with reply message dto
+	resp := toReplyPrivateMessageResponse(msg)
+	resp.Thread = thread
+	resp.Unread = c.bumpUnread(ctx, msg.RoomID, senderID, 1)
+
+	return resp, nil
 }
 
-func (c *ChatService) UpdatePrivateMessage(ctx context.Context, req chat_dto.UpdatePrivateMessageRequest, senderID, roomID, messageID string) (*chat_dto.UpdatePrivateMessageResponse, *app_error.AppError) {
-	// get original message
-	originalMsg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
-	if err != nil {
-		return nil, err
-	}
-	log.Info().Msgf("room id: %s", originalMsg.RoomID)
-	// authorization check
-	if originalMsg.SenderID != senderID {
-		return nil, app_error.NewAppError(http.StatusForbidden, "You can only update your own message", "authorization")
-	}
-	if originalMsg.RoomID != roomID {
-		return nil, app_error.NewAppError(http.StatusForbidden, "You are not a member of this chat room", "authorization")
-	}
-	// Time window check
-	editWindow := 15 * time.Minute
-	if time.Since(originalMsg.CreatedAt) > editWindow {
-		return nil, app_error.NewAppError(http.StatusForbidden, "Message edit time window expired", "time_expired")
-	}
-	// room & membership validation
-	room, err := c.ChatRepo.FindRoomByID(ctx, originalMsg.RoomID)
-	log.Info().Msgf("room deletedAt: %v", room.DeletedAt)
-	log.Info().Msgf("room err: %v", err)
-	if err != nil || room.DeletedAt != nil {
-		return nil, app_error.NewAppError(http.StatusNotFound, "Room not found or inactive", "room")
-	}
-	member, err := c.ChatRepo.FindRoomMembers(ctx, originalMsg.RoomID)
-	log.Info().Msgf("is member: %v", c.isUserMemberOfRoom(member, senderID))
-	if err != nil || !c.isUserMemberOfRoom(member, senderID) {
-		log.Error().Err(err).Msgf("an error occur: %v", err)
-		return nil, app_error.NewAppError(http.StatusForbidden, fmt.Sprintf("%s, you are not a member of this room", senderID), "forbidden")
-	}
-	// Content validation (no empty, different from original)
-	if strings.TrimSpace(req.Content) == strings.TrimSpace(originalMsg.Content) {
-		return nil, app_error.NewAppError(http.StatusBadRequest, "New content must be different", "content")
-	}
-	// update message with optimistic locking
-	now := time.Now()
-	updatedMsg := &entity.Message{
-		ID:        originalMsg.ID,
-		Content:   req.Content,
-		IsEdited:  true,
-		UpdatedAt: &now,
+func toReplyPrivateMessageResponse(msg *entity.Message) *chat_dto.ReplyPrivateMessageResponse {
+	resp := &chat_dto.ReplyPrivateMessageResponse{
+		MessageID:   msg.ID.Hex(),
+		ClientMsgID: msg.ClientMsgID,
+		RoomID:      msg.RoomID,
+		SenderID:    msg.SenderID,
+		ReceiverID:  msg.ReceiverID,
+		Content:     msg.Content,
+		Format:      messageFormat(msg.Format),
+		PlainText:   plainTextOf(msg),
+		Attachments: toAttachmentDTOs(msg.Attachments),
+		IsRead:      false,
+		CreatedAt:   msg.CreatedAt,
+		ExpiresAt:   msg.ExpiresAt,
+		Mentions:    msg.Mentions,
+		Mentioned:   msg.Mentions,
+	}
+
+	if msg.ReplyTo != nil {
+		resp.ReplyTo = &chat_dto.ReplyMessage{
+			RepliedMessageID: msg.ReplyTo.MessageID.Hex(),
+			Content:          msg.ReplyTo.Content,
+			SenderID:         msg.ReplyTo.SenderID,
+		}
 	}
 
-	messageEdit := &entity.MessageEditEntry{
-		MessageID:       originalMsg.ID,
-		OriginalContent: originalMsg.Content,
-		NewContent:      req.Content,
-		EditedBy:        originalMsg.SenderID,
-		EditedAt:        now,
-	}
+	return resp
+}
 
-	err = c.ChatRepo.UpdateMessage(ctx, updatedMsg, messageEdit, originalMsg.UpdatedAt)
+func (c *ChatService) UpdatePrivateMessage(ctx context.Context, req chat_dto.UpdatePrivateMessageRequest, senderID, roomID, messageID string) (*chat_dto.UpdatePrivateMessageResponse, *app_error.AppError) {
+	edit, err := c.editMessage(ctx, senderID, roomID, messageID, req.Format, req.Content)
 	if err != nil {
 		return nil, err
 	}
 
-	// invalidate cache key
-	cacheKey := createMessageCacheKey(roomID)
-	utils.DeleteCacheData(c.AppState.Ctx, c.AppState.Redis, cacheKey)
-
-	messageHistory := make([]*chat_dto.MessageEditEntry, 0)
-
-	if len(originalMsg.MessageEditHistory) == 0 {
-		messageHistory = append(messageHistory, &chat_dto.MessageEditEntry{
-			MessageID:       messageEdit.MessageID.Hex(),
-			OriginalContent: messageEdit.OriginalContent,
-			NewContent:      messageEdit.NewContent,
-			EditedBy:        messageEdit.EditedBy,
-			EditedAt:        messageEdit.EditedAt,
-		})
-	} else {
-		for _, entry := range originalMsg.MessageEditHistory {
-			messageHistory = append(messageHistory, &chat_dto.MessageEditEntry{
-				MessageID:       entry.MessageID.Hex(),
-				OriginalContent: entry.OriginalContent,
-				NewContent:      entry.NewContent,
-				EditedBy:        entry.EditedBy,
-				EditedAt:        entry.EditedAt,
-			})
-		}
-	}
-
-	var replyTo *chat_dto.ReplyMessage
-	if originalMsg.ReplyTo != nil {
-		replyTo = &chat_dto.ReplyMessage{
-			RepliedMessageID: originalMsg.ReplyTo.MessageID.Hex(),
-			Content:          originalMsg.ReplyTo.Content,
-			SenderID:         originalMsg.ReplyTo.SenderID,
-		}
-	}
-
 	return &chat_dto.UpdatePrivateMessageResponse{
-		MessageID:          originalMsg.ID.Hex(),
-		RoomID:             originalMsg.RoomID,
-		SenderID:           originalMsg.SenderID,
-		ReceiverID:         originalMsg.ReceiverID,
-		Content:            updatedMsg.Content,
-		MessageEditHistory: messageHistory,
-		ReplyTo:            replyTo,
-		IsRead:             originalMsg.IsRead,
-		IsEdited:           updatedMsg.IsEdited,
-		UpdatedAt:          *updatedMsg.UpdatedAt,
+		MessageID:          edit.original.ID.Hex(),
+		RoomID:             edit.original.RoomID,
+		SenderID:           edit.original.SenderID,
+		ReceiverID:         edit.original.ReceiverID,
+		Content:            edit.edited.Content,
+		Format:             edit.edited.Format,
+		PlainText:          edit.edited.PlainText,
+		MessageEditHistory: toEditHistoryDTOs(edit.history),
+		ReplyTo:            toReplyMessage(edit.original.ReplyTo),
+		IsRead:             isReadFor(edit.members, senderID, edit.original),
+		IsEdited:           edit.edited.IsEdited,
+		EditedBy:           senderID,
+		UpdatedAt:          *edit.edited.UpdatedAt,
+		Mentions:           edit.edited.Mentions,
+		Mentioned:          newMentions(edit.original.Mentions, edit.edited.Mentions),
 	}, nil
 }
 
 func (c *ChatService) isUserMemberOfRoom(members []*entity.RoomMember, userID string) bool {
 	for _, member := range members {
-		log.Info().Msgf("member_id: %v", member.UserID)
 		if member.UserID == userID {
 			return member.LeftAt == nil
 		}
@@ -0,0 +1,110 @@
+package chat_service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (c *ChatService) GetThread(ctx context.Context, req chat_dto.GetPrivateMessagesRequest, userID, roomID, messageID string) (*chat_dto.ThreadResponse, *app_error.AppError) {
+	_, members, err := c.findActiveRoomWithMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.findActiveMember(members, userID) == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	root, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.RoomID != roomID {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "the message does not belong to this room", "forbidden")
+	}
+
+	// asking for the thread of a reply resolves to the thread it belongs to
+	if root.ThreadRootID != nil {
+		root, err = c.ChatRepo.FindMessageByID(ctx, root.ThreadRootID.Hex())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	replies, err := c.ChatRepo.GetThreadReplies(ctx, root.ID, limit, req.BeforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	respReplies := make([]chat_dto.PrivateMessages, 0, len(replies))
+	for _, reply := range replies {
+		if isHiddenFor(reply, userID) {
+			continue
+		}
+		respReplies = append(respReplies, toPrivateMessage(members, userID, reply))
+	}
+
+	// same cursor scheme as the room history, the next (older) page starts before the oldest reply
+	var nextCursor *string
+	if len(replies) > 0 {
+		oldestReplyID := replies[0].ID.Hex()
+		nextCursor = &oldestReplyID
+	}
+
+	return &chat_dto.ThreadResponse{
+		Root:       toPrivateMessage(members, userID, root),
+		Replies:    respReplies,
+		NextCursor: nextCursor,
+		HasMore:    len(replies) == limit,
+	}, nil
+}
+
+// threadRootOf returns the thread a reply to msg joins, replies to a reply stay in the same thread
+func threadRootOf(msg *entity.Message) *primitive.ObjectID {
+	if msg.ThreadRootID != nil {
+		return msg.ThreadRootID
+	}
+
+	rootID := msg.ID
+	return &rootID
+}
+
+// recordThreadReply updates the thread root of a stored reply. The reply itself is already saved,
+// so a failure here only costs the thread_updated event and is logged instead of returned.
+func (c *ChatService) recordThreadReply(ctx context.Context, reply *entity.Message, members []*entity.RoomMember) *chat_dto.ThreadSummary {
+	root, err := c.ChatRepo.BumpThread(ctx, *reply.ThreadRootID, reply.SenderID, reply.CreatedAt)
+	if err != nil {
+		log.Warn().Msgf("failed to update thread %s: %s", reply.ThreadRootID.Hex(), err.Message)
+		return nil
+	}
+
+	participants := make([]string, 0, len(root.ThreadParticipants))
+	for _, userID := range root.ThreadParticipants {
+		if c.findActiveMember(members, userID) != nil {
+			participants = append(participants, userID)
+		}
+	}
+
+	summary := &chat_dto.ThreadSummary{
+		RootMessageID: root.ID.Hex(),
+		ReplyCount:    root.ReplyCount,
+		Participants:  participants,
+	}
+	if root.LastReplyAt != nil {
+		summary.LastReplyAt = *root.LastReplyAt
+	}
+
+	return summary
+}
@@ -0,0 +1,141 @@
+package chat_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/unfurl"
+	"github.com/xenn00/chat-system/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxLinkPreviews     = 3
+	linkPreviewCacheTTL = 24 * time.Hour
+	// links without a preview are cached too, so a dead link is not fetched for every message
+	emptyPreviewCacheTTL = time.Hour
+	unfurlTimeout        = 15 * time.Second
+)
+
+func createLinkPreviewCacheKey(link string) string {
+	sum := sha256.Sum256([]byte(link))
+	return "unfurl:" + hex.EncodeToString(sum[:])
+}
+
+// UnfurlMessageLinks fetches the previews of the first links in a message and stores them on it, previews of
+// links that were edited away are removed. Returns the updated message for a message_updated event, nil when
+// nothing changed or the message is gone.
+func (c *ChatService) UnfurlMessageLinks(ctx context.Context, messageID string) (*chat_dto.RoomMessageResponse, *app_error.AppError) {
+	msgID, convErr := primitive.ObjectIDFromHex(messageID)
+	if convErr != nil {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "invalid message id", "message_id")
+	}
+
+	msg, err := c.ChatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if msg.IsDeleted {
+		return nil, nil
+	}
+
+	var previews []*entity.LinkPreview
+	for _, link := range unfurl.ExtractURLs(msg.Content, maxLinkPreviews) {
+		if preview := c.linkPreview(ctx, link); preview != nil {
+			previews = append(previews, preview)
+		}
+	}
+
+	if len(previews) == 0 && len(msg.LinkPreviews) == 0 {
+		return nil, nil
+	}
+
+	// edited or deleted while fetching, the job enqueued by the edit takes over
+	updated, err := c.ChatRepo.SetLinkPreviews(ctx, msgID, msg.Content, previews)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, nil
+	}
+
+	return c.messageUpdatedResponse(ctx, updated)
+}
+
+// linkPreview returns the cached preview of a link or fetches it, nil when the page has nothing to show
+func (c *ChatService) linkPreview(ctx context.Context, link string) *entity.LinkPreview {
+	cacheKey := createLinkPreviewCacheKey(link)
+	cached, cacheErr := utils.GetCacheData[entity.LinkPreview](ctx, c.AppState.Redis, cacheKey)
+	if cacheErr == nil && cached != nil {
+		if !hasPreviewContent(cached) {
+			return nil
+		}
+		return cached
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, unfurlTimeout)
+	defer cancel()
+
+	preview := &entity.LinkPreview{URL: link}
+	fetched, err := c.Fetcher.Fetch(fetchCtx, link)
+	if err != nil {
+		// shutting down, the retry should fetch the link again
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Debug().Err(err).Str("url", link).Msg("failed to unfurl link")
+	} else {
+		preview.Title = fetched.Title
+		preview.Description = fetched.Description
+		preview.ImageURL = fetched.ImageURL
+		preview.SiteName = fetched.SiteName
+	}
+
+	ttl := emptyPreviewCacheTTL
+	if hasPreviewContent(preview) {
+		ttl = linkPreviewCacheTTL
+	}
+	if err := utils.SetCacheData(ctx, c.AppState.Redis, cacheKey, preview, ttl); err != nil {
+		log.Warn().Err(err).Str("url", link).Msg("failed to cache link preview")
+	}
+
+	if !hasPreviewContent(preview) {
+		return nil
+	}
+
+	return preview
+}
+
+func hasPreviewContent(preview *entity.LinkPreview) bool {
+	return preview.Title != "" || preview.Description != "" || preview.ImageURL != ""
+}
+
+func toLinkPreviewDTOs(previews []*entity.LinkPreview) []*chat_dto.LinkPreview {
+	if len(previews) == 0 {
+		return nil
+	}
+
+	dtos := make([]*chat_dto.LinkPreview, 0, len(previews))
+	for _, preview := range previews {
+		dtos = append(dtos, &chat_dto.LinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		})
+	}
+
+	return dtos
+}
@@ -0,0 +1,62 @@
+package chat_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/unfurl"
+	"github.com/xenn00/chat-system/state"
+)
+
+// stubFetcher serves previews from memory and counts the fetches
+type stubFetcher struct {
+	previews map[string]*unfurl.Preview
+	calls    int
+}
+
+func (s *stubFetcher) Fetch(ctx context.Context, rawURL string) (*unfurl.Preview, error) {
+	s.calls++
+	preview, ok := s.previews[rawURL]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return preview, nil
+}
+
+func TestLinkPreviewIsCachedByURL(t *testing.T) {
+	mockRedis := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mockRedis.Addr()})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	fetcher := &stubFetcher{previews: map[string]*unfurl.Preview{
+		"https://example.com": {Title: "Example", ImageURL: "https://example.com/cover.png"},
+	}}
+	c := &ChatService{AppState: &state.AppState{Ctx: ctx, Redis: rdb}, Fetcher: fetcher}
+
+	for range 2 {
+		preview := c.linkPreview(ctx, "https://example.com")
+		if preview == nil || preview.Title != "Example" || preview.URL != "https://example.com" {
+			t.Fatalf("linkPreview() = %+v", preview)
+		}
+	}
+	if fetcher.calls != 1 {
+		t.Errorf("fetched %d times, want 1", fetcher.calls)
+	}
+
+	// failures are cached as empty previews
+	for range 2 {
+		if preview := c.linkPreview(ctx, "https://example.com/missing"); preview != nil {
+			t.Fatalf("linkPreview() = %+v, want nil", preview)
+		}
+	}
+	if fetcher.calls != 2 {
+		t.Errorf("fetched %d times, want 2", fetcher.calls)
+	}
+	if ttl := mockRedis.TTL(createLinkPreviewCacheKey("https://example.com/missing")); ttl != emptyPreviewCacheTTL {
+		t.Errorf("empty preview ttl = %v, want %v", ttl, emptyPreviewCacheTTL)
+	}
+}
@@ -0,0 +1,45 @@
+package chat_service
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bumpUnread counts the messages a send added to the room as unread for every other active member. The message is
+// already stored, so a failure is only logged, the next read of the members recounts their counter.
+func (c *ChatService) bumpUnread(ctx context.Context, roomID, senderID string, added int) map[string]int64 {
+	if added == 0 {
+		return nil
+	}
+
+	counts, err := c.ChatRepo.IncrementUnread(ctx, roomID, senderID, int64(added))
+	if err != nil {
+		log.Error().Str("room_id", roomID).Msgf("failed to update unread counters: %s", err.Message)
+		return nil
+	}
+
+	return counts
+}
+
+// resetUnread recounts what is left unread after the read cursor of the user moved to msgID
+func (c *ChatService) resetUnread(ctx context.Context, roomID, userID string, msgID primitive.ObjectID) (int64, *app_error.AppError) {
+	count, err := c.ChatRepo.CountUnreadMessages(ctx, roomID, userID, msgID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.ChatRepo.SetUnread(ctx, roomID, userID, count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ReconcileUnreadCounts writes up to limit unread counters changed in Redis back to room_members, run by the
+// worker. Returns how many counters were written.
+func (c *ChatService) ReconcileUnreadCounts(ctx context.Context, limit int) (int, *app_error.AppError) {
+	return c.ChatRepo.ReconcileUnreadCounts(ctx, int64(limit))
+}
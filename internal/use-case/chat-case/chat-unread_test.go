@@ -0,0 +1,41 @@
+package chat_service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	chat_repo "github.com/xenn00/chat-system/internal/repo/chat"
+	"github.com/xenn00/chat-system/state"
+)
+
+func TestUnreadCounters(t *testing.T) {
+	mockRedis := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mockRedis.Addr()})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	repo := chat_repo.NewChatRepo(&state.AppState{Ctx: ctx, Redis: rdb})
+
+	mockRedis.Set(chat_repo.InboxCacheKey("u1"), "{}")
+	if err := repo.SetUnread(ctx, "r1", "u1", 3); err != nil {
+		t.Fatalf("SetUnread() error = %v", err.Message)
+	}
+
+	counts, err := repo.FindUnreadCounts(ctx, "u1", []string{"r1", "r2"})
+	if err != nil {
+		t.Fatalf("FindUnreadCounts() error = %v", err.Message)
+	}
+	if len(counts) != 1 || counts["r1"] != 3 {
+		t.Errorf("FindUnreadCounts() = %v, want only r1 at 3", counts)
+	}
+
+	// the counter waits for the reconciler and the cached inbox no longer shows the old count
+	if ok, _ := mockRedis.SIsMember("unread:dirty", "r1:u1"); !ok {
+		t.Error("SetUnread() did not mark the counter as changed")
+	}
+	if mockRedis.Exists(chat_repo.InboxCacheKey("u1")) {
+		t.Error("SetUnread() kept the cached inbox")
+	}
+}
@@ -0,0 +1,292 @@
+package chat_service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/config"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultUploadDir     = "./uploads"
+	defaultMaxUploadSize = 10 << 20
+	defaultSignedURLTTL  = 15 * time.Minute
+
+	maxFilenameLength = 255
+	mimeSniffLength   = 512 // bytes http.DetectContentType looks at
+)
+
+// allowedUploadTypes are the sniffed content types accepted for attachments, the type claimed by the client is ignored
+var allowedUploadTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"video/mp4":       true,
+	"video/webm":      true,
+	"audio/mpeg":      true,
+	"audio/wave":      true,
+	"application/ogg": true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+// UploadAttachment stores a file for a later message, the content type is sniffed from the first bytes
+func (c *ChatService) UploadAttachment(ctx context.Context, uploaderID, filename string, content io.Reader) (*chat_dto.UploadResponse, *app_error.AppError) {
+	head := make([]byte, mimeSniffLength)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, uploadReadError(err)
+	}
+	if n == 0 {
+		return nil, app_error.NewAppError(http.StatusBadRequest, "file is empty", "file")
+	}
+	head = head[:n]
+
+	mimeType := sniffMimeType(head)
+	if !allowedUploadTypes[mimeType] {
+		return nil, app_error.NewAppError(http.StatusUnsupportedMediaType, fmt.Sprintf("files of type %s are not allowed", mimeType), "file")
+	}
+
+	upload := &entity.FileUpload{
+		ID:         uuid.New().String(),
+		UploaderID: uploaderID,
+		Filename:   sanitizeFilename(filename),
+		MimeType:   mimeType,
+	}
+	upload.StorageKey = fmt.Sprintf("%s/%s", uploaderID, upload.ID)
+
+	// read one byte past the limit so an oversized file is detected without trusting the declared size
+	limit := MaxUploadSize()
+	size, err := c.Blobs.Put(ctx, upload.StorageKey, io.LimitReader(io.MultiReader(bytes.NewReader(head), content), limit+1))
+	if err != nil {
+		return nil, uploadReadError(err)
+	}
+
+	if size > limit {
+		c.deleteBlob(ctx, upload.StorageKey)
+		return nil, app_error.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d bytes", limit), "file")
+	}
+	upload.Size = size
+
+	if err := c.ChatRepo.CreateUpload(ctx, upload); err != nil {
+		c.deleteBlob(ctx, upload.StorageKey)
+		return nil, err
+	}
+
+	return &chat_dto.UploadResponse{
+		UploadID:  upload.ID,
+		Type:      attachmentType(upload.MimeType),
+		Filename:  upload.Filename,
+		MimeType:  upload.MimeType,
+		Size:      upload.Size,
+		URL:       storage.SignURL(fileURLPath(upload.ID), time.Now().Add(signedURLTTL())),
+		CreatedAt: upload.CreatedAt,
+	}, nil
+}
+
+// OpenUpload opens a stored file for download, the caller checks the signed URL and closes the reader
+func (c *ChatService) OpenUpload(ctx context.Context, uploadID string) (*chat_dto.UploadResponse, io.ReadCloser, *app_error.AppError) {
+	upload, err := c.ChatRepo.FindUploadByID(ctx, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := c.openBlob(ctx, upload.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &chat_dto.UploadResponse{
+		UploadID:  upload.ID,
+		Type:      attachmentType(upload.MimeType),
+		Filename:  upload.Filename,
+		MimeType:  upload.MimeType,
+		Size:      upload.Size,
+		CreatedAt: upload.CreatedAt,
+	}, blob, nil
+}
+
+// attachUploads claims the uploads for a message that is about to be stored, returns nil without uploads
+func (c *ChatService) attachUploads(ctx context.Context, senderID string, uploadIDs []string, roomID string, msgID primitive.ObjectID) ([]*entity.Attachment, *app_error.AppError) {
+	if len(uploadIDs) == 0 {
+		return nil, nil
+	}
+
+	uploads, err := c.ChatRepo.AttachUploads(ctx, senderID, uploadIDs, roomID, msgID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*entity.Attachment, 0, len(uploads))
+	for _, upload := range uploads {
+		attachments = append(attachments, &entity.Attachment{
+			Type:     attachmentType(upload.MimeType),
+			URL:      fileURLPath(upload.ID),
+			UploadID: upload.ID,
+			Filename: upload.Filename,
+			Size:     upload.Size,
+			MimeType: upload.MimeType,
+		})
+	}
+
+	return attachments, nil
+}
+
+// detachUploads frees the uploads of a message that failed to be stored, so the sender can retry with them
+func (c *ChatService) detachUploads(ctx context.Context, msg *entity.Message) {
+	if len(msg.Attachments) == 0 {
+		return
+	}
+
+	if err := c.ChatRepo.DetachUploads(ctx, msg.ID.Hex()); err != nil {
+		log.Error().Str("message_id", msg.ID.Hex()).Msgf("failed to detach uploads: %v", err.Message)
+	}
+}
+
+func (c *ChatService) deleteBlob(ctx context.Context, key string) {
+	if err := c.Blobs.Delete(ctx, key); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("failed to delete blob")
+	}
+}
+
+// validateMessageBody rejects messages without content and without attachments
+func validateMessageBody(content string, attachmentIDs []string) *app_error.AppError {
+	if content == "" && len(attachmentIDs) == 0 {
+		return app_error.NewAppError(http.StatusBadRequest, "a message needs content or attachments", "content")
+	}
+
+	return nil
+}
+
+// toAttachmentDTOs signs the download URL of every uploaded attachment, the links expire after the signed URL TTL
+func toAttachmentDTOs(attachments []*entity.Attachment) []*chat_dto.Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(signedURLTTL())
+	dtos := make([]*chat_dto.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		url, thumbnailURL := attachment.URL, attachment.ThumbnailURL
+		if attachment.UploadID != "" {
+			url = storage.SignURL(attachment.URL, expiresAt)
+			if thumbnailURL != "" {
+				thumbnailURL = storage.SignURL(thumbnailURL, expiresAt)
+			}
+		}
+
+		dtos = append(dtos, &chat_dto.Attachment{
+			Type:         attachment.Type,
+			URL:          url,
+			UploadID:     attachment.UploadID,
+			Filename:     attachment.Filename,
+			Size:         attachment.Size,
+			MimeType:     attachment.MimeType,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+			ThumbnailURL: thumbnailURL,
+		})
+	}
+
+	return dtos
+}
+
+func fileURLPath(uploadID string) string {
+	return "/api/v1/files/" + uploadID
+}
+
+// sniffMimeType detects the content type of the first bytes of a file, without parameters like charset
+func sniffMimeType(head []byte) string {
+	mimeType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return strings.TrimSpace(mimeType)
+}
+
+func attachmentType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return entity.AttachmentTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return entity.AttachmentTypeVideo
+	case strings.HasPrefix(mimeType, "audio/"), mimeType == "application/ogg":
+		return entity.AttachmentTypeAudio
+	default:
+		return entity.AttachmentTypeFile
+	}
+}
+
+// sanitizeFilename keeps the base name without control characters, trimmed to maxFilenameLength bytes
+func sanitizeFilename(filename string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "file"
+	}
+
+	return name
+}
+
+func uploadReadError(err error) *app_error.AppError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return app_error.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d bytes", MaxUploadSize()), "file")
+	}
+
+	log.Error().Err(err).Msg("failed to store upload")
+	return app_error.NewAppError(http.StatusInternalServerError, "failed to store file", "storage")
+}
+
+// MaxUploadSize is the largest accepted file in bytes, handlers use it to cap the request body
+func MaxUploadSize() int64 {
+	if config.Conf == nil || config.Conf.STORAGE.MaxUploadSize <= 0 {
+		return defaultMaxUploadSize
+	}
+
+	return config.Conf.STORAGE.MaxUploadSize
+}
+
+func signedURLTTL() time.Duration {
+	if config.Conf == nil || config.Conf.STORAGE.SignedURLTTL <= 0 {
+		return defaultSignedURLTTL
+	}
+
+	return config.Conf.STORAGE.SignedURLTTL
+}
+
+func uploadDir() string {
+	if config.Conf == nil || config.Conf.STORAGE.LocalDir == "" {
+		return defaultUploadDir
+	}
+
+	return config.Conf.STORAGE.LocalDir
+}
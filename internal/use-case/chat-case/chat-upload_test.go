@@ -0,0 +1,93 @@
+package chat_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/storage"
+)
+
+func TestSniffMimeType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+		typ  string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png", "image"},
+		{"pdf", []byte("%PDF-1.7\n"), "application/pdf", "file"},
+		{"text drops charset", []byte("hello there"), "text/plain", "file"},
+		{"html is not allowed", []byte("<html><body>hi</body></html>"), "text/html", "file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sniffMimeType(tt.head)
+			if got != tt.want {
+				t.Errorf("sniffMimeType() = %q, want %q", got, tt.want)
+			}
+			if typ := attachmentType(got); typ != tt.typ {
+				t.Errorf("attachmentType(%q) = %q, want %q", got, typ, tt.typ)
+			}
+		})
+	}
+
+	if allowedUploadTypes["text/html"] {
+		t.Error("text/html must not be an allowed upload type")
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"photo.jpg", "photo.jpg"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\me\report.pdf`, "report.pdf"},
+		{"bad\"name\n.txt", "badname.txt"},
+		{"", "file"},
+		{"..", "file"},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.in); got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	long := sanitizeFilename(strings.Repeat("é", 200))
+	if len(long) > maxFilenameLength || !strings.HasPrefix(strings.Repeat("é", 200), long) {
+		t.Errorf("sanitizeFilename() cut a long name to %d bytes, want at most %d on a rune boundary", len(long), maxFilenameLength)
+	}
+}
+
+func TestSignedAttachmentURL(t *testing.T) {
+	now := time.Now()
+	path := fileURLPath("0b6f1c1e-4d7a-4f47-9a43-1f1a4f0e2f11")
+
+	signed := storage.SignURL(path, now.Add(time.Minute))
+	_, query, _ := strings.Cut(signed, "?")
+
+	var expires, sig string
+	for _, pair := range strings.Split(query, "&") {
+		key, value, _ := strings.Cut(pair, "=")
+		switch key {
+		case "expires":
+			expires = value
+		case "sig":
+			sig = value
+		}
+	}
+
+	if !storage.VerifySignedURL(path, expires, sig, now) {
+		t.Fatal("fresh signed URL was rejected")
+	}
+	if storage.VerifySignedURL(path, expires, sig, now.Add(2*time.Minute)) {
+		t.Error("expired signed URL was accepted")
+	}
+	if storage.VerifySignedURL(fileURLPath("another"), expires, sig, now) {
+		t.Error("signature was accepted for another file")
+	}
+}
@@ -0,0 +1,83 @@
+package chat_service
+
+import (
+	"net/http"
+
+	"github.com/xenn00/chat-system/internal/entity"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+)
+
+type RoomAction string
+
+const (
+	ActionPostMessage       RoomAction = "post_message"
+	ActionEditAnyMessage    RoomAction = "edit_any_message"
+	ActionDeleteAnyMessage  RoomAction = "delete_any_message"
+	ActionPinMessage        RoomAction = "pin_message"
+	ActionRemoveMember      RoomAction = "remove_member"
+	ActionInviteMember      RoomAction = "invite_member"
+	ActionChangeSettings    RoomAction = "change_settings"
+	ActionManageRoles       RoomAction = "manage_roles"
+	ActionDeleteRoom        RoomAction = "delete_room"
+	ActionTransferOwnership RoomAction = "transfer_ownership"
+)
+
+// roleRank orders the room roles, a higher rank includes every permission of the lower ones
+var roleRank = map[string]int{
+	entity.RoomRoleMember:    1,
+	entity.RoomRoleModerator: 2,
+	entity.RoomRoleAdmin:     3,
+	entity.RoomRoleOwner:     4,
+}
+
+// actionMinRole is the lowest role allowed to perform an action
+var actionMinRole = map[RoomAction]string{
+	ActionPostMessage:       entity.RoomRoleMember,
+	ActionEditAnyMessage:    entity.RoomRoleModerator,
+	ActionDeleteAnyMessage:  entity.RoomRoleModerator,
+	ActionPinMessage:        entity.RoomRoleModerator,
+	ActionRemoveMember:      entity.RoomRoleModerator,
+	ActionInviteMember:      entity.RoomRoleAdmin,
+	ActionChangeSettings:    entity.RoomRoleAdmin,
+	ActionManageRoles:       entity.RoomRoleAdmin,
+	ActionDeleteRoom:        entity.RoomRoleOwner,
+	ActionTransferOwnership: entity.RoomRoleOwner,
+}
+
+// CanPerform reports whether an active member is allowed to perform the action
+func CanPerform(member *entity.RoomMember, action RoomAction) bool {
+	if member == nil || member.LeftAt != nil {
+		return false
+	}
+
+	minRole, ok := actionMinRole[action]
+	if !ok {
+		return false
+	}
+
+	return roleRank[member.Role] >= roleRank[minRole]
+}
+
+// Outranks reports whether actor sits strictly above target in the role hierarchy,
+// actions against another member (remove, promote, demote) require it
+func Outranks(actor, target *entity.RoomMember) bool {
+	if actor == nil || target == nil {
+		return false
+	}
+
+	return roleRank[actor.Role] > roleRank[target.Role]
+}
+
+// authorize resolves the caller membership and checks the action against its role
+func (c *ChatService) authorize(members []*entity.RoomMember, userID string, action RoomAction) (*entity.RoomMember, *app_error.AppError) {
+	member := c.findActiveMember(members, userID)
+	if member == nil {
+		return nil, app_error.NewAppError(http.StatusForbidden, "you are not a member of this room", "forbidden")
+	}
+
+	if !CanPerform(member, action) {
+		return nil, app_error.NewAppError(http.StatusForbidden, "your role is not allowed to perform this action", "forbidden")
+	}
+
+	return member, nil
+}
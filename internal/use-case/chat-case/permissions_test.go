@@ -0,0 +1,69 @@
+package chat_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xenn00/chat-system/internal/entity"
+)
+
+func TestCanPerform(t *testing.T) {
+	owner := &entity.RoomMember{Role: entity.RoomRoleOwner}
+	admin := &entity.RoomMember{Role: entity.RoomRoleAdmin}
+	moderator := &entity.RoomMember{Role: entity.RoomRoleModerator}
+	member := &entity.RoomMember{Role: entity.RoomRoleMember}
+
+	t.Run("Everyone can post", func(t *testing.T) {
+		for _, m := range []*entity.RoomMember{owner, admin, moderator, member} {
+			assert.True(t, CanPerform(m, ActionPostMessage), m.Role)
+		}
+	})
+
+	t.Run("Moderation actions start at moderator", func(t *testing.T) {
+		for _, action := range []RoomAction{ActionEditAnyMessage, ActionDeleteAnyMessage, ActionPinMessage, ActionRemoveMember} {
+			assert.False(t, CanPerform(member, action), action)
+			assert.True(t, CanPerform(moderator, action), action)
+			assert.True(t, CanPerform(owner, action), action)
+		}
+	})
+
+	t.Run("Settings and roles need admin", func(t *testing.T) {
+		for _, action := range []RoomAction{ActionChangeSettings, ActionManageRoles, ActionInviteMember} {
+			assert.False(t, CanPerform(moderator, action), action)
+			assert.True(t, CanPerform(admin, action), action)
+		}
+	})
+
+	t.Run("Only the owner deletes or transfers", func(t *testing.T) {
+		assert.False(t, CanPerform(admin, ActionDeleteRoom))
+		assert.False(t, CanPerform(admin, ActionTransferOwnership))
+		assert.True(t, CanPerform(owner, ActionDeleteRoom))
+		assert.True(t, CanPerform(owner, ActionTransferOwnership))
+	})
+
+	t.Run("Left or missing member cannot do anything", func(t *testing.T) {
+		leftAt := time.Now()
+		left := &entity.RoomMember{Role: entity.RoomRoleOwner, LeftAt: &leftAt}
+		assert.False(t, CanPerform(left, ActionPostMessage))
+		assert.False(t, CanPerform(nil, ActionPostMessage))
+	})
+
+	t.Run("Unknown action or role is denied", func(t *testing.T) {
+		assert.False(t, CanPerform(owner, RoomAction("launch_rocket")))
+		assert.False(t, CanPerform(&entity.RoomMember{Role: "guest"}, ActionPostMessage))
+	})
+}
+
+func TestOutranks(t *testing.T) {
+	owner := &entity.RoomMember{Role: entity.RoomRoleOwner}
+	admin := &entity.RoomMember{Role: entity.RoomRoleAdmin}
+	otherAdmin := &entity.RoomMember{Role: entity.RoomRoleAdmin}
+	member := &entity.RoomMember{Role: entity.RoomRoleMember}
+
+	assert.True(t, Outranks(owner, admin))
+	assert.True(t, Outranks(admin, member))
+	assert.False(t, Outranks(admin, otherAdmin))
+	assert.False(t, Outranks(member, admin))
+	assert.False(t, Outranks(nil, member))
+}
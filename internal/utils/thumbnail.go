@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail scales img down so its longest side is at most maxSide, every target pixel is the
+// average of the source pixels it covers. Images that already fit are returned as they are.
+func Thumbnail(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	thumbWidth, thumbHeight := maxSide, height*maxSide/width
+	if height > width {
+		thumbWidth, thumbHeight = width*maxSide/height, maxSide
+	}
+	thumbWidth, thumbHeight = max(thumbWidth, 1), max(thumbHeight, 1)
+
+	thumb := image.NewRGBA64(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		srcY0 := bounds.Min.Y + y*height/thumbHeight
+		srcY1 := max(bounds.Min.Y+(y+1)*height/thumbHeight, srcY0+1)
+
+		for x := 0; x < thumbWidth; x++ {
+			srcX0 := bounds.Min.X + x*width/thumbWidth
+			srcX1 := max(bounds.Min.X+(x+1)*width/thumbWidth, srcX0+1)
+
+			var r, g, b, a, n uint64
+			for sy := srcY0; sy < srcY1; sy++ {
+				for sx := srcX0; sx < srcX1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			thumb.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	return thumb
+}
@@ -6,17 +6,24 @@ import (
 
 type BroadcastMessagePayload struct {
 	MessageID          string              `json:"message_id"`
+	ClientMsgID        string              `json:"client_msg_id,omitempty"`
 	RoomID             string              `json:"room_id"`
 	SenderID           string              `json:"sender_id"`
 	ReceiverID         string              `json:"receiver_id"`
+	MessageType        string              `json:"message_type,omitempty"`
 	Content            string              `json:"content"`
+	Format             string              `json:"format,omitempty"`
 	IsRead             *bool               `json:"is_read"`
 	IsEdited           *bool               `json:"is_edited"`
 	MessageEditHistory []*MessageEditEntry `json:"message_edit_history"`
 	Attachments        []*Attachment       `json:"attachments"`
+	LinkPreviews       []*LinkPreview      `json:"link_previews,omitempty"`
+	Poll               *PollPayload        `json:"poll,omitempty"`
 	ReplyTo            *ReplyTo            `json:"reply_to"`
+	ForwardedFrom      *ForwardedFrom      `json:"forwarded_from,omitempty"`
 	CreatedAt          time.Time           `json:"created_at"`
 	UpdatedAt          *time.Time          `json:"updated_at"`
+	ExpiresAt          *time.Time          `json:"expires_at,omitempty"`
 }
 
 type MessageEditEntry struct {
@@ -33,7 +40,163 @@ type ReplyTo struct {
 	SenderID  string `json:"sender_id"`
 }
 
+type ForwardedFrom struct {
+	MessageID string `json:"message_id"`
+	RoomID    string `json:"room_id"`
+	SenderID  string `json:"sender_id"`
+}
+
 type Attachment struct {
-	Type string `json:"type"`
-	URL  string `json:"url"`
+	Type         string `json:"type"`
+	URL          string `json:"url"`
+	UploadID     string `json:"upload_id,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+type RoomJoinedPayload struct {
+	RoomID       string   `json:"room_id"`
+	UserID       string   `json:"user_id"`
+	Participants []string `json:"participants"`
+}
+
+type BroadcastGroupMessagePayload struct {
+	Event      string                  `json:"event"` // chat_message or message_updated
+	Recipients []string                `json:"recipients"`
+	Message    BroadcastMessagePayload `json:"message"`
+}
+
+type SystemMessagePayload struct {
+	RoomID     string            `json:"room_id"`
+	Content    string            `json:"content"`
+	Data       map[string]string `json:"data,omitempty"`
+	Recipients []string          `json:"recipients"`
+}
+
+type RoomLeftPayload struct {
+	RoomID     string   `json:"room_id"`
+	UserID     string   `json:"user_id"`
+	Reason     string   `json:"reason"`
+	ActorID    string   `json:"actor_id"`
+	Recipients []string `json:"recipients"`
+}
+
+type MessageReadPayload struct {
+	RoomID     string    `json:"room_id"`
+	MessageID  string    `json:"message_id"`
+	ReadBy     string    `json:"read_by"`
+	ReadAt     time.Time `json:"read_at"`
+	Recipients []string  `json:"recipients"`
+}
+
+type MessageDeletedPayload struct {
+	RoomID     string    `json:"room_id"`
+	MessageID  string    `json:"message_id"`
+	Scope      string    `json:"scope"`
+	DeletedBy  string    `json:"deleted_by"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	Recipients []string  `json:"recipients"`
+}
+
+type MessageReactionPayload struct {
+	RoomID     string                 `json:"room_id"`
+	MessageID  string                 `json:"message_id"`
+	UserID     string                 `json:"user_id"`
+	Emoji      string                 `json:"emoji"`
+	Action     string                 `json:"action"`
+	Reactions  []ReactionCountPayload `json:"reactions"`
+	Recipients []string               `json:"recipients"`
+}
+
+type ReactionCountPayload struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+type ThreadUpdatedPayload struct {
+	RoomID        string    `json:"room_id"`
+	RootMessageID string    `json:"root_message_id"`
+	ReplyID       string    `json:"reply_id"`
+	ReplierID     string    `json:"replier_id"`
+	ReplyCount    int       `json:"reply_count"`
+	LastReplyAt   time.Time `json:"last_reply_at"`
+	Recipients    []string  `json:"recipients"`
+}
+
+type MentionPayload struct {
+	RoomID     string   `json:"room_id"`
+	MessageID  string   `json:"message_id"`
+	SenderID   string   `json:"sender_id"`
+	Content    string   `json:"content"`
+	Recipients []string `json:"recipients"`
+}
+
+// UnreadChangedPayload carries the new unread count of the room for every user in Counts
+type UnreadChangedPayload struct {
+	RoomID string           `json:"room_id"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+type MessagePinnedPayload struct {
+	RoomID     string    `json:"room_id"`
+	MessageID  string    `json:"message_id"`
+	Pinned     bool      `json:"pinned"`
+	PinnedBy   string    `json:"pinned_by"`
+	PinnedAt   time.Time `json:"pinned_at"`
+	Recipients []string  `json:"recipients"`
+}
+
+type ScheduledMessagePayload struct {
+	ScheduledID string `json:"scheduled_id"`
+	Version     int    `json:"version"`
+}
+
+type ProcessAttachmentsPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+type UnfurlLinksPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// PollPayload is the tally of a poll as every member sees it, without the vote of the viewer
+type PollPayload struct {
+	Question       string              `json:"question"`
+	Options        []PollOptionPayload `json:"options"`
+	MultipleChoice bool                `json:"multiple_choice"`
+	Anonymous      bool                `json:"anonymous"`
+	ClosesAt       *time.Time          `json:"closes_at,omitempty"`
+	ClosedAt       *time.Time          `json:"closed_at,omitempty"`
+	IsClosed       bool                `json:"is_closed"`
+	TotalVoters    int                 `json:"total_voters"`
+}
+
+type PollOptionPayload struct {
+	ID     string   `json:"id"`
+	Text   string   `json:"text"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters,omitempty"`
+}
+
+type PollUpdatedPayload struct {
+	RoomID     string      `json:"room_id"`
+	MessageID  string      `json:"message_id"`
+	Poll       PollPayload `json:"poll"`
+	Recipients []string    `json:"recipients"`
+}
+
+type ClosePollPayload struct {
+	MessageID string `json:"message_id"`
 }
@@ -108,7 +108,7 @@ func (h *Hub) Unregister(roomId string, client *Client) {
 	for i, c := range userClients {
 		if c == client {
 			// Remove client from slice
-			h.userClients[client.UserID] = append(h.userClients[client.UserID], userClients[i+1:]...)
+			h.userClients[client.UserID] = append(userClients[:i:i], userClients[i+1:]...)
 			break
 		}
 	}
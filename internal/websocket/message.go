@@ -19,30 +19,39 @@ type ChatMessage struct {
 	Type               string              `json:"type"`
 	RoomID             string              `json:"room_id"`
 	MessageID          string              `json:"message_id"`
+	ClientMsgID        string              `json:"client_msg_id,omitempty"` // id the sender generated, matches its optimistic entry
 	SenderID           string              `json:"senderId"`
 	ReceiverID         string              `json:"receiver_id"`
+	MessageType        string              `json:"message_type,omitempty"` // poll, absent is a text message
 	Content            string              `json:"content"`
+	Format             string              `json:"format,omitempty"` // plain or markdown, absent is plain
+	Poll               *PollResults        `json:"poll,omitempty"`
 	IsEdited           bool                `json:"is_edited"`
 	IsRead             bool                `json:"is_read"`
 	MessageEditHistory []MessageEditEntry  `json:"message_edit_history,omitempty"`
 	Reply              *ReplyMessage       `json:"reply,omitempty"`
+	ForwardedFrom      *ForwardedMessage   `json:"forwarded_from,omitempty"`
 	Attachments        []MessageAttachment `json:"attachments,omitempty"`
 	CreatedAt          int64               `json:"created_at"`
 	UpdatedAt          *int64              `json:"updated_at"`
+	ExpiresAt          *int64              `json:"expires_at,omitempty"` // set when the room has a disappearing timer
 	Timestamp          int64               `json:"timestamp"`
 }
 
 // MessageUpdated represents an edited message
 type MessageUpdated struct {
-	Type               string             `json:"type"`
-	RoomID             string             `json:"room_id"`
-	MessageID          string             `json:"message_id"`
-	Content            string             `json:"content"`
-	IsEdited           bool               `json:"is_edited"`
-	MessageEditHistory []MessageEditEntry `json:"message_edit_history,omitempty"`
-	UpdatedAt          int64              `json:"updated_at"`
-	EditedBy           string             `json:"edited_by"`
-	Timestamp          int64              `json:"timestamp"`
+	Type               string              `json:"type"`
+	RoomID             string              `json:"room_id"`
+	MessageID          string              `json:"message_id"`
+	Content            string              `json:"content"`
+	Format             string              `json:"format,omitempty"`
+	IsEdited           bool                `json:"is_edited"`
+	MessageEditHistory []MessageEditEntry  `json:"message_edit_history,omitempty"`
+	Attachments        []MessageAttachment `json:"attachments,omitempty"`
+	LinkPreviews       []LinkPreview       `json:"link_previews,omitempty"`
+	UpdatedAt          int64               `json:"updated_at"`
+	EditedBy           string              `json:"edited_by"`
+	Timestamp          int64               `json:"timestamp"`
 }
 
 // MessageRead represents a message read receipt
@@ -55,6 +64,104 @@ type MessageRead struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// MessageDeleted represents a message deleted for one user or for everyone
+type MessageDeleted struct {
+	Type      string `json:"type"`
+	RoomID    string `json:"room_id"`
+	MessageID string `json:"message_id"`
+	Scope     string `json:"scope"`
+	DeletedBy string `json:"deleted_by"`
+	DeletedAt int64  `json:"deleted_at"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// MessageReaction represents a reaction added to or removed from a message
+type MessageReaction struct {
+	Type      string          `json:"type"`
+	RoomID    string          `json:"room_id"`
+	MessageID string          `json:"message_id"`
+	UserID    string          `json:"user_id"`
+	Emoji     string          `json:"emoji"`
+	Action    string          `json:"action"`
+	Reactions []ReactionCount `json:"reactions"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// ReactionCount is the number of users that reacted with one emoji
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// PollUpdated represents new votes on a poll or the poll being closed
+type PollUpdated struct {
+	Type      string      `json:"type"`
+	RoomID    string      `json:"room_id"`
+	MessageID string      `json:"message_id"`
+	Poll      PollResults `json:"poll"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// PollResults is the tally of a poll, voters are only listed when the poll is not anonymous
+type PollResults struct {
+	Question       string             `json:"question"`
+	Options        []PollOptionResult `json:"options"`
+	MultipleChoice bool               `json:"multiple_choice"`
+	Anonymous      bool               `json:"anonymous"`
+	ClosesAt       *int64             `json:"closes_at,omitempty"`
+	ClosedAt       *int64             `json:"closed_at,omitempty"`
+	IsClosed       bool               `json:"is_closed"`
+	TotalVoters    int                `json:"total_voters"`
+}
+
+type PollOptionResult struct {
+	ID     string   `json:"id"`
+	Text   string   `json:"text"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters,omitempty"`
+}
+
+// ThreadUpdated represents a new reply in a thread, clients update the reply count of the root
+type ThreadUpdated struct {
+	Type          string `json:"type"`
+	RoomID        string `json:"room_id"`
+	RootMessageID string `json:"root_message_id"`
+	ReplyID       string `json:"reply_id"`
+	ReplierID     string `json:"replier_id"`
+	ReplyCount    int    `json:"reply_count"`
+	LastReplyAt   int64  `json:"last_reply_at"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// Mention represents a message that mentions the receiving user
+type Mention struct {
+	Type      string `json:"type"`
+	RoomID    string `json:"room_id"`
+	MessageID string `json:"message_id"`
+	SenderID  string `json:"sender_id"`
+	Content   string `json:"content"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// UnreadChanged represents the new unread count of a room for the receiving user
+type UnreadChanged struct {
+	Type        string `json:"type"`
+	RoomID      string `json:"room_id"`
+	UnreadCount int64  `json:"unread_count"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// MessagePinned represents a message pinned to or unpinned from a room
+type MessagePinned struct {
+	Type      string `json:"type"`
+	RoomID    string `json:"room_id"`
+	MessageID string `json:"message_id"`
+	Pinned    bool   `json:"pinned"`
+	PinnedBy  string `json:"pinned_by"`
+	PinnedAt  int64  `json:"pinned_at"`
+	Timestamp int64  `json:"timestamp"`
+}
+
 // UserTyping represents typing indicators
 type UserTyping struct {
 	Type      string `json:"type"`
@@ -88,6 +195,8 @@ type RoomLeft struct {
 	Type      string `json:"type"`
 	RoomID    string `json:"room_id"`
 	UserID    string `json:"user_id"`
+	Reason    string `json:"reason,omitempty"`
+	ActorID   string `json:"actor_id,omitempty"`
 	Timestamp int64  `json:"timestamp"`
 }
 
@@ -123,29 +232,55 @@ type MessageEditEntry struct {
 	EditedAt        int64  `json:"edited_at"`
 }
 
+// ForwardedMessage references the original message of a forwarded copy
+type ForwardedMessage struct {
+	MessageID string `json:"message_id"`
+	RoomID    string `json:"room_id"`
+	SenderID  string `json:"sender_id"`
+}
+
 // MessageAttachment represents file attachments
 type MessageAttachment struct {
-	Type     string `json:"type"`
-	URL      string `json:"url"`
-	Filename string `json:"filename,omitempty"`
-	Size     int64  `json:"size,omitempty"`
-	MimeType string `json:"mime_type,omitempty"`
+	Type         string `json:"type"`
+	URL          string `json:"url"`
+	UploadID     string `json:"upload_id,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// LinkPreview represents the metadata of a link in the message content
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
 }
 
 // Message type constants
 const (
 	// Outgoing message types (server -> client)
-	MessageTypeChatMessage    = "chat_message"
-	MessageTypeMessageUpdated = "message_updated"
-	MessageTypeMessageRead    = "message_read"
-	MessageTypeMessageDeleted = "message_deleted"
-	MessageTypeUserTyping     = "user_typing"
-	MessageTypeUserStatus     = "user_status"
-	MessageTypeRoomJoined     = "room_joined"
-	MessageTypeRoomLeft       = "room_left"
-	MessageTypeError          = "error"
-	MessageTypeSystem         = "system"
-	MessageTypePong           = "pong"
+	MessageTypeChatMessage     = "chat_message"
+	MessageTypeMessageUpdated  = "message_updated"
+	MessageTypeMessageRead     = "message_read"
+	MessageTypeMessageDeleted  = "message_deleted"
+	MessageTypeMessageReaction = "message_reaction"
+	MessageTypePollUpdated     = "poll_updated"
+	MessageTypeThreadUpdated   = "thread_updated"
+	MessageTypeMention         = "mention"
+	MessageTypeUnreadChanged   = "unread_changed"
+	MessageTypeMessagePinned   = "message_pinned"
+	MessageTypeUserTyping      = "user_typing"
+	MessageTypeUserStatus      = "user_status"
+	MessageTypeRoomJoined      = "room_joined"
+	MessageTypeRoomLeft        = "room_left"
+	MessageTypeError           = "error"
+	MessageTypeSystem          = "system"
+	MessageTypePong            = "pong"
 
 	// Incoming message types (client -> server)
 	MessageTypeJoinRoom    = "join_room"
@@ -228,6 +363,139 @@ func NewMessageRead(roomID, messageID, readBy string) OutgoingMessage {
 	}
 }
 
+// NewMessageDeleted creates a message deleted notification
+func NewMessageDeleted(roomID, messageID, scope, deletedBy string) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypeMessageDeleted,
+		RoomID:    roomID,
+		MessageID: messageID,
+		SenderID:  deletedBy,
+		Data: MessageDeleted{
+			Type:      MessageTypeMessageDeleted,
+			RoomID:    roomID,
+			MessageID: messageID,
+			Scope:     scope,
+			DeletedBy: deletedBy,
+			DeletedAt: time.Now().Unix(),
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewMessageReaction creates a message reaction notification
+func NewMessageReaction(roomID, messageID, userID, emoji, action string, reactions []ReactionCount) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypeMessageReaction,
+		RoomID:    roomID,
+		MessageID: messageID,
+		SenderID:  userID,
+		Data: MessageReaction{
+			Type:      MessageTypeMessageReaction,
+			RoomID:    roomID,
+			MessageID: messageID,
+			UserID:    userID,
+			Emoji:     emoji,
+			Action:    action,
+			Reactions: reactions,
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewPollUpdated creates a poll updated notification
+func NewPollUpdated(roomID, messageID string, poll PollResults) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypePollUpdated,
+		RoomID:    roomID,
+		MessageID: messageID,
+		Data: PollUpdated{
+			Type:      MessageTypePollUpdated,
+			RoomID:    roomID,
+			MessageID: messageID,
+			Poll:      poll,
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewThreadUpdated creates a thread updated notification
+func NewThreadUpdated(roomID, rootMessageID, replyID, replierID string, replyCount int, lastReplyAt int64) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypeThreadUpdated,
+		RoomID:    roomID,
+		MessageID: rootMessageID,
+		SenderID:  replierID,
+		Data: ThreadUpdated{
+			Type:          MessageTypeThreadUpdated,
+			RoomID:        roomID,
+			RootMessageID: rootMessageID,
+			ReplyID:       replyID,
+			ReplierID:     replierID,
+			ReplyCount:    replyCount,
+			LastReplyAt:   lastReplyAt,
+			Timestamp:     time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewMention creates a mention notification
+func NewMention(roomID, messageID, senderID, content string) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypeMention,
+		RoomID:    roomID,
+		MessageID: messageID,
+		SenderID:  senderID,
+		Data: Mention{
+			Type:      MessageTypeMention,
+			RoomID:    roomID,
+			MessageID: messageID,
+			SenderID:  senderID,
+			Content:   content,
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewUnreadChanged creates an unread count update for a single user
+func NewUnreadChanged(roomID string, unreadCount int64) OutgoingMessage {
+	return OutgoingMessage{
+		Type:   MessageTypeUnreadChanged,
+		RoomID: roomID,
+		Data: UnreadChanged{
+			Type:        MessageTypeUnreadChanged,
+			RoomID:      roomID,
+			UnreadCount: unreadCount,
+			Timestamp:   time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewMessagePinned creates a pin notification, pinned is false when the message was unpinned
+func NewMessagePinned(roomID, messageID, pinnedBy string, pinned bool) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      MessageTypeMessagePinned,
+		RoomID:    roomID,
+		MessageID: messageID,
+		SenderID:  pinnedBy,
+		Data: MessagePinned{
+			Type:      MessageTypeMessagePinned,
+			RoomID:    roomID,
+			MessageID: messageID,
+			Pinned:    pinned,
+			PinnedBy:  pinnedBy,
+			PinnedAt:  time.Now().Unix(),
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
 // NewUserTyping creates a typing indicator message
 func NewUserTyping(roomID, userID string, isTyping bool) OutgoingMessage {
 	return OutgoingMessage{
@@ -286,6 +554,24 @@ func NewRoomJoined(roomID, userID string, participants []string) OutgoingMessage
 	}
 }
 
+// NewRoomLeft creates a room left message, reason is left or removed
+func NewRoomLeft(roomID, userID, reason, actorID string) OutgoingMessage {
+	return OutgoingMessage{
+		Type:     MessageTypeRoomLeft,
+		RoomID:   roomID,
+		SenderID: actorID,
+		Data: RoomLeft{
+			Type:      MessageTypeRoomLeft,
+			RoomID:    roomID,
+			UserID:    userID,
+			Reason:    reason,
+			ActorID:   actorID,
+			Timestamp: time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+}
+
 // NewErrorMessage creates an error message
 func NewErrorMessage(code, message, details string) OutgoingMessage {
 	return OutgoingMessage{
@@ -322,22 +608,27 @@ func NewSystemMessage(roomID, content string, data interface{}) OutgoingMessage
 // IsValidMessageType checks if a message type is valid
 func IsValidMessageType(msgType string) bool {
 	validTypes := map[string]bool{
-		MessageTypeChatMessage:    true,
-		MessageTypeMessageUpdated: true,
-		MessageTypeMessageRead:    true,
-		MessageTypeMessageDeleted: true,
-		MessageTypeUserTyping:     true,
-		MessageTypeUserStatus:     true,
-		MessageTypeRoomJoined:     true,
-		MessageTypeRoomLeft:       true,
-		MessageTypeError:          true,
-		MessageTypeSystem:         true,
-		MessageTypePong:           true,
-		MessageTypeJoinRoom:       true,
-		MessageTypeLeaveRoom:      true,
-		MessageTypeTypingStart:    true,
-		MessageTypeTypingStop:     true,
-		MessageTypePing:           true,
+		MessageTypeChatMessage:     true,
+		MessageTypeMessageUpdated:  true,
+		MessageTypeMessageRead:     true,
+		MessageTypeMessageDeleted:  true,
+		MessageTypeMessageReaction: true,
+		MessageTypeThreadUpdated:   true,
+		MessageTypeMention:         true,
+		MessageTypeUnreadChanged:   true,
+		MessageTypeMessagePinned:   true,
+		MessageTypeUserTyping:      true,
+		MessageTypeUserStatus:      true,
+		MessageTypeRoomJoined:      true,
+		MessageTypeRoomLeft:        true,
+		MessageTypeError:           true,
+		MessageTypeSystem:          true,
+		MessageTypePong:            true,
+		MessageTypeJoinRoom:        true,
+		MessageTypeLeaveRoom:       true,
+		MessageTypeTypingStart:     true,
+		MessageTypeTypingStop:      true,
+		MessageTypePing:            true,
 	}
 	return validTypes[msgType]
 }
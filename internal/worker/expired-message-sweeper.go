@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/utils/types"
+	worker_handler "github.com/xenn00/chat-system/internal/worker/worker-handler"
+)
+
+const (
+	expiredSweepInterval  = 30 * time.Second
+	expiredSweepBatchSize = 200
+)
+
+// StartExpiredMessageSweeper removes messages whose disappearing timer ran out and tells the room members
+// through message_deleted events. The TTL index on expires_at is only a backstop for a stopped sweeper.
+func (wp *WorkerPool) StartExpiredMessageSweeper(ctx context.Context) {
+	log.Info().Msg("Expired message sweeper started")
+	ticker := time.NewTicker(expiredSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Expired message sweeper stopping")
+			return
+		case <-ticker.C:
+			wp.sweepExpiredMessages(ctx)
+		}
+	}
+}
+
+func (wp *WorkerPool) sweepExpiredMessages(ctx context.Context) {
+	workerHandler := worker_handler.NewWorkerHandler(ctx, wp.Redis, wp.ws, wp.chat)
+
+	// drain full batches so a backlog doesn't wait for the next tick
+	for {
+		deletions, err := wp.chat.SweepExpiredMessages(ctx, time.Now(), expiredSweepBatchSize)
+		if err != nil {
+			log.Error().Str("error", err.Message).Msg("failed to sweep expired messages")
+			return
+		}
+
+		for _, deletion := range deletions {
+			payload := types.MessageDeletedPayload{
+				RoomID:     deletion.RoomID,
+				MessageID:  deletion.MessageID,
+				Scope:      deletion.Scope,
+				DeletedBy:  deletion.DeletedBy,
+				DeletedAt:  deletion.DeletedAt,
+				Recipients: deletion.Recipients,
+			}
+			if err := workerHandler.HandleBroadcastMessageDeleted(queue.MustMarshal(payload)); err != nil {
+				log.Error().Err(err).Str("message_id", deletion.MessageID).Msg("failed to broadcast expired message")
+			}
+		}
+
+		if len(deletions) > 0 {
+			log.Info().Int("count", len(deletions)).Msg("Expired messages removed")
+		}
+		if len(deletions) < expiredSweepBatchSize {
+			return
+		}
+	}
+}
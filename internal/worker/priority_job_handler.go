@@ -7,6 +7,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/xenn00/chat-system/internal/queue"
+	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
 	"github.com/xenn00/chat-system/internal/websocket"
 	worker_handler "github.com/xenn00/chat-system/internal/worker/worker-handler"
 )
@@ -16,8 +17,8 @@ type JobPayload struct {
 	Data json.RawMessage `json:"data"`
 }
 
-func HandleJob(ctx context.Context, job queue.Job, redis *redis.Client, ws *websocket.Hub) error {
-	workerHandler := worker_handler.NewWorkerHandler(ctx, redis, ws)
+func HandleJob(ctx context.Context, job queue.Job, redis *redis.Client, ws *websocket.Hub, chat chat_service.ChatServiceContract) error {
+	workerHandler := worker_handler.NewWorkerHandler(ctx, redis, ws, chat)
 	switch job.Type {
 	case "create_user_otp":
 		return workerHandler.HandlerCreateUserOTP(ctx, redis, job.Payload)
@@ -27,6 +28,38 @@ func HandleJob(ctx context.Context, job queue.Job, redis *redis.Client, ws *webs
 		return workerHandler.HandleBroadcastPrivateMessageReply(job.Payload)
 	case "broadcast_private_message_updated":
 		return workerHandler.HandleBroadcastPrivateMessageUpdate(job.Payload)
+	case "broadcast_group_message":
+		return workerHandler.HandleBroadcastGroupMessage(job.Payload)
+	case "broadcast_system_message":
+		return workerHandler.HandleBroadcastSystemMessage(job.Payload)
+	case "broadcast_room_left":
+		return workerHandler.HandleBroadcastRoomLeft(job.Payload)
+	case "broadcast_message_read":
+		return workerHandler.HandleBroadcastMessageRead(job.Payload)
+	case "broadcast_message_deleted":
+		return workerHandler.HandleBroadcastMessageDeleted(job.Payload)
+	case "broadcast_message_reaction":
+		return workerHandler.HandleBroadcastMessageReaction(job.Payload)
+	case "broadcast_thread_updated":
+		return workerHandler.HandleBroadcastThreadUpdated(job.Payload)
+	case "broadcast_mention":
+		return workerHandler.HandleBroadcastMention(job.Payload)
+	case "broadcast_unread_changed":
+		return workerHandler.HandleBroadcastUnreadChanged(job.Payload)
+	case "broadcast_message_pinned":
+		return workerHandler.HandleBroadcastMessagePinned(job.Payload)
+	case "send_scheduled_message":
+		return workerHandler.HandleSendScheduledMessage(job.Payload)
+	case "process_attachments":
+		return workerHandler.HandleProcessAttachments(job.Payload)
+	case "unfurl_links":
+		return workerHandler.HandleUnfurlLinks(job.Payload)
+	case "broadcast_poll_updated":
+		return workerHandler.HandleBroadcastPollUpdated(job.Payload)
+	case "close_poll":
+		return workerHandler.HandleClosePoll(job.Payload)
+	case "broadcast_room_joined":
+		return workerHandler.HandleBroadcastRoomJoined(job.Payload)
 	default:
 		return fmt.Errorf("unknown job type: %s", job.Type)
 	}
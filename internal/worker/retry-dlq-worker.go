@@ -108,7 +108,7 @@ func (wp *WorkerPool) retryDLQJob(ctx context.Context, collection *mongo.Collect
 	originalJob.ErrorMsg = ""
 
 	// Try to process the job using existing HandleJob function
-	if err := HandleJob(ctx, originalJob, wp.Redis, wp.ws); err != nil {
+	if err := HandleJob(ctx, originalJob, wp.Redis, wp.ws, wp.chat); err != nil {
 		// Job failed again - update retry info
 		wp.handleDLQRetryFailure(ctx, collection, dlqJob, err.Error())
 		return
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/utils/types"
+	worker_handler "github.com/xenn00/chat-system/internal/worker/worker-handler"
+)
+
+const (
+	scheduledRecoveryInterval  = time.Minute
+	scheduledRecoveryBatchSize = 100
+	// a send finishes within seconds, a message due or claimed longer ago than this was lost by the queue
+	scheduledRecoveryAfter = 5 * time.Minute
+)
+
+// StartScheduledMessageRecovery delivers scheduled messages the queue lost track of: claims of a worker that
+// crashed while sending and due messages whose job expired or went to the dead-letter queue.
+func (wp *WorkerPool) StartScheduledMessageRecovery(ctx context.Context) {
+	log.Info().Msg("Scheduled message recovery started")
+	ticker := time.NewTicker(scheduledRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Scheduled message recovery stopping")
+			return
+		case <-ticker.C:
+			wp.recoverScheduledMessages(ctx)
+		}
+	}
+}
+
+func (wp *WorkerPool) recoverScheduledMessages(ctx context.Context) {
+	workerHandler := worker_handler.NewWorkerHandler(ctx, wp.Redis, wp.ws, wp.chat)
+
+	msgs, err := wp.chat.RecoverScheduledMessages(ctx, time.Now().Add(-scheduledRecoveryAfter), scheduledRecoveryBatchSize)
+	if err != nil {
+		log.Error().Str("error", err.Message).Msg("failed to recover scheduled messages")
+		return
+	}
+
+	// one batch per tick, a message that fails again is picked up by the next one
+	for _, msg := range msgs {
+		payload := types.ScheduledMessagePayload{
+			ScheduledID: msg.ScheduledID,
+			Version:     msg.Version,
+		}
+		if err := workerHandler.HandleSendScheduledMessage(queue.MustMarshal(payload)); err != nil {
+			log.Error().Err(err).Str("scheduled_id", msg.ScheduledID).Msg("failed to deliver recovered scheduled message")
+		}
+	}
+
+	if len(msgs) > 0 {
+		log.Info().Int("count", len(msgs)).Msg("Scheduled messages recovered")
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	unreadReconcileInterval  = time.Minute
+	unreadReconcileBatchSize = 500
+)
+
+// StartUnreadReconciler writes the unread counters kept in Redis back to room_members, so the table stays close
+// to the live counters and can seed them again when Redis lost them.
+func (wp *WorkerPool) StartUnreadReconciler(ctx context.Context) {
+	log.Info().Msg("Unread reconciler started")
+	ticker := time.NewTicker(unreadReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Unread reconciler stopping")
+			return
+		case <-ticker.C:
+			wp.reconcileUnreadCounts(ctx)
+		}
+	}
+}
+
+func (wp *WorkerPool) reconcileUnreadCounts(ctx context.Context) {
+	// drain full batches so a backlog doesn't wait for the next tick
+	for {
+		written, err := wp.chat.ReconcileUnreadCounts(ctx, unreadReconcileBatchSize)
+		if err != nil {
+			log.Error().Str("error", err.Message).Msg("failed to reconcile unread counters")
+			return
+		}
+
+		if written > 0 {
+			log.Info().Int("count", written).Msg("Unread counters reconciled")
+		}
+		if written < unreadReconcileBatchSize {
+			return
+		}
+	}
+}
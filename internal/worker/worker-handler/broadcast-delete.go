@@ -0,0 +1,30 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastMessageDeleted tells clients to hide a message, recipients are only the deleter for scope me
+func (wh *WorkerHandler) HandleBroadcastMessageDeleted(raw json.RawMessage) error {
+	var payload types.MessageDeletedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid message deleted payload: %w", err)
+	}
+
+	msg := websocket.NewMessageDeleted(payload.RoomID, payload.MessageID, payload.Scope, payload.DeletedBy)
+	if data, ok := msg.Data.(websocket.MessageDeleted); ok {
+		data.DeletedAt = payload.DeletedAt.Unix()
+		msg.Data = data
+	}
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
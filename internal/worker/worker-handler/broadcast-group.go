@@ -0,0 +1,229 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastGroupMessage fans a room message out to every member's connections,
+// so members get it even when they are not joined to the room over websocket
+func (wh *WorkerHandler) HandleBroadcastGroupMessage(raw json.RawMessage) error {
+	var payload types.BroadcastGroupMessagePayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid broadcast payload: %w", err)
+	}
+
+	var msg websocket.OutgoingMessage
+	switch payload.Event {
+	case websocket.MessageTypeChatMessage:
+		msg = newGroupChatMessage(payload.Message)
+	case websocket.MessageTypeMessageUpdated:
+		msg = newGroupMessageUpdated(payload.Message)
+	default:
+		return fmt.Errorf("unknown group message event: %s", payload.Event)
+	}
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
+
+func newGroupChatMessage(payload types.BroadcastMessagePayload) websocket.OutgoingMessage {
+	var replyData *websocket.ReplyMessage
+	if payload.ReplyTo != nil {
+		replyData = &websocket.ReplyMessage{
+			MessageID: payload.ReplyTo.MessageID,
+			Content:   payload.ReplyTo.Content,
+			SenderID:  payload.ReplyTo.SenderID,
+		}
+	}
+
+	var forwardedFrom *websocket.ForwardedMessage
+	if payload.ForwardedFrom != nil {
+		forwardedFrom = &websocket.ForwardedMessage{
+			MessageID: payload.ForwardedFrom.MessageID,
+			RoomID:    payload.ForwardedFrom.RoomID,
+			SenderID:  payload.ForwardedFrom.SenderID,
+		}
+	}
+
+	var poll *websocket.PollResults
+	if payload.Poll != nil {
+		results := toPollResults(*payload.Poll)
+		poll = &results
+	}
+
+	chatData := websocket.ChatMessage{
+		Type:          websocket.MessageTypeChatMessage,
+		RoomID:        payload.RoomID,
+		MessageID:     payload.MessageID,
+		ClientMsgID:   payload.ClientMsgID,
+		SenderID:      payload.SenderID,
+		ReceiverID:    payload.ReceiverID,
+		MessageType:   payload.MessageType,
+		Content:       payload.Content,
+		Format:        payload.Format,
+		Poll:          poll,
+		IsEdited:      false,
+		IsRead:        false,
+		Reply:         replyData,
+		ForwardedFrom: forwardedFrom,
+		Attachments:   toMessageAttachments(payload.Attachments),
+		CreatedAt:     payload.CreatedAt.Unix(),
+		ExpiresAt:     unixOrNil(payload.ExpiresAt),
+		Timestamp:     payload.CreatedAt.Unix(),
+	}
+
+	return websocket.OutgoingMessage{
+		Type:      websocket.MessageTypeChatMessage,
+		RoomID:    payload.RoomID,
+		MessageID: payload.MessageID,
+		SenderID:  payload.SenderID,
+		Data:      chatData,
+		Timestamp: payload.CreatedAt.Unix(),
+	}
+}
+
+func newGroupMessageUpdated(payload types.BroadcastMessagePayload) websocket.OutgoingMessage {
+	editHistory := make([]websocket.MessageEditEntry, 0, len(payload.MessageEditHistory))
+	for _, edit := range payload.MessageEditHistory {
+		editHistory = append(editHistory, websocket.MessageEditEntry{
+			MessageID:       edit.MessageID,
+			OriginalContent: edit.OriginalContent,
+			NewContent:      edit.NewContent,
+			EditedBy:        edit.EditedBy,
+			EditedAt:        edit.EditedAt.Unix(),
+		})
+	}
+
+	var updatedAt int64
+	if payload.UpdatedAt != nil {
+		updatedAt = payload.UpdatedAt.Unix()
+	}
+
+	updateData := websocket.MessageUpdated{
+		Type:               websocket.MessageTypeMessageUpdated,
+		RoomID:             payload.RoomID,
+		MessageID:          payload.MessageID,
+		Content:            payload.Content,
+		Format:             payload.Format,
+		IsEdited:           payload.IsEdited == nil || *payload.IsEdited, // metadata updates of unedited messages pass false
+		MessageEditHistory: editHistory,
+		Attachments:        toMessageAttachments(payload.Attachments),
+		LinkPreviews:       toMessageLinkPreviews(payload.LinkPreviews),
+		UpdatedAt:          updatedAt,
+		EditedBy:           payload.SenderID,
+		Timestamp:          time.Now().Unix(),
+	}
+
+	return websocket.OutgoingMessage{
+		Type:      websocket.MessageTypeMessageUpdated,
+		RoomID:    payload.RoomID,
+		MessageID: payload.MessageID,
+		SenderID:  payload.SenderID,
+		Data:      updateData,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// broadcastMessageUpdated sends the result of a background update of a message, like attachment metadata or
+// link previews, to the members of its room
+func (wh *WorkerHandler) broadcastMessageUpdated(resp *chat_dto.RoomMessageResponse) error {
+	attachments := make([]*types.Attachment, 0, len(resp.Attachments))
+	for _, attachment := range resp.Attachments {
+		attachments = append(attachments, &types.Attachment{
+			Type:         attachment.Type,
+			URL:          attachment.URL,
+			UploadID:     attachment.UploadID,
+			Filename:     attachment.Filename,
+			Size:         attachment.Size,
+			MimeType:     attachment.MimeType,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+			ThumbnailURL: attachment.ThumbnailURL,
+		})
+	}
+
+	previews := make([]*types.LinkPreview, 0, len(resp.LinkPreviews))
+	for _, preview := range resp.LinkPreviews {
+		previews = append(previews, &types.LinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		})
+	}
+
+	return wh.HandleBroadcastGroupMessage(queue.MustMarshal(types.BroadcastGroupMessagePayload{
+		Event:      websocket.MessageTypeMessageUpdated,
+		Recipients: resp.Recipients,
+		Message: types.BroadcastMessagePayload{
+			MessageID:    resp.MessageID,
+			RoomID:       resp.RoomID,
+			SenderID:     resp.SenderID,
+			ReceiverID:   resp.ReceiverID,
+			Content:      resp.Content,
+			Format:       resp.Format,
+			IsEdited:     &resp.IsEdited,
+			Attachments:  attachments,
+			LinkPreviews: previews,
+			CreatedAt:    resp.CreatedAt,
+			UpdatedAt:    resp.UpdatedAt,
+			ExpiresAt:    resp.ExpiresAt,
+		},
+	}))
+}
+
+func toMessageAttachments(attachments []*types.Attachment) []websocket.MessageAttachment {
+	converted := make([]websocket.MessageAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		converted = append(converted, websocket.MessageAttachment{
+			Type:         attachment.Type,
+			URL:          attachment.URL,
+			UploadID:     attachment.UploadID,
+			Filename:     attachment.Filename,
+			Size:         attachment.Size,
+			MimeType:     attachment.MimeType,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+			ThumbnailURL: attachment.ThumbnailURL,
+		})
+	}
+
+	return converted
+}
+
+func toMessageLinkPreviews(previews []*types.LinkPreview) []websocket.LinkPreview {
+	converted := make([]websocket.LinkPreview, 0, len(previews))
+	for _, preview := range previews {
+		converted = append(converted, websocket.LinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		})
+	}
+
+	return converted
+}
+
+// unixOrNil converts an optional time for the websocket payloads
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+
+	unix := t.Unix()
+	return &unix
+}
@@ -0,0 +1,26 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastMention notifies every connection of the mentioned users, whether or not they joined the room
+func (wh *WorkerHandler) HandleBroadcastMention(raw json.RawMessage) error {
+	var payload types.MentionPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid mention payload: %w", err)
+	}
+
+	msg := websocket.NewMention(payload.RoomID, payload.MessageID, payload.SenderID, payload.Content)
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastMessagePinned keeps the pin list of every member in sync
+func (wh *WorkerHandler) HandleBroadcastMessagePinned(raw json.RawMessage) error {
+	var payload types.MessagePinnedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid message pinned payload: %w", err)
+	}
+
+	msg := websocket.NewMessagePinned(payload.RoomID, payload.MessageID, payload.PinnedBy, payload.Pinned)
+	if data, ok := msg.Data.(websocket.MessagePinned); ok {
+		data.PinnedAt = payload.PinnedAt.Unix()
+		msg.Data = data
+	}
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastMessageReaction sends the new reaction counts of a message to every member
+func (wh *WorkerHandler) HandleBroadcastMessageReaction(raw json.RawMessage) error {
+	var payload types.MessageReactionPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid message reaction payload: %w", err)
+	}
+
+	reactions := make([]websocket.ReactionCount, 0, len(payload.Reactions))
+	for _, reaction := range payload.Reactions {
+		reactions = append(reactions, websocket.ReactionCount{
+			Emoji: reaction.Emoji,
+			Count: reaction.Count,
+		})
+	}
+
+	msg := websocket.NewMessageReaction(payload.RoomID, payload.MessageID, payload.UserID, payload.Emoji, payload.Action, reactions)
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastMessageRead sends one message_read per cursor move, message_id is the new "read up to" position
+func (wh *WorkerHandler) HandleBroadcastMessageRead(raw json.RawMessage) error {
+	var payload types.MessageReadPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid message read payload: %w", err)
+	}
+
+	msg := websocket.NewMessageRead(payload.RoomID, payload.MessageID, payload.ReadBy)
+	if data, ok := msg.Data.(websocket.MessageRead); ok {
+		data.ReadAt = payload.ReadAt.Unix()
+		msg.Data = data
+	}
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+func (wh *WorkerHandler) HandleBroadcastRoomJoined(raw json.RawMessage) error {
+	var payload types.RoomJoinedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid room joined payload: %w", err)
+	}
+
+	msg := websocket.NewRoomJoined(payload.RoomID, payload.UserID, payload.Participants)
+
+	wh.Ws.BroadcastToRoom(payload.RoomID, msg)
+	return nil
+}
+
+func (wh *WorkerHandler) HandleBroadcastSystemMessage(raw json.RawMessage) error {
+	var payload types.SystemMessagePayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid system message payload: %w", err)
+	}
+
+	msg := websocket.NewSystemMessage(payload.RoomID, payload.Content, payload.Data)
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
+
+// HandleBroadcastRoomLeft drops the live connections of the user from the room first,
+// so nothing sent to the room after this reaches them, then notifies the members
+func (wh *WorkerHandler) HandleBroadcastRoomLeft(raw json.RawMessage) error {
+	var payload types.RoomLeftPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid room left payload: %w", err)
+	}
+
+	msg := websocket.NewRoomLeft(payload.RoomID, payload.UserID, payload.Reason, payload.ActorID)
+
+	for _, client := range wh.Ws.GetRoomClients(payload.RoomID) {
+		if client.UserID != payload.UserID {
+			continue
+		}
+		client.SendMessage(msg)
+		wh.Ws.Unregister(payload.RoomID, client)
+	}
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
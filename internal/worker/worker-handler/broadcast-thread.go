@@ -0,0 +1,26 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastThreadUpdated sends the new reply count of a thread to its participants
+func (wh *WorkerHandler) HandleBroadcastThreadUpdated(raw json.RawMessage) error {
+	var payload types.ThreadUpdatedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid thread updated payload: %w", err)
+	}
+
+	msg := websocket.NewThreadUpdated(payload.RoomID, payload.RootMessageID, payload.ReplyID, payload.ReplierID, payload.ReplyCount, payload.LastReplyAt.Unix())
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
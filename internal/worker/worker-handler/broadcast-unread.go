@@ -0,0 +1,24 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastUnreadChanged sends every user their own unread count of the room, to all of their connections
+func (wh *WorkerHandler) HandleBroadcastUnreadChanged(raw json.RawMessage) error {
+	var payload types.UnreadChangedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid unread changed payload: %w", err)
+	}
+
+	for userID, count := range payload.Counts {
+		wh.Ws.BroadcastToUser(userID, websocket.NewUnreadChanged(payload.RoomID, count))
+	}
+
+	return nil
+}
@@ -19,15 +19,19 @@ func (wh *WorkerHandler) HandleBroadcastPrivateMessage(raw json.RawMessage) erro
 
 	// Create chat message using the new structure
 	chatData := websocket.ChatMessage{
-		Type:      websocket.MessageTypeChatMessage,
-		RoomID:    payload.RoomID,
-		MessageID: payload.MessageID,
-		SenderID:  payload.SenderID,
-		Content:   payload.Content,
-		IsEdited:  false,
-		IsRead:    false,
-		CreatedAt: payload.CreatedAt.Unix(),
-		Timestamp: payload.CreatedAt.Unix(),
+		Type:        websocket.MessageTypeChatMessage,
+		RoomID:      payload.RoomID,
+		MessageID:   payload.MessageID,
+		ClientMsgID: payload.ClientMsgID,
+		SenderID:    payload.SenderID,
+		Content:     payload.Content,
+		Format:      payload.Format,
+		IsEdited:    false,
+		IsRead:      false,
+		Attachments: toMessageAttachments(payload.Attachments),
+		CreatedAt:   payload.CreatedAt.Unix(),
+		ExpiresAt:   unixOrNil(payload.ExpiresAt),
+		Timestamp:   payload.CreatedAt.Unix(),
 	}
 
 	// Create OutgoingMessage
@@ -64,16 +68,20 @@ func (wh *WorkerHandler) HandleBroadcastPrivateMessageReply(raw json.RawMessage)
 
 	// Create chat message with reply
 	chatData := websocket.ChatMessage{
-		Type:      websocket.MessageTypeChatMessage,
-		RoomID:    payload.RoomID,
-		MessageID: payload.MessageID,
-		SenderID:  payload.SenderID,
-		Content:   payload.Content,
-		IsEdited:  false,
-		IsRead:    false,
-		Reply:     replyData,
-		CreatedAt: payload.CreatedAt.Unix(),
-		Timestamp: payload.CreatedAt.Unix(),
+		Type:        websocket.MessageTypeChatMessage,
+		RoomID:      payload.RoomID,
+		MessageID:   payload.MessageID,
+		ClientMsgID: payload.ClientMsgID,
+		SenderID:    payload.SenderID,
+		Content:     payload.Content,
+		Format:      payload.Format,
+		IsEdited:    false,
+		IsRead:      false,
+		Reply:       replyData,
+		Attachments: toMessageAttachments(payload.Attachments),
+		CreatedAt:   payload.CreatedAt.Unix(),
+		ExpiresAt:   unixOrNil(payload.ExpiresAt),
+		Timestamp:   payload.CreatedAt.Unix(),
 	}
 
 	// Create OutgoingMessage
@@ -106,8 +114,8 @@ func (wh *WorkerHandler) HandleBroadcastPrivateMessageUpdate(raw json.RawMessage
 				MessageID:       payload.MessageID,
 				OriginalContent: edit.OriginalContent,
 				NewContent:      edit.NewContent,
-				EditedBy:        payload.SenderID,
-				EditedAt:        payload.UpdatedAt.Unix(),
+				EditedBy:        edit.EditedBy,
+				EditedAt:        edit.EditedAt.Unix(),
 			}
 		}
 	} else {
@@ -126,6 +134,7 @@ func (wh *WorkerHandler) HandleBroadcastPrivateMessageUpdate(raw json.RawMessage
 		RoomID:             payload.RoomID,
 		MessageID:          payload.MessageID,
 		Content:            payload.Content,
+		Format:             payload.Format,
 		IsEdited:           true,
 		MessageEditHistory: editHistory,
 		UpdatedAt:          updatedAt,
@@ -178,8 +187,8 @@ func (wh *WorkerHandler) HandleBroadcastPrivateMessageUpdateSimplified(raw json.
 				MessageID:       payload.MessageID,
 				OriginalContent: edit.OriginalContent,
 				NewContent:      edit.NewContent,
-				EditedBy:        payload.SenderID,
-				EditedAt:        payload.UpdatedAt.Unix(),
+				EditedBy:        edit.EditedBy,
+				EditedAt:        edit.EditedAt.Unix(),
 			}
 		}
 	}
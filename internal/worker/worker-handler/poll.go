@@ -0,0 +1,98 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// HandleBroadcastPollUpdated sends the new tally of a poll to every member
+func (wh *WorkerHandler) HandleBroadcastPollUpdated(raw json.RawMessage) error {
+	var payload types.PollUpdatedPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid poll updated payload: %w", err)
+	}
+
+	msg := websocket.NewPollUpdated(payload.RoomID, payload.MessageID, toPollResults(payload.Poll))
+
+	for _, userID := range payload.Recipients {
+		wh.Ws.BroadcastToUser(userID, msg)
+	}
+
+	return nil
+}
+
+// HandleClosePoll closes a poll at its closing time and sends the final tally as a poll_updated event
+func (wh *WorkerHandler) HandleClosePoll(raw json.RawMessage) error {
+	var payload types.ClosePollPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid close poll payload: %w", err)
+	}
+
+	resp, err := wh.Chat.CloseExpiredPoll(wh.Ctx, payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to close poll %s: %w", payload.MessageID, err)
+	}
+	if resp == nil {
+		return nil
+	}
+
+	return wh.HandleBroadcastPollUpdated(queue.MustMarshal(types.PollUpdatedPayload{
+		RoomID:     resp.RoomID,
+		MessageID:  resp.MessageID,
+		Poll:       toPollPayload(resp.Poll),
+		Recipients: resp.Recipients,
+	}))
+}
+
+func toPollPayload(poll *chat_dto.Poll) types.PollPayload {
+	options := make([]types.PollOptionPayload, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		options = append(options, types.PollOptionPayload{
+			ID:     option.ID,
+			Text:   option.Text,
+			Votes:  option.Votes,
+			Voters: option.Voters,
+		})
+	}
+
+	return types.PollPayload{
+		Question:       poll.Question,
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		IsClosed:       poll.IsClosed,
+		TotalVoters:    poll.TotalVoters,
+	}
+}
+
+func toPollResults(poll types.PollPayload) websocket.PollResults {
+	options := make([]websocket.PollOptionResult, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		options = append(options, websocket.PollOptionResult{
+			ID:     option.ID,
+			Text:   option.Text,
+			Votes:  option.Votes,
+			Voters: option.Voters,
+		})
+	}
+
+	return websocket.PollResults{
+		Question:       poll.Question,
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       unixOrNil(poll.ClosesAt),
+		ClosedAt:       unixOrNil(poll.ClosedAt),
+		IsClosed:       poll.IsClosed,
+		TotalVoters:    poll.TotalVoters,
+	}
+}
@@ -0,0 +1,28 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xenn00/chat-system/internal/utils/types"
+)
+
+// HandleProcessAttachments records size, type and image previews of the uploads of a message and
+// sends the updated attachments as a message_updated event, so clients can swap placeholders for previews
+func (wh *WorkerHandler) HandleProcessAttachments(raw json.RawMessage) error {
+	var payload types.ProcessAttachmentsPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid process attachments payload: %w", err)
+	}
+
+	resp, err := wh.Chat.ProcessMessageAttachments(wh.Ctx, payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to process attachments of %s: %w", payload.MessageID, err)
+	}
+	if resp == nil {
+		return nil
+	}
+
+	return wh.broadcastMessageUpdated(resp)
+}
@@ -0,0 +1,70 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/unfurl"
+	"github.com/xenn00/chat-system/internal/utils/types"
+)
+
+// HandleSendScheduledMessage sends a due scheduled message through the regular private send
+// and broadcasts it like one sent over http. Cancelled or edited messages are skipped by the use case.
+func (wh *WorkerHandler) HandleSendScheduledMessage(raw json.RawMessage) error {
+	var payload types.ScheduledMessagePayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid scheduled message payload: %w", err)
+	}
+
+	resp, err := wh.Chat.DeliverScheduledMessage(wh.Ctx, payload.ScheduledID, payload.Version)
+	if err != nil {
+		return fmt.Errorf("failed to send scheduled message %s: %w", payload.ScheduledID, err)
+	}
+	if resp == nil {
+		return nil
+	}
+
+	if err := wh.HandleBroadcastPrivateMessage(queue.MustMarshal(types.BroadcastMessagePayload{
+		MessageID:  resp.MessageID,
+		RoomID:     resp.RoomID,
+		SenderID:   resp.SenderID,
+		ReceiverID: resp.ReceiverID,
+		Content:    resp.Content,
+		Format:     resp.Format,
+		CreatedAt:  resp.CreatedAt,
+		ExpiresAt:  resp.ExpiresAt,
+	})); err != nil {
+		return err
+	}
+
+	// previews come from their own job like for a message sent over http, the message is out either way
+	if unfurl.HasLinks(resp.Content) {
+		if err := wh.enqueueUnfurlLinks(resp.MessageID); err != nil {
+			log.Error().Err(err).Str("message_id", resp.MessageID).Msg("failed to enqueue unfurl job")
+		}
+	}
+
+	if len(resp.Unread) > 0 {
+		if err := wh.HandleBroadcastUnreadChanged(queue.MustMarshal(types.UnreadChangedPayload{
+			RoomID: resp.RoomID,
+			Counts: resp.Unread,
+		})); err != nil {
+			return err
+		}
+	}
+
+	if len(resp.Mentioned) > 0 {
+		return wh.HandleBroadcastMention(queue.MustMarshal(types.MentionPayload{
+			RoomID:     resp.RoomID,
+			MessageID:  resp.MessageID,
+			SenderID:   resp.SenderID,
+			Content:    resp.PlainText,
+			Recipients: resp.Mentioned,
+		}))
+	}
+
+	return nil
+}
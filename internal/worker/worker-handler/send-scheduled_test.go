@@ -0,0 +1,85 @@
+package worker_handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/xenn00/chat-system/internal/dtos/chat_dto"
+	app_error "github.com/xenn00/chat-system/internal/errors"
+	"github.com/xenn00/chat-system/internal/queue"
+	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
+	"github.com/xenn00/chat-system/internal/utils/types"
+	"github.com/xenn00/chat-system/internal/websocket"
+)
+
+// deliveringChat delivers one fixed message, the rest of the contract is not used by the scheduled send
+type deliveringChat struct {
+	chat_service.ChatServiceContract
+	resp *chat_dto.SendPrivateMessageResponse
+}
+
+func (c *deliveringChat) DeliverScheduledMessage(ctx context.Context, scheduledID string, version int) (*chat_dto.SendPrivateMessageResponse, *app_error.AppError) {
+	return c.resp, nil
+}
+
+func TestHandleSendScheduledMessageUnfurlsLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantJobs int
+	}{
+		{"with a link", "look at https://example.com", 1},
+		{"without links", "see you at 5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := miniredis.RunT(t)
+			rdb := redis.NewClient(&redis.Options{Addr: mockRedis.Addr()})
+			defer rdb.Close()
+
+			ctx := context.Background()
+			chat := &deliveringChat{resp: &chat_dto.SendPrivateMessageResponse{
+				MessageID: "m1",
+				RoomID:    "r1",
+				SenderID:  "u1",
+				Content:   tt.content,
+				CreatedAt: time.Now(),
+			}}
+			hub := websocket.NewHub()
+			defer hub.Close()
+			wh := NewWorkerHandler(ctx, rdb, hub, chat)
+
+			raw := queue.MustMarshal(types.ScheduledMessagePayload{ScheduledID: "s1", Version: 1})
+			if err := wh.HandleSendScheduledMessage(raw); err != nil {
+				t.Fatalf("HandleSendScheduledMessage() error = %v", err)
+			}
+
+			queued, err := rdb.ZRange(ctx, "priority_queue", 0, -1).Result()
+			if err != nil {
+				t.Fatalf("ZRange() error = %v", err)
+			}
+			if len(queued) != tt.wantJobs {
+				t.Fatalf("queued %d jobs, want %d", len(queued), tt.wantJobs)
+			}
+
+			for _, raw := range queued {
+				var job queue.Job
+				var payload types.UnfurlLinksPayload
+				if err := json.Unmarshal([]byte(raw), &job); err != nil {
+					t.Fatalf("invalid job: %v", err)
+				}
+				if err := json.Unmarshal(job.Payload, &payload); err != nil {
+					t.Fatalf("invalid job payload: %v", err)
+				}
+				if job.Type != "unfurl_links" || payload.MessageID != "m1" {
+					t.Errorf("queued %s for %q, want unfurl_links for m1", job.Type, payload.MessageID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package worker_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xenn00/chat-system/internal/queue"
+	"github.com/xenn00/chat-system/internal/utils/types"
+)
+
+// HandleUnfurlLinks stores the previews of the links in a message and sends them as a message_updated event
+func (wh *WorkerHandler) HandleUnfurlLinks(raw json.RawMessage) error {
+	var payload types.UnfurlLinksPayload
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("invalid unfurl links payload: %w", err)
+	}
+
+	resp, err := wh.Chat.UnfurlMessageLinks(wh.Ctx, payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to unfurl links of %s: %w", payload.MessageID, err)
+	}
+	if resp == nil {
+		return nil
+	}
+
+	return wh.broadcastMessageUpdated(resp)
+}
+
+// enqueueUnfurlLinks queues the unfurl_links job of a message sent by the worker itself, like a scheduled one
+func (wh *WorkerHandler) enqueueUnfurlLinks(messageID string) error {
+	job := queue.Job{
+		ID:        uuid.New().String(),
+		Type:      "unfurl_links",
+		Payload:   queue.MustMarshal(&types.UnfurlLinksPayload{MessageID: messageID}),
+		Priority:  3,
+		Retry:     0,
+		MaxRetry:  3,
+		CreatedAt: time.Now().Unix(),
+		ExpireAt:  time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	return queue.NewProducer(wh.Redis).Enqueue(wh.Ctx, job)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/redis/go-redis/v9"
+	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
 	"github.com/xenn00/chat-system/internal/websocket"
 )
 
@@ -11,12 +12,14 @@ type WorkerHandler struct {
 	Ctx   context.Context
 	Redis *redis.Client
 	Ws    *websocket.Hub
+	Chat  chat_service.ChatServiceContract // used by jobs that go through the chat use cases, like scheduled sends
 }
 
-func NewWorkerHandler(ctx context.Context, redis *redis.Client, ws *websocket.Hub) *WorkerHandler {
+func NewWorkerHandler(ctx context.Context, redis *redis.Client, ws *websocket.Hub, chat chat_service.ChatServiceContract) *WorkerHandler {
 	return &WorkerHandler{
 		Ctx:   ctx,
 		Redis: redis,
 		Ws:    ws,
+		Chat:  chat,
 	}
 }
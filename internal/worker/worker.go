@@ -9,6 +9,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	"github.com/xenn00/chat-system/internal/queue"
+	chat_service "github.com/xenn00/chat-system/internal/use-case/chat-case"
 	"github.com/xenn00/chat-system/internal/utils/types"
 	"github.com/xenn00/chat-system/internal/websocket"
 	"github.com/xenn00/chat-system/state"
@@ -26,6 +27,19 @@ else
 end
 `
 
+// Lua script moving due jobs from the delayed queue into the priority queue,
+// scored the same way as the producer does (priority * 1e10 + expired_at)
+
+const promoteDueScript = `
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, member in ipairs(due) do
+	local job = cjson.decode(member)
+	redis.call('ZREM', KEYS[1], member)
+	redis.call('ZADD', KEYS[2], job.priority * 1e10 + job.expired_at, member)
+end
+return #due
+`
+
 type WorkerPool struct {
 	Redis      *redis.Client
 	AppState   *state.AppState
@@ -33,12 +47,14 @@ type WorkerPool struct {
 	JobChannel chan string
 	wg         sync.WaitGroup
 	ws         *websocket.Hub
+	chat       chat_service.ChatServiceContract
 	DLQConfig  types.DLQRetryConfig
 
 	// graceful shutdown
-	ctx       context.Context
-	cancel    context.CancelFunc
-	atomicPop *redis.Script
+	ctx        context.Context
+	cancel     context.CancelFunc
+	atomicPop  *redis.Script
+	promoteDue *redis.Script
 }
 
 func NewWorkerPool(redisClient *redis.Client, workerNum int, ws *websocket.Hub, appState *state.AppState) *WorkerPool {
@@ -49,9 +65,11 @@ func NewWorkerPool(redisClient *redis.Client, workerNum int, ws *websocket.Hub,
 		WorkerNum:  workerNum,
 		JobChannel: make(chan string, 100), // Buffered channel to hold jobs
 		ws:         ws,
+		chat:       chat_service.NewChatService(appState),
 		ctx:        ctx,
 		cancel:     cancel,
 		atomicPop:  redis.NewScript(atomicPopScript),
+		promoteDue: redis.NewScript(promoteDueScript),
 		DLQConfig: types.DLQRetryConfig{
 			BatchSize:      10,
 			RetryInterval:  1 * time.Minute,
@@ -75,6 +93,11 @@ func (wp *WorkerPool) popJob(ctx context.Context) (string, error) {
 	return result.(string), nil
 }
 
+// promoteDueJobs releases delayed jobs whose run time has come
+func (wp *WorkerPool) promoteDueJobs(ctx context.Context) error {
+	return wp.promoteDue.Run(ctx, wp.Redis, []string{queue.DelayedQueueKey, "priority_queue"}, time.Now().Unix(), 100).Err()
+}
+
 func (wp *WorkerPool) Start(parentCtx context.Context) {
 	log.Info().Msgf("Starting worker pool with %d workers", wp.WorkerNum)
 
@@ -99,6 +122,24 @@ func (wp *WorkerPool) Start(parentCtx context.Context) {
 		wp.StartDLQRetryConsumer(wp.ctx) // MongoDB -> Retry
 	}()
 
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		wp.StartExpiredMessageSweeper(wp.ctx) // disappearing messages
+	}()
+
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		wp.StartUnreadReconciler(wp.ctx) // Redis unread counters -> room_members
+	}()
+
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		wp.StartScheduledMessageRecovery(wp.ctx) // scheduled messages lost by the queue
+	}()
+
 	// Job producer - get jobs from redis and distribute to workers
 	wp.wg.Add(1)
 	go func() {
@@ -118,6 +159,10 @@ func (wp *WorkerPool) Start(parentCtx context.Context) {
 				wp.cancel() // cancel internal context
 				return
 			case <-ticker.C:
+				if err := wp.promoteDueJobs(wp.ctx); err != nil {
+					log.Error().Err(err).Msg("failed to promote delayed jobs")
+				}
+
 				// atomic pop from redis
 				payload, err := wp.popJob(wp.ctx)
 				if err != nil {
@@ -182,7 +227,7 @@ func (wp *WorkerPool) worker(id int) {
 				Msgf("Worker %d: Processing job", id)
 
 			// process job
-			if err := HandleJob(wp.ctx, job, wp.Redis, wp.ws); err != nil {
+			if err := HandleJob(wp.ctx, job, wp.Redis, wp.ws, wp.chat); err != nil {
 				wp.handlerJobFailure(job, err, id)
 			} else {
 				log.Info().
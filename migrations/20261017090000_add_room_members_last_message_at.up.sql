@@ -0,0 +1,2 @@
+-- room_members.last_message_at is written by the room metadata updates and on member insert
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS last_message_at TIMESTAMP WITH TIME ZONE;
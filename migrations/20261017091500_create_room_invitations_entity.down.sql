@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS room_invitations;
+DROP TYPE IF EXISTS invitation_status;
@@ -0,0 +1,18 @@
+-- Create invitation status type
+CREATE TYPE invitation_status AS ENUM ('pending', 'accepted', 'declined');
+
+CREATE TABLE room_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status invitation_status NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    responded_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Only one pending invitation per user per room
+CREATE UNIQUE INDEX idx_room_invitations_pending ON room_invitations(room_id, invitee_id) WHERE status = 'pending';
+
+-- Index for listing pending invitations of one user
+CREATE INDEX idx_room_invitations_invitee ON room_invitations(invitee_id, status);
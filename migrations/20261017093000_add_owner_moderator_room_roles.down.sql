@@ -0,0 +1,10 @@
+-- enum values cannot be dropped, recreate the type with the original values
+UPDATE room_members SET role = 'admin' WHERE role = 'owner';
+UPDATE room_members SET role = 'member' WHERE role = 'moderator';
+
+ALTER TABLE room_members ALTER COLUMN role DROP DEFAULT;
+ALTER TYPE room_role_type RENAME TO room_role_type_old;
+CREATE TYPE room_role_type AS ENUM ('admin', 'member');
+ALTER TABLE room_members ALTER COLUMN role TYPE room_role_type USING role::text::room_role_type;
+ALTER TABLE room_members ALTER COLUMN role SET DEFAULT 'member';
+DROP TYPE room_role_type_old;
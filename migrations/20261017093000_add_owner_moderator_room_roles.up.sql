@@ -0,0 +1,3 @@
+-- role hierarchy: owner > admin > moderator > member
+ALTER TYPE room_role_type ADD VALUE IF NOT EXISTS 'owner' BEFORE 'admin';
+ALTER TYPE room_role_type ADD VALUE IF NOT EXISTS 'moderator' AFTER 'admin';
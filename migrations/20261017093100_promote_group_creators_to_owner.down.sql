@@ -0,0 +1 @@
+UPDATE room_members SET role = 'admin' WHERE role = 'owner';
@@ -0,0 +1,8 @@
+-- group creators were stored as admin before the owner role existed
+UPDATE room_members rm
+SET role = 'owner'
+FROM rooms r
+WHERE rm.room_id = r.id
+  AND r.rt = 'group'
+  AND rm.user_id = r.created_by
+  AND rm.role = 'admin';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS room_membership_events;
+DROP TYPE IF EXISTS membership_event_type;
@@ -0,0 +1,15 @@
+-- Create membership event type
+CREATE TYPE membership_event_type AS ENUM ('joined', 'rejoined', 'left', 'removed');
+
+-- Append only audit trail of room membership changes
+CREATE TABLE room_membership_events (
+    id BIGSERIAL PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    event membership_event_type NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- Index for reading the trail of one room
+CREATE INDEX idx_room_membership_events_room ON room_membership_events(room_id, created_at DESC);
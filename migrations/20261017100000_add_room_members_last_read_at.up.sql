@@ -0,0 +1,2 @@
+-- last_read_msg_id is a "read up to" cursor, last_read_at is when it last moved forward
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS last_read_at TIMESTAMP WITH TIME ZONE;
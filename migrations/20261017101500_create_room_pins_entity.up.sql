@@ -0,0 +1,12 @@
+-- Pinned messages of a room, message_id is the hex ObjectID of the Mongo message
+CREATE TABLE room_pins (
+    id BIGSERIAL PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id VARCHAR(24) NOT NULL,
+    pinned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    pinned_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    UNIQUE (room_id, message_id)
+);
+
+-- Index for listing the pins of one room, newest first
+CREATE INDEX idx_room_pins_room ON room_pins(room_id, pinned_at DESC);
@@ -0,0 +1,12 @@
+-- Private per user bookmarks, message_id is the hex ObjectID of the Mongo message
+CREATE TABLE message_bookmarks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id VARCHAR(24) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    UNIQUE (user_id, message_id)
+);
+
+-- Index for paging through the bookmarks of one user, newest first
+CREATE INDEX idx_message_bookmarks_user ON message_bookmarks(user_id, id DESC);
@@ -0,0 +1,2 @@
+-- disappearing messages timer of the room in seconds, 0 keeps messages forever
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS message_ttl_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Files uploaded for message attachments, message_id is the hex ObjectID of the Mongo message once attached
+CREATE TABLE file_uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    uploader_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    room_id UUID REFERENCES rooms(id) ON DELETE SET NULL,
+    message_id VARCHAR(24),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- Index for finding the uploads of one user that are not attached yet
+CREATE INDEX idx_file_uploads_unattached ON file_uploads(uploader_id) WHERE message_id IS NULL;
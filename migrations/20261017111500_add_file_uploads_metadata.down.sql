@@ -0,0 +1,6 @@
+ALTER TABLE file_uploads
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS thumbnail_key,
+    DROP COLUMN IF EXISTS thumbnail_mime_type,
+    DROP COLUMN IF EXISTS processed_at;
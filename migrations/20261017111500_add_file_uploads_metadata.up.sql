@@ -0,0 +1,7 @@
+-- metadata filled in by the process_attachments job, width, height and the thumbnail are only set for decodable images
+ALTER TABLE file_uploads
+    ADD COLUMN IF NOT EXISTS width INTEGER,
+    ADD COLUMN IF NOT EXISTS height INTEGER,
+    ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS thumbnail_mime_type VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS processed_at TIMESTAMP WITH TIME ZONE;
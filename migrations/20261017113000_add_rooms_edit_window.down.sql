@@ -0,0 +1 @@
+ALTER TABLE rooms DROP COLUMN IF EXISTS edit_window_seconds;
@@ -0,0 +1,2 @@
+-- edit window of the room in seconds, NULL uses the server default and 0 lets messages be edited at any time
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS edit_window_seconds INTEGER CHECK (edit_window_seconds >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_room_members_inbox;
+ALTER TABLE room_members DROP COLUMN IF EXISTS is_archived;
+ALTER TABLE room_members DROP COLUMN IF EXISTS is_muted;
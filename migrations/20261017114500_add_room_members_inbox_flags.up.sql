@@ -0,0 +1,6 @@
+-- inbox settings of a member, both only change how the client lists the room
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS is_muted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE room_members ADD COLUMN IF NOT EXISTS is_archived BOOLEAN NOT NULL DEFAULT false;
+
+-- serves the inbox, the rooms of a user by latest activity
+CREATE INDEX IF NOT EXISTS idx_room_members_inbox ON room_members (user_id, (COALESCE(last_message_at, joined_at)) DESC, room_id DESC) WHERE left_at IS NULL;
@@ -0,0 +1,2 @@
+-- nothing to undo, NULL is what these rows should have held from the start
+SELECT 1;
@@ -0,0 +1,3 @@
+-- members inserted before last_message_at became nullable in the model got the Go zero time instead of NULL,
+-- which kept the inbox from falling back to joined_at
+UPDATE room_members SET last_message_at = NULL WHERE last_message_at < '0002-01-01';
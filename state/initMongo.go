@@ -36,6 +36,14 @@ func InitMongo(ctx context.Context) (*mongo.Client, error) {
 	if err := initMessageCollection(ctx, db); err != nil {
 		return nil, fmt.Errorf("faield to create 'messages' collection: %w", err)
 	}
+	// init message_deletions collections
+	if err := initMessageDeletionCollection(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to create 'message_deletions' collection: %w", err)
+	}
+	// init scheduled_messages collections
+	if err := initScheduledMessageCollection(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to create 'scheduled_messages' collection: %w", err)
+	}
 	// init dlq_jobs collections
 	if err := initDLQCollection(ctx, db); err != nil {
 		return nil, fmt.Errorf("failed to create 'dlq_jobs' collection: %w", err)
@@ -48,147 +56,441 @@ func InitMongo(ctx context.Context) (*mongo.Client, error) {
 func initMessageCollection(ctx context.Context, db *mongo.Database) error {
 	collectionName := "messages"
 
-	// check, is it already set or not
-	collections, err := db.ListCollectionNames(ctx, bson.M{"name": collectionName})
-	if err != nil {
-		return fmt.Errorf("failed to list connections: %w", err)
-	}
-
-	if len(collections) == 0 {
-		// create collection with optional JSON schema validator
-		jsonSchema := bson.M{
-			"bsonType": "object",
-			"required": []string{"room_id", "sender_id", "receiver_id", "content", "is_read", "created_at"},
-			"properties": bson.M{
-				"room_id": bson.M{
-					"bsonType":    "string",
-					"description": "Room ID must be a string",
-				},
-				"sender_id": bson.M{
-					"bsonType":    "string",
-					"description": "ID sender user",
-				},
-				"receiver_id": bson.M{
-					"bsonType":    "string",
-					"description": "ID receiver user",
-				},
-				"content": bson.M{
-					"bsonType":    "string",
-					"description": "Chat's content",
-				},
-				"is_read": bson.M{
-					"bsonType":    "bool",
-					"description": "Is message already read",
+	jsonSchema := bson.M{
+		"bsonType": "object",
+		"required": []string{"room_id", "sender_id", "content", "created_at"},
+		"properties": bson.M{
+			"room_id": bson.M{
+				"bsonType":    "string",
+				"description": "Room ID must be a string",
+			},
+			"sender_id": bson.M{
+				"bsonType":    "string",
+				"description": "ID sender user",
+			},
+			"receiver_id": bson.M{
+				"bsonType":    "string",
+				"description": "ID receiver user, only set for private rooms",
+			},
+			"content": bson.M{
+				"bsonType":    "string",
+				"description": "Chat's content",
+			},
+			"format": bson.M{
+				"enum":        []string{"plain", "markdown"},
+				"description": "How content is rendered, missing means plain",
+			},
+			"plain_text": bson.M{
+				"bsonType":    "string",
+				"description": "Content without markup, indexed for search",
+			},
+			"client_msg_id": bson.M{
+				"bsonType":    "string",
+				"description": "ID the sender generated for the message, unique per sender so retried sends are not stored twice",
+			},
+			"type": bson.M{
+				"enum":        []string{"poll", "system"},
+				"description": "Kind of message, missing means a text message",
+			},
+			"system_data": bson.M{
+				"bsonType":    "object",
+				"description": "What a system message announces, like the event and the users involved",
+			},
+			"poll": bson.M{
+				"bsonType": "object",
+				"required": []string{"question", "options", "votes"},
+				"properties": bson.M{
+					"question": bson.M{
+						"bsonType":    "string",
+						"description": "Question of the poll, also stored as content",
+					},
+					"options": bson.M{
+						"bsonType": "array",
+						"minItems": 2,
+						"items": bson.M{
+							"bsonType": "object",
+							"required": []string{"id", "text"},
+						},
+					},
+					"multiple_choice": bson.M{
+						"bsonType":    "bool",
+						"description": "Whether a vote can pick several options",
+					},
+					"anonymous": bson.M{
+						"bsonType":    "bool",
+						"description": "Whether voters are hidden from the results",
+					},
+					"closes_at": bson.M{
+						"bsonType":    "date",
+						"description": "When the close_poll job closes the poll",
+					},
+					"closed_at": bson.M{
+						"bsonType":    "date",
+						"description": "When the poll was closed",
+					},
+					"votes": bson.M{
+						"bsonType": "array",
+						"items": bson.M{
+							"bsonType": "object",
+							"required": []string{"user_id", "option_ids", "voted_at"},
+						},
+					},
 				},
-				"message_edit_history": bson.M{
-					"bsonType": []string{"array", "null"},
+			},
+			"is_read": bson.M{
+				"bsonType":    "bool",
+				"description": "Deprecated, read state lives in room_members.last_read_msg_id",
+			},
+			"message_edit_history": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
 					"required": []string{"message_id", "original_content", "new_content", "edited_by", "edited_at"},
 					"properties": bson.M{
 						"message_id": bson.M{
 							"bsonType":    []string{"objectId", "binData"},
-							"description": "ID of the replied message",
+							"description": "ID of the edited message",
 						},
 						"original_content": bson.M{
 							"bsonType":    "string",
-							"description": "Original content of the message",
+							"description": "Content before the edit",
 						},
 						"new_content": bson.M{
 							"bsonType":    "string",
-							"description": "New content fof the message",
+							"description": "Content after the edit",
 						},
 						"edited_by": bson.M{
 							"bsonType":    "string",
 							"description": "Responsible ID who do editing message content",
 						},
 						"edited_at": bson.M{
-							"bsonType":    "string",
-							"description": "Edited at",
+							"bsonType":    "date",
+							"description": "When the edit was made",
 						},
 					},
 				},
-				"reply_to": bson.M{
-					"bsonType": []string{"object", "null"},
-					"required": []string{"message_id", "content", "sender_id"},
+			},
+			"reply_to": bson.M{
+				"bsonType": []string{"object", "null"},
+				"required": []string{"message_id", "content", "sender_id"},
+				"properties": bson.M{
+					"message_id": bson.M{
+						"bsonType":    []string{"objectId", "binData"},
+						"description": "ID of the replied message",
+					},
+					"content": bson.M{
+						"bsonType":    "string",
+						"description": "Content of the replied message",
+					},
+					"sender_id": bson.M{
+						"bsonType":    "string",
+						"description": "Sender of the original message",
+					},
+				},
+			},
+			"forwarded_from": bson.M{
+				"bsonType": []string{"object", "null"},
+				"required": []string{"message_id", "room_id", "sender_id"},
+				"properties": bson.M{
+					"message_id": bson.M{
+						"bsonType":    []string{"objectId", "binData"},
+						"description": "ID of the original message",
+					},
+					"room_id": bson.M{
+						"bsonType":    "string",
+						"description": "Room of the original message",
+					},
+					"sender_id": bson.M{
+						"bsonType":    "string",
+						"description": "Sender of the original message",
+					},
+				},
+			},
+			"attachments": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
+					"required": []string{"url", "type"},
 					"properties": bson.M{
-						"message_id": bson.M{
-							"bsonType":    []string{"objectId", "binData"},
-							"description": "ID of the replied message",
+						"type": bson.M{
+							"bsonType":    "string",
+							"description": "File type, e.g., image, video, docs, etc.",
+						},
+						"url": bson.M{
+							"bsonType":    "string",
+							"description": "File storage URL",
+						},
+						"upload_id": bson.M{
+							"bsonType":    "string",
+							"description": "ID of the file_uploads row, set for uploaded files",
+						},
+						"filename": bson.M{
+							"bsonType":    "string",
+							"description": "Original file name",
+						},
+						"size": bson.M{
+							"bsonType":    []string{"int", "long"},
+							"description": "File size in bytes",
 						},
-						"content": bson.M{
+						"mime_type": bson.M{
 							"bsonType":    "string",
-							"description": "Content of the replied message",
+							"description": "Content type sniffed at upload",
+						},
+						"width": bson.M{
+							"bsonType":    "int",
+							"description": "Image width in pixels",
 						},
-						"sender_id": bson.M{
+						"height": bson.M{
+							"bsonType":    "int",
+							"description": "Image height in pixels",
+						},
+						"thumbnail_url": bson.M{
 							"bsonType":    "string",
-							"description": "Sender of the original message",
+							"description": "Unsigned download path of the image thumbnail",
 						},
 					},
 				},
-				"attachments": bson.M{
-					"bsonType": []string{"array", "null"},
-					"items": bson.M{
-						"bsonType": "object",
-						"required": []string{"url", "type"},
-						"properties": bson.M{
-							"type": bson.M{
-								"bsonType":    "string",
-								"description": "File type, e.g., image, video, docs, etc.",
-							},
-							"url": bson.M{
-								"bsonType":    "string",
-								"description": "File storage URL",
-							},
+			},
+			"link_previews": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
+					"required": []string{"url"},
+					"properties": bson.M{
+						"url": bson.M{
+							"bsonType":    "string",
+							"description": "Link as written in the message",
+						},
+						"title": bson.M{
+							"bsonType":    "string",
+							"description": "Open Graph or html title of the page",
+						},
+						"description": bson.M{
+							"bsonType":    "string",
+							"description": "Open Graph or meta description of the page",
+						},
+						"image_url": bson.M{
+							"bsonType":    "string",
+							"description": "Absolute URL of the preview image",
+						},
+						"site_name": bson.M{
+							"bsonType":    "string",
+							"description": "Open Graph site name",
 						},
 					},
 				},
-				"is_edited": bson.M{
-					"bsonType":    "bool",
-					"description": "Whether the message has been edited",
-				},
-				"created_at": bson.M{
-					"bsonType":    "date",
-					"description": "Message creation timestamp",
-				},
-				"updated_at": bson.M{
-					"bsonType":    []string{"date", "null"},
-					"description": "Message last update timestamp",
+			},
+			"is_edited": bson.M{
+				"bsonType":    "bool",
+				"description": "Whether the message has been edited",
+			},
+			"reactions": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
+					"required": []string{"user_id", "emoji", "reacted_at"},
+					"properties": bson.M{
+						"user_id": bson.M{
+							"bsonType":    "string",
+							"description": "ID of the reacting user",
+						},
+						"emoji": bson.M{
+							"bsonType":    "string",
+							"description": "Reaction emoji",
+						},
+						"reacted_at": bson.M{
+							"bsonType":    "date",
+							"description": "Reaction timestamp",
+						},
+					},
 				},
 			},
-		}
-		opts := options.CreateCollection().SetValidator(bson.M{
-			"$jsonSchema": jsonSchema,
-		})
+			"mentions": bson.M{
+				"bsonType":    "array",
+				"items":       bson.M{"bsonType": "string"},
+				"description": "IDs of the room members mentioned in the content",
+			},
+			"thread_root_id": bson.M{
+				"bsonType":    []string{"objectId", "binData"},
+				"description": "ID of the first message of the thread this reply belongs to",
+			},
+			"reply_count": bson.M{
+				"bsonType":    "int",
+				"description": "Number of replies in the thread, thread roots only",
+			},
+			"last_reply_at": bson.M{
+				"bsonType":    "date",
+				"description": "Latest reply timestamp, thread roots only",
+			},
+			"thread_participants": bson.M{
+				"bsonType":    "array",
+				"items":       bson.M{"bsonType": "string"},
+				"description": "Root sender and every replier, thread roots only",
+			},
+			"deleted_for": bson.M{
+				"bsonType":    "array",
+				"items":       bson.M{"bsonType": "string"},
+				"description": "Users that deleted the message for themselves",
+			},
+			"is_deleted": bson.M{
+				"bsonType":    "bool",
+				"description": "Whether the message has been deleted for everyone",
+			},
+			"deleted_by": bson.M{
+				"bsonType":    "string",
+				"description": "Responsible ID who deleted the message for everyone",
+			},
+			"deleted_at": bson.M{
+				"bsonType":    "date",
+				"description": "Message deletion timestamp",
+			},
+			"expires_at": bson.M{
+				"bsonType":    "date",
+				"description": "When the message disappears, set from the room disappearing timer",
+			},
+			"created_at": bson.M{
+				"bsonType":    "date",
+				"description": "Message creation timestamp",
+			},
+			"updated_at": bson.M{
+				"bsonType":    []string{"date", "null"},
+				"description": "Message last update timestamp",
+			},
+		},
+	}
+	validator := bson.M{
+		"$jsonSchema": jsonSchema,
+	}
+
+	// collMod keeps the validator of an existing collection in sync with the schema above
+	cmd := bson.D{
+		{Key: "collMod", Value: collectionName},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+	}
 
-		if err := db.CreateCollection(ctx, collectionName, opts); err != nil {
-			return fmt.Errorf("faield to create collection: %w", err)
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		// code 26 = NamespaceNotFound -> if collection is not exist
+		if commandErr, ok := err.(mongo.CommandError); ok && commandErr.Code == 26 {
+			opts := options.CreateCollection().SetValidator(validator)
+			if err := db.CreateCollection(ctx, collectionName, opts); err != nil {
+				return fmt.Errorf("faield to create collection: %w", err)
+			}
+			log.Info().Msg("Collection 'messages' created!")
+		} else {
+			return fmt.Errorf("failed to modify messages collection: %w", err)
 		}
+	}
 
-		log.Info().Msg("Collection 'messages' created!")
+	if err := migrateMessageTextIndex(ctx, db.Collection(collectionName)); err != nil {
+		return err
+	}
 
-		// add index (room_id + created_at) for easily query and sort
-		indexes := db.Collection(collectionName).Indexes()
-		_, err := indexes.CreateMany(ctx, []mongo.IndexModel{
-			{
-				Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "created_at", Value: -1}},
-				Options: options.Index().SetName("room_time_idx"),
-			},
-			{
-				Keys:    bson.D{{Key: "sender_id", Value: 1}},
-				Options: options.Index().SetName("sender_idx"),
-			},
-			{
-				Keys:    bson.D{{Key: "receiver_id", Value: 1}},
-				Options: options.Index().SetName("receiver_idx"),
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create indexes: %w", err)
+	// add index (room_id + created_at) for easily query and sort
+	indexes := db.Collection(collectionName).Indexes()
+	_, err := indexes.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("room_time_idx"),
+		},
+		{
+			Keys:    bson.D{{Key: "sender_id", Value: 1}},
+			Options: options.Index().SetName("sender_idx"),
+		},
+		{
+			Keys:    bson.D{{Key: "receiver_id", Value: 1}},
+			Options: options.Index().SetName("receiver_idx"),
+		},
+		{
+			// backs the Redis dedupe of retried sends, a second message with the same client id cannot be stored
+			Keys:    bson.D{{Key: "sender_id", Value: 1}, {Key: "client_msg_id", Value: 1}},
+			Options: options.Index().SetName("client_msg_idx").SetUnique(true).SetPartialFilterExpression(bson.M{"client_msg_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "mentions", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("mentions_idx").SetPartialFilterExpression(bson.M{"mentions": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "thread_root_id", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("thread_idx").SetPartialFilterExpression(bson.M{"thread_root_id": bson.M{"$exists": true}}),
+		},
+		{
+			// full-text search, a collection holds a single text index
+			Keys:    bson.D{{Key: "plain_text", Value: "text"}},
+			Options: options.Index().SetName("plain_text_idx").SetDefaultLanguage("english"),
+		},
+		{
+			// serves the sweeper, the TTL only removes what the sweeper missed for an hour (no message_deleted event then)
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_idx").SetExpireAfterSeconds(3600),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	log.Info().Msg("Messages collection initialized successfully")
+	return nil
+}
+
+// migrateMessageTextIndex moves search from content to plain_text, messages stored before formats existed are
+// plain so their content is copied over as is
+func migrateMessageTextIndex(ctx context.Context, collection *mongo.Collection) error {
+	backfill := mongo.Pipeline{{{Key: "$set", Value: bson.M{"plain_text": "$content"}}}}
+	if _, err := collection.UpdateMany(ctx, bson.M{"plain_text": bson.M{"$exists": false}}, backfill); err != nil {
+		return fmt.Errorf("failed to backfill plain_text: %w", err)
+	}
+
+	if err := collection.Indexes().DropOne(ctx, "content_text_idx"); err != nil {
+		// code 27 = IndexNotFound -> already migrated
+		if commandErr, ok := err.(mongo.CommandError); !ok || commandErr.Code != 27 {
+			return fmt.Errorf("failed to drop content text index: %w", err)
 		}
+	}
+
+	return nil
+}
+
+func initMessageDeletionCollection(ctx context.Context, db *mongo.Database) error {
+	// audit records of messages deleted for everyone, created on first insert
+	indexes := db.Collection("message_deletions").Indexes()
+	_, err := indexes.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "message_id", Value: 1}},
+			Options: options.Index().SetName("message_idx").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "deleted_at", Value: -1}},
+			Options: options.Index().SetName("room_deleted_idx"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
 
-		log.Info().Msg("Indexes created for 'messages'")
+	log.Info().Msg("Message deletions collection initialized successfully")
+	return nil
+}
+
+func initScheduledMessageCollection(ctx context.Context, db *mongo.Database) error {
+	// private messages waiting for their send time, created on first insert
+	indexes := db.Collection("scheduled_messages").Indexes()
+	_, err := indexes.CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "sender_id", Value: 1}, {Key: "status", Value: 1}, {Key: "send_at", Value: 1}},
+			Options: options.Index().SetName("sender_status_idx"),
+		},
+		{
+			// the recovery worker looks for overdue and stale messages by status
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "send_at", Value: 1}},
+			Options: options.Index().SetName("status_send_at_idx"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
 	}
 
-	log.Info().Msg("Already have 'messages' collection!")
+	log.Info().Msg("Scheduled messages collection initialized successfully")
 	return nil
 }
 